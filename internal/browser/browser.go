@@ -0,0 +1,17 @@
+package browser
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+func Open(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("cmd", "/c", "start", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
@@ -0,0 +1,90 @@
+package capabilities
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"mastodoncli/internal/config"
+	"mastodoncli/internal/mastodon"
+)
+
+// MaxAge is how long a probe is trusted before the instance is asked again,
+// so upgrades are noticed within a day.
+const MaxAge = 24 * time.Hour
+
+// Store caches capability probes per instance domain.
+type Store struct {
+	Instances map[string]*mastodon.Capabilities `json:"instances"`
+}
+
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "capabilities.json"), nil
+}
+
+func Load() (*Store, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{Instances: map[string]*mastodon.Capabilities{}}
+	if err := config.ReadJSON(path, "capabilities", store); err != nil {
+		return nil, err
+	}
+	if store.Instances == nil {
+		store.Instances = map[string]*mastodon.Capabilities{}
+	}
+	return store, nil
+}
+
+func (s *Store) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, "capabilities", s)
+}
+
+// For returns the capabilities of instance, from the cache while they are
+// younger than MaxAge and probed through client otherwise (always when
+// refresh is set). The client keeps them for its own checks.
+func For(client *mastodon.Client, instance string, refresh bool, now time.Time) (*mastodon.Capabilities, error) {
+	return ForInstance(client, instance, nil, refresh, now)
+}
+
+// ForInstance is For when the instance information was already fetched;
+// a probe then only adds the nodeinfo. A nil info is fetched as in For.
+func ForInstance(client *mastodon.Client, instance string, info *mastodon.Instance, refresh bool, now time.Time) (*mastodon.Capabilities, error) {
+	store, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	key := domain(instance)
+	if cached, ok := store.Instances[key]; ok && !refresh && now.Sub(cached.CheckedAt) < MaxAge {
+		client.SetCapabilities(cached)
+		return cached, nil
+	}
+
+	if info == nil {
+		if info, err = client.Instance(); err != nil {
+			return nil, err
+		}
+	}
+	caps := client.DetectCapabilities(info)
+	client.SetCapabilities(caps)
+	store.Instances[key] = caps
+	if err := store.Save(); err != nil {
+		return nil, err
+	}
+	return caps, nil
+}
+
+func domain(instance string) string {
+	instance = strings.TrimPrefix(strings.TrimPrefix(instance, "https://"), "http://")
+	return strings.ToLower(strings.TrimRight(instance, "/"))
+}
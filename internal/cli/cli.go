@@ -4,13 +4,17 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
-	"runtime"
+	"slices"
+	"strings"
 
+	"mastodoncli/internal/browser"
 	"mastodoncli/internal/config"
+	"mastodoncli/internal/drafts"
 	"mastodoncli/internal/mastodon"
 	"mastodoncli/internal/metrics"
 	"mastodoncli/internal/output"
+	"mastodoncli/internal/preview"
+	"mastodoncli/internal/rules"
 	"mastodoncli/internal/ui"
 )
 
@@ -31,6 +35,20 @@ func Run(args []string) error {
 		return runNotifications(args[2:])
 	case "metrics":
 		return runMetrics(args[2:])
+	case "post":
+		return runPost(args[2:])
+	case "edit":
+		return runEdit(args[2:])
+	case "poll":
+		return runPoll(args[2:])
+	case "scheduled":
+		return runScheduled(args[2:])
+	case "drafts":
+		return runDrafts(args[2:])
+	case "instance":
+		return runInstance(args[2:])
+	case "watch":
+		return runWatch(args[2:])
 	case "ui":
 		return runUI(args[2:])
 	case "help", "-h", "--help":
@@ -42,6 +60,9 @@ func Run(args []string) error {
 	}
 }
 
+// oauthScopes covers reading plus posting, voting and follow requests.
+const oauthScopes = "read write"
+
 func runLogin(args []string) error {
 	fs := flag.NewFlagSet("login", flag.ExitOnError)
 	instance := fs.String("instance", "", "Mastodon instance domain (e.g. mastodon.social)")
@@ -66,20 +87,21 @@ func runLogin(args []string) error {
 	}
 
 	client := mastodon.NewClient(cfg.Instance, "")
-	if cfg.ClientID == "" || cfg.ClientSecret == "" || *force {
-		app, err := client.RegisterApp("MastodonCLI", cfg.RedirectURI, "read")
+	if cfg.ClientID == "" || cfg.ClientSecret == "" || cfg.Scopes != oauthScopes || *force {
+		app, err := client.RegisterApp("MastodonCLI", cfg.RedirectURI, oauthScopes)
 		if err != nil {
 			return err
 		}
 		cfg.ClientID = app.ClientID
 		cfg.ClientSecret = app.ClientSecret
+		cfg.Scopes = oauthScopes
 	}
 
-	authURL := client.AuthorizeURL(cfg.ClientID, cfg.RedirectURI, "read")
+	authURL := client.AuthorizeURL(cfg.ClientID, cfg.RedirectURI, oauthScopes)
 	fmt.Println("Open this URL in your browser and authorize the app:")
 	fmt.Println(authURL)
 	fmt.Println()
-	if err := openBrowser(authURL); err != nil {
+	if err := browser.Open(authURL); err != nil {
 		fmt.Printf("Could not open browser automatically: %v\n", err)
 	}
 
@@ -91,11 +113,16 @@ func runLogin(args []string) error {
 		return fmt.Errorf("authorization code is required")
 	}
 
-	token, err := client.ExchangeToken(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURI, code, "read")
+	token, err := client.ExchangeToken(cfg.ClientID, cfg.ClientSecret, cfg.RedirectURI, code, oauthScopes)
 	if err != nil {
 		return err
 	}
 	cfg.AccessToken = token.AccessToken
+	cfg.Account, cfg.AccountID = "", ""
+	if account, err := mastodon.NewClient(cfg.Instance, cfg.AccessToken).VerifyCredentials(); err == nil {
+		cfg.Account = drafts.AccountTag(account.Acct, cfg.Instance)
+		cfg.AccountID = account.ID
+	}
 
 	if err := config.Save(cfg); err != nil {
 		return err
@@ -109,6 +136,9 @@ func runTimeline(args []string) error {
 	fs := flag.NewFlagSet("timeline", flag.ExitOnError)
 	limit := fs.Int("limit", 20, "Number of statuses to fetch (1-40)")
 	timelineType := fs.String("type", "home", "Timeline type: home, local, federated, trending")
+	showHidden := fs.Bool("show-hidden", false, "Show statuses hidden by local rules")
+	expandCW := fs.Bool("expand-cw", false, "Show text behind content warnings")
+	previews := fs.Bool("previews", false, "Render inline image previews")
 	fs.Parse(args)
 
 	if *limit <= 0 || *limit > 40 {
@@ -144,7 +174,25 @@ func runTimeline(args []string) error {
 		return err
 	}
 
-	output.PrintStatuses(statuses)
+	ruleSet, err := rules.Load()
+	if err != nil {
+		return err
+	}
+	ruleSet.SetInstance(cfg.Instance)
+	context := rules.ContextPublic
+	if *timelineType == "home" {
+		context = rules.ContextHome
+	}
+
+	opts, err := displayOptions(cfg, *expandCW, *previews)
+	if err != nil {
+		return err
+	}
+	opts.Rules = ruleSet
+	opts.Context = context
+	opts.ShowHidden = *showHidden
+
+	output.PrintStatuses(statuses, opts)
 	return nil
 }
 
@@ -153,6 +201,8 @@ func runPosts(args []string) error {
 	limit := fs.Int("limit", 20, "Number of statuses to fetch (1-800)")
 	includeBoosts := fs.Bool("boosts", false, "Include boosts in results")
 	includeReplies := fs.Bool("replies", false, "Include replies in results")
+	expandCW := fs.Bool("expand-cw", false, "Show text behind content warnings")
+	previews := fs.Bool("previews", false, "Render inline image previews")
 	fs.Parse(args)
 
 	if *limit <= 0 || *limit > 800 {
@@ -208,18 +258,52 @@ func runPosts(args []string) error {
 		fmt.Fprintln(os.Stderr)
 	}
 
-	output.PrintStatuses(all)
+	opts, err := displayOptions(cfg, *expandCW, *previews)
+	if err != nil {
+		return err
+	}
+
+	output.PrintStatuses(all, opts)
 	return nil
 }
 
 func runNotifications(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "policy":
+			return runNotificationPolicy(args[1:])
+		case "requests":
+			return runNotificationRequests(args[1:])
+		}
+	}
+
 	fs := flag.NewFlagSet("notifications", flag.ExitOnError)
 	limit := fs.Int("limit", 20, "Number of notifications to fetch (1-40)")
+	showHidden := fs.Bool("show-hidden", false, "Show notifications hidden by local rules")
+	expandCW := fs.Bool("expand-cw", false, "Show text behind content warnings")
+	previews := fs.Bool("previews", false, "Render inline image previews")
+	types := fs.String("types", "", "Only these notification types (comma-separated)")
+	exclude := fs.String("exclude", "", "Leave out these notification types (comma-separated)")
+	mentions := fs.Bool("mentions", false, "Only mentions (same as --types mention)")
 	fs.Parse(args)
 
 	if *limit <= 0 || *limit > 40 {
 		return fmt.Errorf("limit must be between 1 and 40")
 	}
+	var filter mastodon.NotificationFilter
+	var err error
+	if filter.Types, err = parseNotificationTypes(*types); err != nil {
+		return err
+	}
+	if filter.ExcludeTypes, err = parseNotificationTypes(*exclude); err != nil {
+		return err
+	}
+	if *mentions {
+		if len(filter.Types) > 0 {
+			return fmt.Errorf("--mentions cannot be combined with --types")
+		}
+		filter.Types = []string{"mention"}
+	}
 
 	cfg, err := config.Load()
 	if err != nil {
@@ -230,15 +314,66 @@ func runNotifications(args []string) error {
 	}
 
 	client := mastodon.NewClient(cfg.Instance, cfg.AccessToken)
-	notifications, err := client.GroupedNotifications(*limit)
+	useCachedCapabilities(cfg, client)
+	notifications, _, err := client.NotificationGroupsPage(*limit, "", filter)
 	if err != nil {
 		return err
 	}
 
-	output.PrintNotifications(notifications)
+	ruleSet, err := rules.Load()
+	if err != nil {
+		return err
+	}
+	ruleSet.SetInstance(cfg.Instance)
+
+	opts, err := displayOptions(cfg, *expandCW, *previews)
+	if err != nil {
+		return err
+	}
+	opts.Rules = ruleSet
+	opts.Context = rules.ContextNotifications
+	opts.ShowHidden = *showHidden
+	opts.Details = notificationDetails(client, notifications)
+
+	output.PrintNotifications(notifications, opts)
 	return nil
 }
 
+// parseNotificationTypes splits a comma-separated list and checks each
+// entry against mastodon.NotificationTypes.
+func parseNotificationTypes(value string) ([]string, error) {
+	var types []string
+	for _, kind := range strings.Split(value, ",") {
+		kind = strings.TrimSpace(kind)
+		if kind == "" {
+			continue
+		}
+		if !slices.Contains(mastodon.NotificationTypes, kind) {
+			return nil, fmt.Errorf("unknown notification type %q (known: %s)", kind, strings.Join(mastodon.NotificationTypes, ", "))
+		}
+		types = append(types, kind)
+	}
+	return types, nil
+}
+
+// notificationDetails fetches the payloads of the notifications whose type
+// needs one. A failed fetch only drops that payload.
+func notificationDetails(client *mastodon.Client, notifications []mastodon.GroupedNotification) map[string]*mastodon.NotificationDetail {
+	details := make(map[string]*mastodon.NotificationDetail)
+	for _, item := range notifications {
+		if !output.NotificationNeedsDetail(item.Type) || item.MostRecent == "" {
+			continue
+		}
+		detail, err := client.Notification(item.MostRecent)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Could not load notification %s: %v\n", item.MostRecent, err)
+			continue
+		}
+		details[item.MostRecent] = detail
+	}
+	return details
+}
+
 func runUI(args []string) error {
 	if len(args) != 0 {
 		return fmt.Errorf("ui does not accept arguments")
@@ -252,36 +387,87 @@ func runUI(args []string) error {
 		return fmt.Errorf("missing config; run `mastodon login --instance <domain>` first")
 	}
 
+	ruleSet, err := rules.Load()
+	if err != nil {
+		return err
+	}
+	ruleSet.SetInstance(cfg.Instance)
+
+	opts, err := displayOptions(cfg, false, false)
+	if err != nil {
+		return err
+	}
+
 	client := mastodon.NewClient(cfg.Instance, cfg.AccessToken)
-	return ui.Run(client)
+	useCachedCapabilities(cfg, client)
+	return ui.Run(client, ui.Options{
+		Rules:    ruleSet,
+		CW:       opts.CW,
+		Previews: opts.Previews != preview.ProtocolOff,
+		Cache:    opts.Cache,
+		Account:  draftAccount(cfg, client),
+	})
 }
 
 func runMetrics(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "posts":
+			return runMetricsPosts(args[1:])
+		case "followers":
+			return runMetricsFollowers(args[1:])
+		case "heatmap":
+			return runMetricsHeatmap(args[1:])
+		}
+	}
+
 	fs := flag.NewFlagSet("metrics", flag.ExitOnError)
-	rangeDays := fs.Int("range", 7, "Range in days (7 or 30)")
+	windowOpts := addWindowFlags(fs)
+	bucketName := fs.String("bucket", "day", "Group by: day, week, or month")
+	compare := fs.Bool("compare", false, "Compare with the previous period of the same length")
+	seriesNames := fs.String("series", strings.Join(metrics.DefaultSeriesKeys, ","), "Comma-separated series to print, or all")
+	export := fs.String("export", "", "Write the collected data to a .html, .csv or .json file instead of syncing")
 	fs.Parse(args)
 
-	if *rangeDays != 7 && *rangeDays != 30 {
-		return fmt.Errorf("range must be 7 or 30")
+	bucket, err := metrics.ParseBucket(*bucketName)
+	if err != nil {
+		return err
 	}
-
-	cfg, err := config.Load()
+	selected, err := metrics.ParseSeries(*seriesNames)
 	if err != nil {
 		return err
 	}
-	if cfg.Instance == "" || cfg.AccessToken == "" {
-		return fmt.Errorf("missing config; run `mastodon login --instance <domain>` first")
+	window, err := windowOpts.window(bucket)
+	if err != nil {
+		return err
+	}
+	if *export != "" {
+		if *compare {
+			return fmt.Errorf("--compare cannot be combined with --export")
+		}
+		return exportMetrics(*export, window, selected)
 	}
 
-	client := mastodon.NewClient(cfg.Instance, cfg.AccessToken)
-	showProgress := *rangeDays > 7
+	_, client, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	showProgress := window.Days() > 7
 	var lastScanned int
-	series, err := metrics.FetchDailyMetrics(client, *rangeDays, func(scanned int) {
+	progress := func(scanned int) {
 		lastScanned = scanned
 		if showProgress {
-			fmt.Fprintf(os.Stderr, "Scanned %d groups...\r", scanned)
+			fmt.Fprintf(os.Stderr, "Synced %d notifications...\r", scanned)
 		}
-	})
+	}
+	var comparison metrics.Comparison
+	if *compare {
+		comparison, err = metrics.FetchComparison(client, window, progress)
+	} else {
+		comparison.Current, err = metrics.FetchMetrics(client, window, progress)
+		comparison.Anomalies = metrics.Anomalies(comparison.Current)
+	}
 	if err != nil {
 		return err
 	}
@@ -289,27 +475,84 @@ func runMetrics(args []string) error {
 		fmt.Fprintln(os.Stderr)
 	}
 
-	output.PrintDailyMetrics(series)
+	output.PrintDailyMetrics(comparison.Current, comparison.Anomalies, selected)
+	if *compare {
+		output.PrintComparison(comparison)
+	}
 	return nil
 }
 
+// authenticatedClient loads the config and fails when no login is stored.
+func authenticatedClient() (*config.Config, *mastodon.Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.Instance == "" || cfg.AccessToken == "" {
+		return nil, nil, fmt.Errorf("missing config; run `mastodon login --instance <domain>` first")
+	}
+	return cfg, mastodon.NewClient(cfg.Instance, cfg.AccessToken), nil
+}
+
+// displayOptions applies the content warning and media preview settings
+// from the config, with command flags taking precedence.
+func displayOptions(cfg *config.Config, expandCW, previews bool) (output.DisplayOptions, error) {
+	if !output.ValidCWMode(cfg.CWDefault) {
+		return output.DisplayOptions{}, fmt.Errorf("cw_default must be one of: always, never, keywords")
+	}
+	protocol, err := preview.ParseProtocol(cfg.MediaPreviews)
+	if err != nil {
+		return output.DisplayOptions{}, err
+	}
+
+	opts := output.DisplayOptions{
+		CW:       output.CWPolicy{Mode: cfg.CWDefault, Keywords: cfg.CWKeywords},
+		Previews: protocol,
+	}
+	if expandCW {
+		opts.CW.Mode = output.CWAlways
+	}
+	if previews && opts.Previews == preview.ProtocolOff {
+		opts.Previews = preview.ProtocolAuto
+	}
+	if opts.Previews != preview.ProtocolOff {
+		cache, err := preview.NewCache()
+		if err != nil {
+			return output.DisplayOptions{}, err
+		}
+		opts.Cache = cache
+	}
+	return opts, nil
+}
+
 func printUsage() {
 	fmt.Println("Usage:")
 	fmt.Println("  mastodon login --instance <domain> [--force]")
-	fmt.Println("  mastodon timeline --limit <n> [--type home|local|federated|trending]")
-	fmt.Println("  mastodon posts --limit <n> [--boosts] [--replies]")
-	fmt.Println("  mastodon notifications --limit <n>")
-	fmt.Println("  mastodon metrics --range <7|30>")
+	fmt.Println("  mastodon timeline --limit <n> [--type home|local|federated|trending] [--show-hidden] [--expand-cw] [--previews]")
+	fmt.Println("  mastodon posts --limit <n> [--boosts] [--replies] [--expand-cw] [--previews]")
+	fmt.Println("  mastodon notifications --limit <n> [--types t,...] [--exclude t,...] [--mentions] [--show-hidden] [--expand-cw] [--previews]")
+	fmt.Println("  mastodon notifications policy show|set [--for-not-following v] [--for-not-followers v] [--for-new-accounts v] [--for-private-mentions v] [--for-limited-accounts v]")
+	fmt.Println("  mastodon notifications requests list [--limit <n>] | accept|dismiss <id>...")
+	fmt.Println("  mastodon metrics [--range <days>] [--since YYYY-MM-DD] [--until YYYY-MM-DD] [--bucket day|week|month] [--series <list>] [--compare] [--export <file>]")
+	fmt.Println("  mastodon metrics posts [--range <days>] [--since YYYY-MM-DD] [--until YYYY-MM-DD] [--sort score|favourites|reblogs|replies] [--limit <n>]")
+	fmt.Println("  mastodon metrics followers [--range <days>] [--since YYYY-MM-DD] [--until YYYY-MM-DD] [--snapshot]")
+	fmt.Println("  mastodon metrics heatmap [--range <days>] [--since YYYY-MM-DD] [--until YYYY-MM-DD] [--tz <zone>] [--source engagement|posts|both] [--format matrix|csv]")
+	fmt.Println("  mastodon post [--visibility v] [--cw text] [--reply-to id] [--poll-option o]... [--at \"YYYY-MM-DD HH:MM\" [--tz <zone>]] [--media <file> [--alt text] [--focus x,y]]... [--allow-no-alt] [--draft] <text>")
+	fmt.Println("  mastodon post --thread <file> [--counters] [--followup-visibility v] [--max-chars <n>] [--yes] [--visibility v] [--cw text] [--reply-to id]")
+	fmt.Println("  mastodon scheduled list [--tz <zone>]")
+	fmt.Println("  mastodon scheduled show [--tz <zone>] <id>")
+	fmt.Println("  mastodon scheduled reschedule [--tz <zone>] <id> \"YYYY-MM-DD HH:MM\"")
+	fmt.Println("  mastodon scheduled cancel <id>")
+	fmt.Println("  mastodon drafts list [--all]")
+	fmt.Println("  mastodon drafts edit|delete <id>")
+	fmt.Println("  mastodon drafts post [--allow-no-alt] <id>")
+	fmt.Println("  mastodon edit [--history] [--poll-expires <duration>] <status-id>")
+	fmt.Println("  mastodon poll show <status-id>")
+	fmt.Println("  mastodon poll vote <status-id> <choice>...")
+	fmt.Println("  mastodon instance [--refresh] [domain]")
+	fmt.Println("  mastodon watch notifications [--types t,...] [--exclude t,...] [--mentions] [--desktop] [watch flags]")
+	fmt.Println("  mastodon watch timeline [watch flags] [--types post,reply,boost] [home|local|federated]")
+	fmt.Println("  mastodon watch hashtag|list [watch flags] [--types post,reply,boost] <tag|list-id>")
+	fmt.Println("    watch flags: [--exec cmd] [--webhook URL]... [--retries n] [--keyword k]... [--account acct]... [--quiet HH:MM-HH:MM] [--poll] [--interval d]")
 	fmt.Println("  mastodon ui")
 }
-
-func openBrowser(url string) error {
-	switch runtime.GOOS {
-	case "darwin":
-		return exec.Command("open", url).Start()
-	case "windows":
-		return exec.Command("cmd", "/c", "start", url).Start()
-	default:
-		return exec.Command("xdg-open", url).Start()
-	}
-}
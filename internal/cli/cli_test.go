@@ -1,13 +1,21 @@
 package cli
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"mastodoncli/internal/capabilities"
 	"mastodoncli/internal/config"
+	"mastodoncli/internal/drafts"
+	"mastodoncli/internal/mastodon"
 )
 
 func TestRunTimelineRejectsInvalidType(t *testing.T) {
@@ -22,6 +30,15 @@ func TestRunTimelineRejectsInvalidLimit(t *testing.T) {
 	}
 }
 
+func TestRunWatchRejectsEmptyTagAndList(t *testing.T) {
+	if err := runWatch([]string{"hashtag", "--exec", "true", "#"}); err == nil || err.Error() != "tag cannot be empty" {
+		t.Fatalf("expected an empty tag to be refused, got %v", err)
+	}
+	if err := runWatch([]string{"list", "--exec", "true", " "}); err == nil || err.Error() != "list ID cannot be empty" {
+		t.Fatalf("expected an empty list ID to be refused, got %v", err)
+	}
+}
+
 func TestRunTimelineLocalUsesPublicEndpoint(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/api/v1/timelines/public" {
@@ -59,3 +76,462 @@ func TestRunTimelineLocalUsesPublicEndpoint(t *testing.T) {
 		t.Fatalf("expected config dir to exist: %v", err)
 	}
 }
+
+func TestParsePollChoices(t *testing.T) {
+	poll := mastodon.Poll{Options: []mastodon.PollOption{{Title: "a"}, {Title: "b"}, {Title: "c"}}, Multiple: true}
+
+	choices, err := parsePollChoices([]string{"1", "3", "3"}, poll)
+	if err != nil {
+		t.Fatalf("parse choices: %v", err)
+	}
+	if len(choices) != 2 || choices[0] != 0 || choices[1] != 2 {
+		t.Fatalf("unexpected choices: %v", choices)
+	}
+
+	if _, err := parsePollChoices([]string{"4"}, poll); err == nil {
+		t.Fatal("expected error for out of range choice")
+	}
+
+	poll.Multiple = false
+	if _, err := parsePollChoices([]string{"1", "2"}, poll); err == nil {
+		t.Fatal("expected error for multiple choices on a single-choice poll")
+	}
+}
+
+func TestRunPostSendsPoll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/statuses" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var body mastodon.StatusParams
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if body.Status != "Lunch?" || body.Poll == nil || len(body.Poll.Options) != 2 || body.Poll.ExpiresIn != 3600 {
+			t.Fatalf("unexpected body: %+v", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "1"}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := config.Save(&config.Config{Instance: server.URL, AccessToken: "token"}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	err := runPost([]string{"--poll-option", "Pizza", "--poll-option", "Salad", "--poll-expires", "1h", "Lunch?"})
+	if err != nil {
+		t.Fatalf("runPost error: %v", err)
+	}
+}
+
+func TestRunPostSchedulesInTimezone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveInstance(w, r, "4.3.0") {
+			return
+		}
+		var body mastodon.StatusParams
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if body.ScheduledAt != "2099-11-01T08:00:00Z" {
+			t.Fatalf("unexpected scheduled_at: %q", body.ScheduledAt)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "7", "scheduled_at": "2099-11-01T08:00:00.000Z", "params": {"text": "Meetup"}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := config.Save(&config.Config{Instance: server.URL, AccessToken: "token"}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	if err := runPost([]string{"--at", "2099-11-01 09:00", "--tz", "Europe/Berlin", "Meetup"}); err != nil {
+		t.Fatalf("runPost error: %v", err)
+	}
+	if err := runPost([]string{"--at", "2000-01-01 09:00", "Too late"}); err == nil {
+		t.Fatal("expected error for a time in the past")
+	}
+}
+
+func TestRunPostRefusesSchedulingWithoutSupport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveInstance(w, r, "2.6.0") {
+			return
+		}
+		t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := config.Save(&config.Config{Instance: server.URL, AccessToken: "token"}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	err := runPost([]string{"--at", "2099-11-01 09:00", "Meetup"})
+	if err == nil || !strings.Contains(err.Error(), "does not support scheduled statuses") {
+		t.Fatalf("expected scheduling to be refused, got %v", err)
+	}
+}
+
+func TestRunPostKeepsDraftWhenPostingFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"error": "down for maintenance"}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	cfg := &config.Config{Instance: server.URL, AccessToken: "token", Account: "alice@example.social"}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	if err := runPost([]string{"--cw", "spoilers", "--visibility", "unlisted", "The ending!"}); err == nil {
+		t.Fatal("expected error when the server fails")
+	}
+
+	store, err := drafts.Load()
+	if err != nil {
+		t.Fatalf("load drafts: %v", err)
+	}
+	list := store.List("alice@example.social")
+	if len(list) != 1 {
+		t.Fatalf("expected 1 draft, got %d", len(list))
+	}
+	draft := list[0]
+	if draft.Text != "The ending!" || draft.SpoilerText != "spoilers" || draft.Visibility != "unlisted" {
+		t.Fatalf("unexpected draft: %+v", draft)
+	}
+	if others := store.List("bob@example.social"); len(others) != 0 {
+		t.Fatalf("expected no drafts for another account, got %d", len(others))
+	}
+}
+
+func TestRunPostKeepsUntaggedDraftWhenAccountUnknown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := config.Save(&config.Config{Instance: server.URL, AccessToken: "token"}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	if err := runPost([]string{"Offline thoughts"}); err == nil {
+		t.Fatal("expected error when the server fails")
+	}
+
+	store, err := drafts.Load()
+	if err != nil {
+		t.Fatalf("load drafts: %v", err)
+	}
+	list := store.List("alice@example.social")
+	if len(list) != 1 || list[0].Account != "" {
+		t.Fatalf("expected one untagged draft for the next account, got %+v", list)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Account != "" {
+		t.Fatalf("config was rewritten with account %q", cfg.Account)
+	}
+}
+
+func TestRunPostUploadsMediaWithAltText(t *testing.T) {
+	var posted mastodon.StatusParams
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v2/instance":
+			_, _ = w.Write([]byte(`{"configuration": {"statuses": {"max_media_attachments": 4}, "media_attachments": {"supported_mime_types": ["image/png"], "image_size_limit": 1048576}}}`))
+		case "/.well-known/nodeinfo":
+			http.NotFound(w, r)
+		case "/api/v2/media":
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Fatalf("parse upload: %v", err)
+			}
+			if r.FormValue("description") != "A red square" || r.FormValue("focus") != "0,0.5" {
+				t.Fatalf("unexpected upload fields: %v", r.MultipartForm.Value)
+			}
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"id": "m1", "type": "image", "url": null}`))
+		case "/api/v1/media/m1":
+			_, _ = w.Write([]byte(`{"id": "m1", "type": "image", "url": "https://files.example/m1.png"}`))
+		case "/api/v1/statuses":
+			if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"id": "9"}`))
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := config.Save(&config.Config{Instance: server.URL, AccessToken: "token"}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	image := filepath.Join(t.TempDir(), "square.png")
+	if err := os.WriteFile(image, []byte("\x89PNG\r\n\x1a\n"), 0o600); err != nil {
+		t.Fatalf("write image: %v", err)
+	}
+
+	if err := runPost([]string{"--media", image, "Look"}); err == nil {
+		t.Fatal("expected an error for an image without alt text")
+	}
+	if err := runPost([]string{"--media", image, "--alt", "A red square", "--focus", "0,0.5", "Look"}); err != nil {
+		t.Fatalf("runPost error: %v", err)
+	}
+	if len(posted.MediaIDs) != 1 || posted.MediaIDs[0] != "m1" {
+		t.Fatalf("unexpected media_ids: %v", posted.MediaIDs)
+	}
+}
+
+func TestRunEditKeepsMediaAndPoll(t *testing.T) {
+	var edited mastodon.EditParams
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveInstance(w, r, "4.3.0") {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v1/statuses/7":
+			_, _ = w.Write([]byte(`{"id": "7", "language": "en", "media_attachments": [{"id": "m1"}], "poll": {"id": "p1", "expires_at": "2999-01-01T00:00:00Z", "options": [{"title": "Yes"}, {"title": "No"}]}}`))
+		case r.Method == "GET" && r.URL.Path == "/api/v1/statuses/7/source":
+			_, _ = w.Write([]byte(`{"id": "7", "text": "Is teh poll open?", "spoiler_text": ""}`))
+		case r.Method == "PUT" && r.URL.Path == "/api/v1/statuses/7":
+			if err := json.NewDecoder(r.Body).Decode(&edited); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"id": "7"}`))
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := config.Save(&config.Config{Instance: server.URL, AccessToken: "token"}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	script := "#!/bin/sh\nprintf 'CW: \\n\\nIs the poll open?\\n' > \"$1\"\n"
+	if err := os.WriteFile(editor, []byte(script), 0o700); err != nil {
+		t.Fatalf("write editor: %v", err)
+	}
+	t.Setenv("VISUAL", editor)
+
+	if err := runEdit([]string{"7"}); err != nil {
+		t.Fatalf("runEdit error: %v", err)
+	}
+	if edited.Status != "Is the poll open?" || edited.Language != "en" {
+		t.Fatalf("unexpected edit: %+v", edited)
+	}
+	if len(edited.MediaIDs) != 1 || edited.MediaIDs[0] != "m1" {
+		t.Fatalf("unexpected media_ids: %v", edited.MediaIDs)
+	}
+	if edited.Poll == nil || len(edited.Poll.Options) != 2 || edited.Poll.ExpiresIn < 300 || !edited.Poll.HideTotals {
+		t.Fatalf("unexpected poll: %+v", edited.Poll)
+	}
+}
+
+func TestRunNotificationsFiltersTypes(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveInstance(w, r, "4.3.0") {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/api/v2/notifications" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`{
+			"accounts": [{"id": "1", "acct": "alice"}],
+			"statuses": [{"id": "5", "content": "<p>hi</p>"}],
+			"notification_groups": [{"group_key": "ungrouped-9", "type": "mention", "notifications_count": 1, "most_recent_notification_id": "9", "sample_account_ids": ["1"], "status_id": "5"}]
+		}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := config.Save(&config.Config{Instance: server.URL, AccessToken: "token"}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	if err := runNotifications([]string{"--types", "mention,bogus"}); err == nil {
+		t.Fatal("expected error for an unknown type")
+	}
+	if err := runNotifications([]string{"--mentions", "--exclude", "favourite"}); err != nil {
+		t.Fatalf("runNotifications error: %v", err)
+	}
+	if got := query["types[]"]; len(got) != 1 || got[0] != "mention" {
+		t.Fatalf("unexpected types[]: %v", got)
+	}
+	if got := query["exclude_types[]"]; len(got) != 1 || got[0] != "favourite" {
+		t.Fatalf("unexpected exclude_types[]: %v", got)
+	}
+}
+
+func TestRunNotificationsGroupsV1(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/instance", "/api/v2/notifications", "/.well-known/nodeinfo":
+			http.NotFound(w, r)
+		case "/api/v1/instance":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"version": "3.5.3 (compatible; GoToSocial 0.17.0)"}`))
+		case "/api/v1/notifications":
+			query = r.URL.Query()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[
+				{"id": "3", "type": "favourite", "created_at": "2024-05-02T10:00:00Z", "account": {"id": "1", "acct": "alice"}, "status": {"id": "5"}},
+				{"id": "2", "type": "favourite", "created_at": "2024-05-02T09:00:00Z", "account": {"id": "2", "acct": "bob"}, "status": {"id": "5"}},
+				{"id": "1", "type": "mention", "created_at": "2024-05-01T09:00:00Z", "account": {"id": "2", "acct": "bob"}, "status": {"id": "4"}}
+			]`))
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := config.Save(&config.Config{Instance: server.URL, AccessToken: "token"}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	printed := captureStdout(t, func() {
+		if err := runNotifications([]string{"--exclude", "follow"}); err != nil {
+			t.Fatalf("runNotifications error: %v", err)
+		}
+	})
+	if got := query["exclude_types[]"]; len(got) != 1 || got[0] != "follow" {
+		t.Fatalf("unexpected exclude_types[]: %v", got)
+	}
+	if strings.Count(printed, "----") != 2 {
+		t.Fatalf("expected 2 groups, got:\n%s", printed)
+	}
+	if !strings.Contains(printed, "Favorite (2)") || !strings.Contains(printed, "@alice +1") {
+		t.Fatalf("expected the favourites grouped, got:\n%s", printed)
+	}
+	if !strings.Contains(printed, "Mention (1)") {
+		t.Fatalf("expected the mention on its own, got:\n%s", printed)
+	}
+}
+
+func TestRunNotificationPolicySet(t *testing.T) {
+	var update map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if serveInstance(w, r, "4.3.0") {
+			return
+		}
+		if r.Method != "PATCH" || r.URL.Path != "/api/v2/notifications/policy" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"for_not_following": "accept", "for_new_accounts": "filter"}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := config.Save(&config.Config{Instance: server.URL, AccessToken: "token"}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	if err := runNotifications([]string{"policy", "set", "--for-new-accounts", "hide"}); err == nil {
+		t.Fatal("expected error for an unknown policy value")
+	}
+	if err := runNotifications([]string{"policy", "set", "--for-new-accounts", "filter"}); err != nil {
+		t.Fatalf("policy set error: %v", err)
+	}
+	if len(update) != 1 || update["for_new_accounts"] != "filter" {
+		t.Fatalf("unexpected update: %v", update)
+	}
+}
+
+func TestRunInstanceCachesCapabilities(t *testing.T) {
+	probes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v2/instance":
+			probes++
+			_, _ = w.Write([]byte(`{"domain": "social.example", "title": "Example", "version": "3.5.3 (compatible; GoToSocial 0.17.0)", "registrations": {"enabled": false}, "rules": [{"id": "1", "text": "Be kind"}], "configuration": {"statuses": {"max_characters": 5000}}}`))
+		case "/.well-known/nodeinfo":
+			fmt.Fprintf(w, `{"links": [{"rel": "http://nodeinfo.diaspora.software/ns/schema/2.0", "href": "http://%s/nodeinfo/2.0"}]}`, r.Host)
+		case "/nodeinfo/2.0":
+			_, _ = w.Write([]byte(`{"software": {"name": "gotosocial", "version": "0.17.0"}}`))
+		case "/api/v1/instance/extended_description":
+			http.NotFound(w, r)
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := config.Save(&config.Config{Instance: server.URL, AccessToken: "token"}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	if err := runInstance(nil); err != nil {
+		t.Fatalf("runInstance error: %v", err)
+	}
+	err := runNotifications([]string{"policy", "show"})
+	if err == nil || !strings.Contains(err.Error(), "GoToSocial 0.17.0 does not support") {
+		t.Fatalf("expected the policy to be refused, got %v", err)
+	}
+	store, err := capabilities.Load()
+	if err != nil {
+		t.Fatalf("load capabilities: %v", err)
+	}
+	if len(store.Instances) != 1 || probes != 1 {
+		t.Fatalf("expected one cached instance after one probe, got %d instances and %d instance requests", len(store.Instances), probes)
+	}
+}
+
+// serveInstance answers the capability probe as a Mastodon server of the
+// given version without nodeinfo, and reports whether r was part of it.
+func serveInstance(w http.ResponseWriter, r *http.Request, version string) bool {
+	switch r.URL.Path {
+	case "/api/v2/instance":
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"version": %q}`, version)
+		return true
+	case "/.well-known/nodeinfo":
+		http.NotFound(w, r)
+		return true
+	}
+	return false
+}
+
+// captureStdout returns what fn prints to standard output.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		done <- string(data)
+	}()
+	fn()
+	writer.Close()
+	return <-done
+}
@@ -0,0 +1,274 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"mastodoncli/internal/config"
+	"mastodoncli/internal/drafts"
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+)
+
+func runDrafts(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: mastodon drafts list|edit|post|delete")
+	}
+	switch args[0] {
+	case "list":
+		return runDraftsList(args[1:])
+	case "edit":
+		return runDraftsEdit(args[1:])
+	case "post":
+		return runDraftsPost(args[1:])
+	case "delete":
+		return runDraftsDelete(args[1:])
+	default:
+		return fmt.Errorf("unknown drafts command: %s", args[0])
+	}
+}
+
+func runDraftsList(args []string) error {
+	fs := flag.NewFlagSet("drafts list", flag.ExitOnError)
+	all := fs.Bool("all", false, "Include drafts of other accounts")
+	fs.Parse(args)
+
+	store, err := drafts.Load()
+	if err != nil {
+		return err
+	}
+	if *all {
+		output.PrintDrafts(store.List(""), true)
+		return nil
+	}
+	cfg, client, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+	output.PrintDrafts(store.List(draftAccount(cfg, client)), false)
+	return nil
+}
+
+func runDraftsEdit(args []string) error {
+	fs := flag.NewFlagSet("drafts edit", flag.ExitOnError)
+	fs.Parse(args)
+
+	id, err := draftID(fs.Args(), "edit <id>")
+	if err != nil {
+		return err
+	}
+	store, err := drafts.Load()
+	if err != nil {
+		return err
+	}
+	draft, ok := store.Get(id)
+	if !ok {
+		return fmt.Errorf("draft %s not found", id)
+	}
+
+	edited, err := editText(formatDraftFile(draft), "mastodon-draft-*.txt")
+	if err != nil {
+		return err
+	}
+	draft, err = parseDraftFile(edited, draft)
+	if err != nil {
+		return fmt.Errorf("draft not changed: %w", err)
+	}
+	if err := drafts.Update(func(store *drafts.Store) error {
+		store.Put(draft, time.Now())
+		return nil
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("Saved draft %s\n", draft.ID)
+	return nil
+}
+
+func runDraftsPost(args []string) error {
+	fs := flag.NewFlagSet("drafts post", flag.ExitOnError)
+	allowNoAlt := fs.Bool("allow-no-alt", false, "Allow images without alt text")
+	fs.Parse(args)
+
+	id, err := draftID(fs.Args(), "post <id>")
+	if err != nil {
+		return err
+	}
+	store, err := drafts.Load()
+	if err != nil {
+		return err
+	}
+	draft, ok := store.Get(id)
+	if !ok {
+		return fmt.Errorf("draft %s not found", id)
+	}
+	params := draft.Params()
+	if err := validateStatusParams(params, len(draft.Media)); err != nil {
+		return err
+	}
+	cfg, client, err := postingClient(params)
+	if err != nil {
+		return err
+	}
+	if account := draftAccount(cfg, client); !draft.BelongsTo(account) {
+		return fmt.Errorf("draft %s belongs to %s, but you are logged in as %s", id, draft.Account, account)
+	}
+	if err := checkMedia(cfg, client, draft.Media, !*allowNoAlt); err != nil {
+		return err
+	}
+	message, err := uploadAndSend(client, params, draft.Media, time.Local)
+	if err != nil {
+		return err
+	}
+	if err := drafts.Update(func(store *drafts.Store) error {
+		store.Delete(id)
+		return nil
+	}); err != nil {
+		return err
+	}
+	fmt.Println(message)
+	return nil
+}
+
+func runDraftsDelete(args []string) error {
+	fs := flag.NewFlagSet("drafts delete", flag.ExitOnError)
+	fs.Parse(args)
+
+	id, err := draftID(fs.Args(), "delete <id>")
+	if err != nil {
+		return err
+	}
+	if err := drafts.Update(func(store *drafts.Store) error {
+		if !store.Delete(id) {
+			return fmt.Errorf("draft %s not found", id)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted draft %s\n", id)
+	return nil
+}
+
+func draftID(args []string, usage string) (string, error) {
+	if len(args) != 1 || args[0] == "" {
+		return "", fmt.Errorf("usage: mastodon drafts %s", usage)
+	}
+	return args[0], nil
+}
+
+// draftAccount returns the account drafts are tagged with, as saved at
+// login. Configs written before drafts existed ask the server for this run
+// only; when that fails too it returns "" and drafts stay untagged.
+func draftAccount(cfg *config.Config, client *mastodon.Client) string {
+	if cfg.Account != "" {
+		return cfg.Account
+	}
+	account, err := client.VerifyCredentials()
+	if err != nil {
+		return ""
+	}
+	cfg.Account = drafts.AccountTag(account.Acct, cfg.Instance)
+	return cfg.Account
+}
+
+// saveDraft stores params and the media still to upload as a draft of the
+// logged-in account and returns its ID.
+func saveDraft(cfg *config.Config, client *mastodon.Client, params mastodon.StatusParams, media []drafts.Media) (string, error) {
+	draft := drafts.FromParams(params, draftAccount(cfg, client))
+	draft.Media = media
+	var id string
+	err := drafts.Update(func(store *drafts.Store) error {
+		id = store.Put(draft, time.Now())
+		return nil
+	})
+	return id, err
+}
+
+// draftHeaders are the fields editable above the text of a draft file.
+var draftHeaders = []string{"Visibility", "CW", "Reply-To", "Language"}
+
+func formatDraftFile(draft drafts.Draft) string {
+	values := map[string]string{
+		"Visibility": draft.Visibility,
+		"CW":         draft.SpoilerText,
+		"Reply-To":   draft.InReplyToID,
+		"Language":   draft.Language,
+	}
+	var b strings.Builder
+	b.WriteString("# Headers end at the first blank line; the status text follows.\n")
+	for _, name := range draftHeaders {
+		fmt.Fprintf(&b, "%s: %s\n", name, values[name])
+	}
+	b.WriteString("# One line per attachment: Media: <path> | <alt text> | <focus x,y>\n")
+	for _, media := range draft.Media {
+		fmt.Fprintf(&b, "Media: %s | %s | %s\n", media.Path, media.Alt, media.Focus)
+	}
+	b.WriteString("\n")
+	b.WriteString(draft.Text)
+	b.WriteString("\n")
+	return b.String()
+}
+
+func parseDraftFile(text string, draft drafts.Draft) (drafts.Draft, error) {
+	head, body, _ := strings.Cut(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n")
+	draft.Media = nil
+	for _, line := range strings.Split(head, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return draft, fmt.Errorf("invalid header line %q", line)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(name) {
+		case "Visibility":
+			draft.Visibility = value
+		case "CW":
+			draft.SpoilerText = value
+		case "Reply-To":
+			draft.InReplyToID = value
+		case "Language":
+			draft.Language = value
+		case "Media":
+			media, err := parseDraftMedia(value)
+			if err != nil {
+				return draft, err
+			}
+			draft.Media = append(draft.Media, media)
+		default:
+			return draft, fmt.Errorf("unknown header %q", name)
+		}
+	}
+	draft.Text = strings.TrimSpace(body)
+	switch draft.Visibility {
+	case "", "public", "unlisted", "private", "direct":
+	default:
+		return draft, fmt.Errorf("visibility must be one of: public, unlisted, private, direct")
+	}
+	return draft, nil
+}
+
+func parseDraftMedia(value string) (drafts.Media, error) {
+	fields := strings.SplitN(value, "|", 3)
+	for len(fields) < 3 {
+		fields = append(fields, "")
+	}
+	media := drafts.Media{
+		Path:  strings.TrimSpace(fields[0]),
+		Alt:   strings.TrimSpace(fields[1]),
+		Focus: strings.TrimSpace(fields[2]),
+	}
+	if media.Path == "" {
+		return media, fmt.Errorf("media line without a path")
+	}
+	if media.Focus != "" {
+		if _, _, err := mastodon.ParseFocus(media.Focus); err != nil {
+			return media, fmt.Errorf("%s: %w", media.Path, err)
+		}
+	}
+	return media, nil
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+)
+
+func runEdit(args []string) error {
+	fs := flag.NewFlagSet("edit", flag.ExitOnError)
+	history := fs.Bool("history", false, "Show earlier revisions as a word diff instead of editing")
+	pollExpires := fs.Duration("poll-expires", 0, "Give the poll a new duration from now (e.g. 30m, 24h)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: mastodon edit [--history] [--poll-expires <duration>] <status-id>")
+	}
+	id := fs.Arg(0)
+	if *pollExpires != 0 && *pollExpires < mastodon.MinPollDuration {
+		return fmt.Errorf("poll duration must be at least 5m")
+	}
+
+	_, client, err := clientSupporting(func(caps *mastodon.Capabilities) bool { return caps.StatusEdits }, "editing statuses")
+	if err != nil {
+		return err
+	}
+	if *history {
+		edits, err := client.StatusHistory(id)
+		if err != nil {
+			return err
+		}
+		output.PrintHistory(edits)
+		return nil
+	}
+
+	status, err := client.GetStatus(id)
+	if err != nil {
+		return err
+	}
+	if status.Reblog != nil {
+		return fmt.Errorf("status %s is a boost; edit the original instead", id)
+	}
+	source, err := client.GetStatusSource(id)
+	if err != nil {
+		return err
+	}
+	params, err := mastodon.EditParamsFor(*status, *source, time.Now())
+	if errors.Is(err, mastodon.ErrPollClosingSoon) && *pollExpires == 0 {
+		return fmt.Errorf("%w; pass --poll-expires to give it a new end", err)
+	} else if err != nil && !errors.Is(err, mastodon.ErrPollClosingSoon) {
+		return err
+	}
+	if *pollExpires != 0 {
+		if params.Poll == nil {
+			return fmt.Errorf("status %s has no poll", id)
+		}
+		params.Poll.ExpiresIn = int(pollExpires.Seconds())
+	}
+
+	edited, err := editText(formatEditFile(params), "mastodon-edit-*.txt")
+	if err != nil {
+		return err
+	}
+	text, spoiler, err := parseEditFile(edited)
+	if err != nil {
+		return fmt.Errorf("status not changed: %w", err)
+	}
+	if text == params.Status && spoiler == params.SpoilerText && *pollExpires == 0 {
+		fmt.Println("No changes.")
+		return nil
+	}
+	if text == "" && len(params.MediaIDs) == 0 && params.Poll == nil {
+		return fmt.Errorf("status text is empty")
+	}
+	params.Status = text
+	params.SpoilerText = spoiler
+
+	updated, err := client.EditStatus(id, params)
+	if err != nil {
+		// The server kept the old version; print the new text so it is
+		// not lost.
+		fmt.Fprintf(os.Stderr, "Your edit:\n%s\n", text)
+		return err
+	}
+	fmt.Printf("Edited %s\n", statusLink(updated))
+	return nil
+}
+
+func formatEditFile(params mastodon.EditParams) string {
+	var b strings.Builder
+	b.WriteString("# Headers end at the first blank line; the status text follows.\n")
+	fmt.Fprintf(&b, "CW: %s\n\n", params.SpoilerText)
+	b.WriteString(params.Status)
+	b.WriteString("\n")
+	return b.String()
+}
+
+func parseEditFile(text string) (string, string, error) {
+	head, body, _ := strings.Cut(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n")
+	spoiler := ""
+	for _, line := range strings.Split(head, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return "", "", fmt.Errorf("invalid header line %q", line)
+		}
+		if strings.TrimSpace(name) != "CW" {
+			return "", "", fmt.Errorf("unknown header %q", name)
+		}
+		spoiler = strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(body), spoiler, nil
+}
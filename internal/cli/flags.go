@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"mastodoncli/internal/drafts"
+	"mastodoncli/internal/mastodon"
+)
+
+// stringList collects the values of a repeatable flag.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ", ")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// mediaFlag is one of --media, --alt and --focus. --media adds a file; the
+// other two describe the file named just before them.
+type mediaFlag struct {
+	media *[]drafts.Media
+	field string
+}
+
+func (f mediaFlag) String() string {
+	if f.media == nil {
+		return ""
+	}
+	var paths []string
+	for _, media := range *f.media {
+		paths = append(paths, media.Path)
+	}
+	return strings.Join(paths, ", ")
+}
+
+func (f mediaFlag) Set(value string) error {
+	if f.field == "media" {
+		*f.media = append(*f.media, drafts.Media{Path: value})
+		return nil
+	}
+	if len(*f.media) == 0 {
+		return fmt.Errorf("--%s must follow the --media it describes", f.field)
+	}
+	last := &(*f.media)[len(*f.media)-1]
+	switch f.field {
+	case "alt":
+		if last.Alt != "" {
+			return fmt.Errorf("%s already has alt text", last.Path)
+		}
+		last.Alt = value
+	case "focus":
+		if _, _, err := mastodon.ParseFocus(value); err != nil {
+			return err
+		}
+		last.Focus = value
+	}
+	return nil
+}
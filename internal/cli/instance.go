@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"mastodoncli/internal/capabilities"
+	"mastodoncli/internal/config"
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+)
+
+func runInstance(args []string) error {
+	fs := flag.NewFlagSet("instance", flag.ExitOnError)
+	refresh := fs.Bool("refresh", false, "Probe the instance again instead of using the cached capabilities")
+	fs.Parse(args)
+
+	if fs.NArg() > 1 {
+		return fmt.Errorf("usage: mastodon instance [--refresh] [domain]")
+	}
+	var domain, token string
+	if fs.NArg() == 1 {
+		domain = fs.Arg(0)
+	} else {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if cfg.Instance == "" {
+			return fmt.Errorf("missing config; run `mastodon login --instance <domain>` first or name a domain")
+		}
+		domain, token = cfg.Instance, cfg.AccessToken
+	}
+
+	client := mastodon.NewClient(domain, token)
+	instance, err := client.Instance()
+	if err != nil {
+		return err
+	}
+	caps, err := capabilities.ForInstance(client, domain, instance, *refresh, time.Now())
+	if err != nil {
+		return err
+	}
+	about, err := client.ExtendedDescription()
+	if err != nil {
+		return err
+	}
+	output.PrintInstance(*instance, *caps, about)
+	return nil
+}
+
+// useCachedCapabilities hands the client the capabilities cached on disk,
+// probing when they are stale, so its own checks (instance limits, grouped
+// notifications) can use them. Failures are left for those checks to run
+// into.
+func useCachedCapabilities(cfg *config.Config, client *mastodon.Client) {
+	_, _ = capabilities.For(client, cfg.Instance, false, time.Now())
+}
+
+// clientSupporting is authenticatedClient for commands whose endpoints not
+// every server has. It refuses when the instance is known to lack the
+// feature; when the probe itself fails the request goes ahead and the
+// server has the last word.
+func clientSupporting(supported func(*mastodon.Capabilities) bool, feature string) (*config.Config, *mastodon.Client, error) {
+	cfg, client, err := authenticatedClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	caps, err := capabilities.For(client, cfg.Instance, false, time.Now())
+	if err == nil && !supported(caps) {
+		return nil, nil, fmt.Errorf("%s does not support %s", caps.Name(), feature)
+	}
+	return cfg, client, nil
+}
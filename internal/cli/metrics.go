@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"mastodoncli/internal/metrics"
+	"mastodoncli/internal/output"
+)
+
+// windowFlags are the --range/--since/--until flags shared by the metrics
+// commands.
+type windowFlags struct {
+	rangeDays *int
+	since     *string
+	until     *string
+}
+
+func addWindowFlags(fs *flag.FlagSet) windowFlags {
+	return windowFlags{
+		rangeDays: fs.Int("range", 7, "Range in days, ending today or at --until"),
+		since:     fs.String("since", "", "First day (YYYY-MM-DD)"),
+		until:     fs.String("until", "", "Last day (YYYY-MM-DD), default today"),
+	}
+}
+
+func (w windowFlags) window(bucket metrics.Bucket) (metrics.Window, error) {
+	if *w.rangeDays < 1 || *w.rangeDays > metrics.MaxDays {
+		return metrics.Window{}, fmt.Errorf("range must be between 1 and %d", metrics.MaxDays)
+	}
+	return metrics.NewWindow(*w.rangeDays, *w.since, *w.until, bucket, time.Now())
+}
+
+func runMetricsPosts(args []string) error {
+	fs := flag.NewFlagSet("metrics posts", flag.ExitOnError)
+	windowOpts := addWindowFlags(fs)
+	sortBy := fs.String("sort", "score", "Rank by: score, favourites, reblogs, or replies")
+	limit := fs.Int("limit", 10, "Number of posts to list")
+	fs.Parse(args)
+
+	switch *sortBy {
+	case "score", "favourites", "reblogs", "replies":
+	default:
+		return fmt.Errorf("sort must be one of: score, favourites, reblogs, replies")
+	}
+	if *limit < 1 {
+		return fmt.Errorf("limit must be at least 1")
+	}
+	window, err := windowOpts.window(metrics.BucketDay)
+	if err != nil {
+		return err
+	}
+
+	_, client, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+	posts, err := metrics.FetchPosts(client, window)
+	if err != nil {
+		return err
+	}
+	metrics.SortPosts(posts, *sortBy)
+
+	output.PrintPostsReport(metrics.BuildPostsReport(posts), *limit)
+	return nil
+}
+
+func runMetricsFollowers(args []string) error {
+	fs := flag.NewFlagSet("metrics followers", flag.ExitOnError)
+	windowOpts := addWindowFlags(fs)
+	snapshot := fs.Bool("snapshot", false, "Take a new followers snapshot even if the last one is recent")
+	fs.Parse(args)
+
+	window, err := windowOpts.window(metrics.BucketDay)
+	if err != nil {
+		return err
+	}
+
+	_, client, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+	store, err := metrics.LoadFollowerStore()
+	if err != nil {
+		return err
+	}
+	if err := metrics.SnapshotFollowers(client, store, *snapshot); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not take a followers snapshot (%v); showing the last one.\n", err)
+	} else if err := store.Save(); err != nil {
+		return err
+	}
+
+	output.PrintFollowerReport(store, store.Report(window))
+	return nil
+}
+
+func runMetricsHeatmap(args []string) error {
+	fs := flag.NewFlagSet("metrics heatmap", flag.ExitOnError)
+	windowOpts := addWindowFlags(fs)
+	tz := fs.String("tz", "", "IANA timezone for hours and weekdays (default: local)")
+	source := fs.String("source", "both", "engagement, posts, or both")
+	format := fs.String("format", "matrix", "matrix or csv")
+	fs.Parse(args)
+
+	if *source != "engagement" && *source != "posts" && *source != "both" {
+		return fmt.Errorf("source must be one of: engagement, posts, both")
+	}
+	if *format != "matrix" && *format != "csv" {
+		return fmt.Errorf("format must be matrix or csv")
+	}
+	loc, err := loadLocation(*tz)
+	if err != nil {
+		return err
+	}
+	window, err := windowOpts.window(metrics.BucketDay)
+	if err != nil {
+		return err
+	}
+
+	_, client, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+
+	var heatmaps []output.NamedHeatmap
+	if *source != "posts" {
+		history, err := metrics.SyncHistory(client, window, nil)
+		if err != nil {
+			return err
+		}
+		heatmaps = append(heatmaps, output.NamedHeatmap{Name: "engagement", Heatmap: metrics.EngagementHeatmap(history, window, loc)})
+	}
+	if *source != "engagement" {
+		posts, err := metrics.FetchPosts(client, window)
+		if err != nil {
+			return err
+		}
+		heatmaps = append(heatmaps, output.NamedHeatmap{Name: "posts", Heatmap: metrics.PostingHeatmap(posts, loc)})
+	}
+
+	if *format == "csv" {
+		return output.WriteHeatmapsCSV(os.Stdout, heatmaps)
+	}
+	output.PrintHeatmaps(heatmaps, loc.String())
+	return nil
+}
+
+// exportMetrics writes the locally collected data for window to path, in
+// the format given by its extension. Nothing is fetched from the server.
+func exportMetrics(path string, window metrics.Window, selected []metrics.Series) error {
+	format := strings.ToLower(filepath.Ext(path))
+	switch format {
+	case ".html", ".htm", ".csv", ".json":
+	default:
+		return fmt.Errorf("unsupported export format %q (use .html, .csv or .json)", format)
+	}
+
+	report, err := metrics.LoadReport(window, time.Now())
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create export: %w", err)
+	}
+	switch format {
+	case ".csv":
+		err = output.WriteMetricsCSV(file, report.Series, report.Anomalies)
+	case ".json":
+		err = output.WriteMetricsJSON(file, report.Series, report.Anomalies)
+	default:
+		err = output.WriteMetricsHTML(file, report, selected)
+	}
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("write export: %w", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s (%s).\n", path, window.Describe(time.Now()))
+	return nil
+}
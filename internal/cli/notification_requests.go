@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+)
+
+func notificationPolicy(caps *mastodon.Capabilities) bool { return caps.NotificationPolicy }
+
+func runNotificationPolicy(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: mastodon notifications policy show|set [--for-not-following v] ...")
+	}
+
+	switch args[0] {
+	case "show":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: mastodon notifications policy show")
+		}
+		_, client, err := clientSupporting(notificationPolicy, "notification policies")
+		if err != nil {
+			return err
+		}
+		policy, err := client.NotificationPolicy()
+		if err != nil {
+			return err
+		}
+		output.PrintNotificationPolicy(*policy)
+		return nil
+	case "set":
+		return runNotificationPolicySet(args[1:])
+	default:
+		return fmt.Errorf("unknown policy command: %s", args[0])
+	}
+}
+
+func runNotificationPolicySet(args []string) error {
+	fs := flag.NewFlagSet("notifications policy set", flag.ExitOnError)
+	var update mastodon.NotificationPolicyUpdate
+	fs.StringVar(&update.ForNotFollowing, "for-not-following", "", "People you don't follow: accept, filter or drop")
+	fs.StringVar(&update.ForNotFollowers, "for-not-followers", "", "People who don't follow you: accept, filter or drop")
+	fs.StringVar(&update.ForNewAccounts, "for-new-accounts", "", "Accounts younger than 30 days: accept, filter or drop")
+	fs.StringVar(&update.ForPrivateMentions, "for-private-mentions", "", "Unsolicited private mentions: accept, filter or drop")
+	fs.StringVar(&update.ForLimitedAccounts, "for-limited-accounts", "", "Accounts limited by moderators: accept, filter or drop")
+	fs.Parse(args)
+
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: mastodon notifications policy set [--for-not-following v] ...")
+	}
+	values := []string{update.ForNotFollowing, update.ForNotFollowers, update.ForNewAccounts, update.ForPrivateMentions, update.ForLimitedAccounts}
+	changed := false
+	for _, value := range values {
+		switch value {
+		case "":
+			continue
+		case mastodon.PolicyAccept, mastodon.PolicyFilter, mastodon.PolicyDrop:
+			changed = true
+		default:
+			return fmt.Errorf("policy values must be one of: accept, filter, drop")
+		}
+	}
+	if !changed {
+		return fmt.Errorf("nothing to change; pass at least one --for-* flag")
+	}
+
+	_, client, err := clientSupporting(notificationPolicy, "notification policies")
+	if err != nil {
+		return err
+	}
+	policy, err := client.UpdateNotificationPolicy(update)
+	if err != nil {
+		return err
+	}
+	output.PrintNotificationPolicy(*policy)
+	return nil
+}
+
+func runNotificationRequests(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: mastodon notifications requests list|accept|dismiss")
+	}
+
+	switch args[0] {
+	case "list":
+		return runNotificationRequestsList(args[1:])
+	case "accept", "dismiss":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: mastodon notifications requests %s <id>...", args[0])
+		}
+		_, client, err := clientSupporting(notificationPolicy, "notification policies")
+		if err != nil {
+			return err
+		}
+		for _, id := range args[1:] {
+			if args[0] == "accept" {
+				err = client.AcceptNotificationRequest(id)
+			} else {
+				err = client.DismissNotificationRequest(id)
+			}
+			if err != nil {
+				return err
+			}
+			if args[0] == "accept" {
+				fmt.Printf("Accepted request %s\n", id)
+			} else {
+				fmt.Printf("Dismissed request %s\n", id)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown requests command: %s", args[0])
+	}
+}
+
+func runNotificationRequestsList(args []string) error {
+	fs := flag.NewFlagSet("notifications requests list", flag.ExitOnError)
+	limit := fs.Int("limit", 40, "Number of requests to fetch (1-80)")
+	fs.Parse(args)
+
+	if *limit <= 0 || *limit > 80 {
+		return fmt.Errorf("limit must be between 1 and 80")
+	}
+	_, client, err := clientSupporting(notificationPolicy, "notification policies")
+	if err != nil {
+		return err
+	}
+	requests, _, err := client.NotificationRequestsPage(*limit, "")
+	if err != nil {
+		return err
+	}
+	output.PrintNotificationRequests(requests)
+	return nil
+}
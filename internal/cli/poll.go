@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+)
+
+func runPoll(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: mastodon poll show|vote <status-id> [choice...]")
+	}
+
+	switch args[0] {
+	case "show":
+		return runPollShow(args[1:])
+	case "vote":
+		return runPollVote(args[1:])
+	default:
+		return fmt.Errorf("unknown poll command: %s", args[0])
+	}
+}
+
+func runPollShow(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: mastodon poll show <status-id>")
+	}
+
+	_, client, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+	poll, err := statusPoll(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	output.PrintPoll(*poll)
+	return nil
+}
+
+func runPollVote(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: mastodon poll vote <status-id> <choice>...")
+	}
+
+	_, client, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+	poll, err := statusPoll(client, args[0])
+	if err != nil {
+		return err
+	}
+
+	choices, err := parsePollChoices(args[1:], *poll)
+	if err != nil {
+		return err
+	}
+
+	updated, err := client.VotePoll(poll.ID, choices)
+	if err != nil {
+		return err
+	}
+
+	output.PrintPoll(*updated)
+	return nil
+}
+
+// statusPoll fetches the poll attached to a status or the status it boosts.
+func statusPoll(client *mastodon.Client, statusID string) (*mastodon.Poll, error) {
+	status, err := client.GetStatus(statusID)
+	if err != nil {
+		return nil, err
+	}
+	if status.Reblog != nil {
+		status = status.Reblog
+	}
+	if status.Poll == nil {
+		return nil, fmt.Errorf("status %s has no poll", statusID)
+	}
+	return status.Poll, nil
+}
+
+// parsePollChoices converts one-based choices to the zero-based indexes the
+// API expects.
+func parsePollChoices(args []string, poll mastodon.Poll) ([]int, error) {
+	if poll.Expired {
+		return nil, fmt.Errorf("poll is closed")
+	}
+	if poll.Voted {
+		return nil, fmt.Errorf("you already voted in this poll")
+	}
+	if len(args) > 1 && !poll.Multiple {
+		return nil, fmt.Errorf("poll allows a single choice")
+	}
+
+	seen := make(map[int]bool, len(args))
+	choices := make([]int, 0, len(args))
+	for _, arg := range args {
+		choice, err := strconv.Atoi(arg)
+		if err != nil || choice < 1 || choice > len(poll.Options) {
+			return nil, fmt.Errorf("choice must be between 1 and %d", len(poll.Options))
+		}
+		if seen[choice] {
+			continue
+		}
+		seen[choice] = true
+		choices = append(choices, choice-1)
+	}
+	return choices, nil
+}
@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"mastodoncli/internal/config"
+	"mastodoncli/internal/drafts"
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+)
+
+func runPost(args []string) error {
+	fs := flag.NewFlagSet("post", flag.ExitOnError)
+	visibility := fs.String("visibility", "", "Visibility: public, unlisted, private, direct (default: account setting)")
+	spoiler := fs.String("cw", "", "Content warning text")
+	replyTo := fs.String("reply-to", "", "ID of the status to reply to")
+	language := fs.String("language", "", "ISO 639 language code")
+	var pollOptions stringList
+	fs.Var(&pollOptions, "poll-option", "Poll option (repeat for each option)")
+	pollExpires := fs.Duration("poll-expires", 24*time.Hour, "Poll duration (e.g. 30m, 24h)")
+	pollMultiple := fs.Bool("poll-multiple", false, "Allow choosing multiple poll options")
+	pollHideTotals := fs.Bool("poll-hide-totals", false, "Hide vote counts until the poll ends")
+	at := fs.String("at", "", "Schedule for this time instead of posting now (YYYY-MM-DD HH:MM)")
+	tz := fs.String("tz", "", "IANA timezone for --at (default: local)")
+	asDraft := fs.Bool("draft", false, "Save as a draft instead of posting")
+	threadPath := fs.String("thread", "", "Post this file (or - for stdin) as a thread of replies")
+	counters := fs.Bool("counters", false, "Append 1/n counters to thread parts")
+	followUp := fs.String("followup-visibility", "", "Visibility of thread parts after the first (default: thread_followup_visibility from config)")
+	maxChars := fs.Int("max-chars", 0, "Characters per thread part (default: instance limit)")
+	yes := fs.Bool("yes", false, "Post a thread without asking for confirmation")
+	var media []drafts.Media
+	fs.Var(mediaFlag{media: &media, field: "media"}, "media", "Attach a file (repeat for each file)")
+	fs.Var(mediaFlag{media: &media, field: "alt"}, "alt", "Alt text for the preceding --media")
+	fs.Var(mediaFlag{media: &media, field: "focus"}, "focus", "Focus point x,y (-1 to 1) for the preceding --media")
+	allowNoAlt := fs.Bool("allow-no-alt", false, "Allow images without alt text")
+	fs.Parse(args)
+
+	if *threadPath != "" {
+		if fs.NArg() > 0 {
+			return fmt.Errorf("--thread reads the text from the file; do not pass text arguments")
+		}
+		if *at != "" || *asDraft || len(pollOptions) > 0 || len(media) > 0 {
+			return fmt.Errorf("--thread cannot be combined with --at, --draft, --media or a poll")
+		}
+		if *maxChars < 0 {
+			return fmt.Errorf("--max-chars must be positive")
+		}
+		switch *visibility {
+		case "", "public", "unlisted", "private", "direct":
+		default:
+			return fmt.Errorf("visibility must be one of: public, unlisted, private, direct")
+		}
+		return postThread(mastodon.StatusParams{
+			InReplyToID: *replyTo,
+			SpoilerText: *spoiler,
+			Visibility:  *visibility,
+			Language:    *language,
+		}, threadOptions{
+			path:               *threadPath,
+			counters:           *counters,
+			followUpVisibility: *followUp,
+			maxChars:           *maxChars,
+			yes:                *yes,
+		})
+	}
+
+	text, err := statusText(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	params := mastodon.StatusParams{
+		Status:      text,
+		InReplyToID: *replyTo,
+		SpoilerText: *spoiler,
+		Visibility:  *visibility,
+		Language:    *language,
+	}
+	if len(pollOptions) > 0 {
+		params.Poll = &mastodon.PollParams{
+			Options:    pollOptions,
+			ExpiresIn:  int(pollExpires.Seconds()),
+			Multiple:   *pollMultiple,
+			HideTotals: *pollHideTotals,
+		}
+	}
+	if err := validateStatusParams(params, len(media)); err != nil {
+		return err
+	}
+	loc, err := loadLocation(*tz)
+	if err != nil {
+		return err
+	}
+	if *at != "" {
+		scheduledAt, err := mastodon.ParseScheduleTime(*at, loc, time.Now())
+		if err != nil {
+			return fmt.Errorf("invalid --at: %w", err)
+		}
+		params.ScheduledAt = scheduledAt.UTC().Format(time.RFC3339)
+	} else if *tz != "" {
+		return fmt.Errorf("--tz requires --at")
+	}
+
+	var cfg *config.Config
+	var client *mastodon.Client
+	if *asDraft {
+		cfg, client, err = authenticatedClient()
+	} else {
+		cfg, client, err = postingClient(params)
+	}
+	if err != nil {
+		return err
+	}
+	if *asDraft {
+		id, err := saveDraft(cfg, client, params, media)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Saved as draft %s\n", id)
+		return nil
+	}
+
+	if err := checkMedia(cfg, client, media, !*allowNoAlt); err != nil {
+		return err
+	}
+
+	// Keep the text if the request is interrupted; a failed request is
+	// saved below.
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	go func() {
+		if _, ok := <-interrupted; !ok {
+			return
+		}
+		if id, err := saveDraft(cfg, client, params, media); err == nil {
+			fmt.Fprintf(os.Stderr, "\nInterrupted; saved as draft %s\n", id)
+		}
+		os.Exit(130)
+	}()
+	message, err := uploadAndSend(client, params, media, loc)
+	signal.Stop(interrupted)
+	close(interrupted)
+	if err != nil {
+		if id, saveErr := saveDraft(cfg, client, params, media); saveErr == nil {
+			return fmt.Errorf("%w (saved as draft %s)", err, id)
+		}
+		return err
+	}
+
+	fmt.Println(message)
+	return nil
+}
+
+// postingClient is authenticatedClient for sending params, refusing
+// scheduled ones when the instance is known to lack scheduled statuses.
+func postingClient(params mastodon.StatusParams) (*config.Config, *mastodon.Client, error) {
+	if params.ScheduledAt != "" {
+		return clientSupporting(scheduledStatuses, "scheduled statuses")
+	}
+	return authenticatedClient()
+}
+
+// checkMedia applies the instance media limits before anything is sent.
+func checkMedia(cfg *config.Config, client *mastodon.Client, media []drafts.Media, requireAlt bool) error {
+	if len(media) > 0 {
+		useCachedCapabilities(cfg, client)
+	}
+	err := client.CheckMediaFiles(drafts.Draft{Media: media}.Uploads(), requireAlt)
+	if errors.Is(err, mastodon.ErrMissingAlt) {
+		return fmt.Errorf("%w; describe it with --alt after its --media, or pass --allow-no-alt", err)
+	}
+	return err
+}
+
+// uploadAndSend uploads media, then posts or schedules the status.
+func uploadAndSend(client *mastodon.Client, params mastodon.StatusParams, media []drafts.Media, loc *time.Location) (string, error) {
+	if len(media) > 0 {
+		ids, err := client.UploadMediaFiles(drafts.Draft{Media: media}.Uploads())
+		if err != nil {
+			return "", err
+		}
+		params.MediaIDs = ids
+	}
+	return sendStatus(client, params, loc)
+}
+
+// sendStatus posts params, or schedules them when ScheduledAt is set, and
+// describes the result with times in loc.
+func sendStatus(client *mastodon.Client, params mastodon.StatusParams, loc *time.Location) (string, error) {
+	if params.ScheduledAt != "" {
+		scheduled, err := client.ScheduleStatus(params)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Scheduled %s for %s", scheduled.ID, scheduled.Time().In(loc).Format(output.ScheduleTimeLayout+" MST")), nil
+	}
+
+	status, err := client.PostStatus(params)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Posted %s", statusLink(status)), nil
+}
+
+// statusText joins the positional arguments, reading stdin for "-" or when
+// input is piped.
+func statusText(args []string) (string, error) {
+	if (len(args) == 1 && args[0] == "-") || (len(args) == 0 && !stdinIsTerminal()) {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("read status text: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return strings.TrimSpace(strings.Join(args, " ")), nil
+}
+
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return true
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// validateStatusParams checks params before media is uploaded; pending is
+// the number of files still to be attached.
+func validateStatusParams(params mastodon.StatusParams, pending int) error {
+	media := len(params.MediaIDs) + pending
+	if params.Status == "" && media == 0 {
+		return fmt.Errorf("status text is required")
+	}
+	switch params.Visibility {
+	case "", "public", "unlisted", "private", "direct":
+	default:
+		return fmt.Errorf("visibility must be one of: public, unlisted, private, direct")
+	}
+	if params.Poll != nil {
+		if len(params.Poll.Options) < 2 {
+			return fmt.Errorf("a poll needs at least 2 options")
+		}
+		for _, option := range params.Poll.Options {
+			if strings.TrimSpace(option) == "" {
+				return fmt.Errorf("poll options cannot be empty")
+			}
+		}
+		if params.Poll.ExpiresIn < int(mastodon.MinPollDuration.Seconds()) {
+			return fmt.Errorf("poll duration must be at least 5m")
+		}
+		if media > 0 {
+			return fmt.Errorf("a status cannot have both a poll and media")
+		}
+	}
+	return nil
+}
+
+func statusLink(status *mastodon.Status) string {
+	if status.URL != "" {
+		return status.URL
+	}
+	return status.ID
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+)
+
+func runScheduled(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: mastodon scheduled list|show|reschedule|cancel")
+	}
+	switch args[0] {
+	case "list":
+		return runScheduledList(args[1:])
+	case "show":
+		return runScheduledShow(args[1:])
+	case "reschedule":
+		return runScheduledReschedule(args[1:])
+	case "cancel":
+		return runScheduledCancel(args[1:])
+	default:
+		return fmt.Errorf("unknown scheduled command: %s", args[0])
+	}
+}
+
+func scheduledStatuses(caps *mastodon.Capabilities) bool { return caps.ScheduledStatuses }
+
+func runScheduledList(args []string) error {
+	fs := flag.NewFlagSet("scheduled list", flag.ExitOnError)
+	tz := fs.String("tz", "", "IANA timezone for times (default: local)")
+	fs.Parse(args)
+
+	loc, err := loadLocation(*tz)
+	if err != nil {
+		return err
+	}
+	_, client, err := clientSupporting(scheduledStatuses, "scheduled statuses")
+	if err != nil {
+		return err
+	}
+	statuses, err := client.ScheduledStatuses()
+	if err != nil {
+		return err
+	}
+	output.PrintScheduledStatuses(statuses, loc)
+	return nil
+}
+
+func runScheduledShow(args []string) error {
+	fs := flag.NewFlagSet("scheduled show", flag.ExitOnError)
+	tz := fs.String("tz", "", "IANA timezone for times (default: local)")
+	fs.Parse(args)
+
+	id, err := scheduledID(fs.Args(), "show <id>")
+	if err != nil {
+		return err
+	}
+	loc, err := loadLocation(*tz)
+	if err != nil {
+		return err
+	}
+	_, client, err := clientSupporting(scheduledStatuses, "scheduled statuses")
+	if err != nil {
+		return err
+	}
+	status, err := client.GetScheduledStatus(id)
+	if err != nil {
+		return err
+	}
+	output.PrintScheduledStatus(*status, loc)
+	return nil
+}
+
+func runScheduledReschedule(args []string) error {
+	fs := flag.NewFlagSet("scheduled reschedule", flag.ExitOnError)
+	tz := fs.String("tz", "", "IANA timezone for the new time (default: local)")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: mastodon scheduled reschedule [--tz <zone>] <id> \"YYYY-MM-DD HH:MM\"")
+	}
+	loc, err := loadLocation(*tz)
+	if err != nil {
+		return err
+	}
+	at, err := mastodon.ParseScheduleTime(fs.Arg(1), loc, time.Now())
+	if err != nil {
+		return err
+	}
+	_, client, err := clientSupporting(scheduledStatuses, "scheduled statuses")
+	if err != nil {
+		return err
+	}
+	status, err := client.RescheduleStatus(fs.Arg(0), at)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Rescheduled %s for %s\n", status.ID, status.Time().In(loc).Format(output.ScheduleTimeLayout+" MST"))
+	return nil
+}
+
+func runScheduledCancel(args []string) error {
+	fs := flag.NewFlagSet("scheduled cancel", flag.ExitOnError)
+	fs.Parse(args)
+
+	id, err := scheduledID(fs.Args(), "cancel <id>")
+	if err != nil {
+		return err
+	}
+	_, client, err := clientSupporting(scheduledStatuses, "scheduled statuses")
+	if err != nil {
+		return err
+	}
+	if err := client.CancelScheduledStatus(id); err != nil {
+		return err
+	}
+	fmt.Printf("Cancelled %s\n", id)
+	return nil
+}
+
+func scheduledID(args []string, usage string) (string, error) {
+	if len(args) != 1 || args[0] == "" {
+		return "", fmt.Errorf("usage: mastodon scheduled %s", usage)
+	}
+	return args[0], nil
+}
+
+// loadLocation resolves a --tz flag, defaulting to the local zone.
+func loadLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --tz: %w", err)
+	}
+	return loc, nil
+}
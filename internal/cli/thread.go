@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/thread"
+)
+
+type threadOptions struct {
+	path               string
+	counters           bool
+	followUpVisibility string
+	maxChars           int
+	yes                bool
+}
+
+// postThread splits the file into parts, previews them and posts each as a
+// reply to the one before. Posted parts are recorded, so running the same
+// command after a failure continues with the next part.
+func postThread(params mastodon.StatusParams, opts threadOptions) error {
+	if opts.path == "-" && !opts.yes {
+		return fmt.Errorf("reading a thread from stdin requires --yes")
+	}
+	text, err := readThreadFile(opts.path)
+	if err != nil {
+		return err
+	}
+	cfg, client, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+	followUp := opts.followUpVisibility
+	if followUp == "" {
+		followUp = cfg.ThreadVisibility
+	}
+	switch followUp {
+	case "", "public", "unlisted", "private", "direct":
+	default:
+		return fmt.Errorf("follow-up visibility must be one of: public, unlisted, private, direct")
+	}
+
+	limit, urlLength := opts.maxChars, mastodon.DefaultURLLength
+	useCachedCapabilities(cfg, client)
+	if instance, err := client.InstanceConfiguration(); err == nil {
+		urlLength = instance.Statuses.CharactersReservedPerURL
+		if limit == 0 {
+			limit = instance.Statuses.MaxCharacters
+		}
+	} else if limit == 0 {
+		fmt.Fprintf(os.Stderr, "Could not read the instance limits (%v); assuming %d characters.\n", err, mastodon.DefaultMaxCharacters)
+		limit = mastodon.DefaultMaxCharacters
+	}
+
+	parts, err := thread.Split(text, thread.Options{
+		MaxCharacters: limit - thread.Length(params.SpoilerText, urlLength),
+		URLLength:     urlLength,
+		Counters:      opts.counters,
+	})
+	if err != nil {
+		return err
+	}
+
+	key := thread.Key(parts, params.InReplyToID, params.Visibility, followUp, params.SpoilerText, params.Language)
+	store, err := thread.LoadProgress()
+	if err != nil {
+		return err
+	}
+	progress := store.Threads[key]
+	if progress == nil {
+		progress = &thread.Progress{Parts: parts}
+	}
+
+	printThreadPreview(parts, len(progress.PostedIDs), limit, urlLength, params.SpoilerText)
+	remaining := len(parts) - len(progress.PostedIDs)
+	if !opts.yes {
+		question := fmt.Sprintf("Post %d parts? [y/N] ", remaining)
+		if len(progress.PostedIDs) > 0 {
+			question = fmt.Sprintf("Resume after part %d and post the remaining %d? [y/N] ", len(progress.PostedIDs), remaining)
+		}
+		answer, err := prompt(question)
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			fmt.Println("Nothing posted.")
+			return nil
+		}
+	}
+
+	for i := len(progress.PostedIDs); i < len(parts); i++ {
+		part := params
+		part.Status = parts[i]
+		if i > 0 {
+			part.InReplyToID = progress.LastID()
+			part.Visibility = thread.FollowUpVisibility(progress.Visibility, followUp)
+		}
+		status, err := client.PostStatus(part)
+		if err != nil {
+			if i > 0 {
+				return fmt.Errorf("part %d of %d: %w; run the same command again to resume", i+1, len(parts), err)
+			}
+			return fmt.Errorf("part %d of %d: %w", i+1, len(parts), err)
+		}
+		fmt.Printf("Posted %d/%d %s\n", i+1, len(parts), statusLink(status))
+
+		if i == 0 {
+			progress.Visibility = status.Visibility
+		}
+		progress.PostedIDs = append(progress.PostedIDs, status.ID)
+		progress.UpdatedAt = time.Now()
+		store.Threads[key] = progress
+		if err := store.Save(); err != nil {
+			return err
+		}
+	}
+
+	delete(store.Threads, key)
+	return store.Save()
+}
+
+func readThreadFile(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("read thread: %w", err)
+	}
+	return string(data), nil
+}
+
+func printThreadPreview(parts []string, posted, limit, urlLength int, spoiler string) {
+	for i, part := range parts {
+		length := thread.Length(part, urlLength) + thread.Length(spoiler, urlLength)
+		state := ""
+		if i < posted {
+			state = " · posted"
+		}
+		fmt.Printf("── Part %d/%d · %d/%d characters%s ──\n", i+1, len(parts), length, limit, state)
+		if spoiler != "" {
+			fmt.Printf("CW: %s\n", spoiler)
+		}
+		fmt.Println(part)
+		fmt.Println()
+	}
+}
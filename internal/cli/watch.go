@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"mastodoncli/internal/config"
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+	"mastodoncli/internal/rules"
+	"mastodoncli/internal/watch"
+)
+
+func runWatch(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: mastodon watch notifications|timeline|hashtag|list [flags]")
+	}
+	switch args[0] {
+	case "notifications":
+		return runWatchNotifications(args[1:])
+	case "timeline", "hashtag", "list":
+		return runWatchStatuses(args[0], args[1:])
+	default:
+		return fmt.Errorf("unknown watch source: %s", args[0])
+	}
+}
+
+// watchFlags are shared by every watch source.
+type watchFlags struct {
+	keywords stringList
+	accounts stringList
+	webhooks stringList
+	quiet    string
+	poll     bool
+	interval time.Duration
+	command  string
+	retries  int
+}
+
+func (w *watchFlags) register(fs *flag.FlagSet) {
+	fs.Var(&w.keywords, "keyword", "Only items containing this keyword (repeatable)")
+	fs.Var(&w.accounts, "account", "Only items from this account, e.g. user@example.org (repeatable)")
+	fs.Var(&w.webhooks, "webhook", "POST each item as JSON to this URL (repeatable)")
+	fs.StringVar(&w.quiet, "quiet", "", "Deliver nothing between these local times, e.g. 22:00-07:00")
+	fs.BoolVar(&w.poll, "poll", false, "Poll instead of using the streaming API")
+	fs.DurationVar(&w.interval, "interval", time.Minute, "Time between polls and stream reconnects")
+	fs.StringVar(&w.command, "exec", "", "Run this shell command for each item, with it as JSON on stdin")
+	fs.IntVar(&w.retries, "retries", 3, "Times a failed webhook delivery is repeated")
+}
+
+func (w *watchFlags) options(kinds []string) (watch.Options, error) {
+	if w.interval < 10*time.Second {
+		return watch.Options{}, fmt.Errorf("interval must be at least 10s")
+	}
+	if w.retries < 0 {
+		return watch.Options{}, fmt.Errorf("retries cannot be negative")
+	}
+	for _, hook := range w.webhooks {
+		if target, err := url.Parse(hook); err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return watch.Options{}, fmt.Errorf("webhook must be an http or https URL: %s", hook)
+		}
+	}
+	opts := watch.Options{
+		Poll:     w.poll,
+		Interval: w.interval,
+		Match:    watch.Match{Keywords: w.keywords, Accounts: w.accounts, Kinds: kinds},
+		Targets:  w.targets(),
+		Logf:     watchLogf,
+	}
+	if w.quiet != "" {
+		quiet, err := watch.ParseQuietHours(w.quiet)
+		if err != nil {
+			return watch.Options{}, err
+		}
+		opts.Quiet = quiet
+	}
+	return opts, nil
+}
+
+// hasTargets reports whether --exec or --webhook was given.
+func (w *watchFlags) hasTargets() bool {
+	return w.command != "" || len(w.webhooks) > 0
+}
+
+// targets are the command and every webhook. The watch state tracks them
+// by the command and the URL.
+func (w *watchFlags) targets() []watch.Target {
+	var targets []watch.Target
+	if command := w.command; command != "" {
+		targets = append(targets, watch.Target{
+			Name: "exec " + command,
+			Send: func(_ context.Context, _ string, payload any) error {
+				return watch.Exec(command, payload)
+			},
+		})
+	}
+	for _, hook := range w.webhooks {
+		webhook := watch.Webhook{URL: hook, Retries: w.retries}
+		targets = append(targets, watch.Target{Name: "webhook " + hook, Send: webhook.Post})
+	}
+	return targets
+}
+
+func runWatchNotifications(args []string) error {
+	fs := flag.NewFlagSet("watch notifications", flag.ExitOnError)
+	var common watchFlags
+	common.register(fs)
+	types := fs.String("types", "", "Only these notification types (comma-separated)")
+	exclude := fs.String("exclude", "", "Leave out these notification types (comma-separated)")
+	mentions := fs.Bool("mentions", false, "Only mentions (same as --types mention)")
+	desktop := fs.Bool("desktop", false, "Show desktop notifications even with --exec or --webhook")
+	fs.Parse(args)
+
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: mastodon watch notifications [flags]")
+	}
+	var filter mastodon.NotificationFilter
+	var err error
+	if filter.Types, err = parseNotificationTypes(*types); err != nil {
+		return err
+	}
+	if filter.ExcludeTypes, err = parseNotificationTypes(*exclude); err != nil {
+		return err
+	}
+	if *mentions {
+		if len(filter.Types) > 0 {
+			return fmt.Errorf("--mentions cannot be combined with --types")
+		}
+		filter.Types = []string{"mention"}
+	}
+	opts, err := common.options(nil)
+	if err != nil {
+		return err
+	}
+
+	cfg, client, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+	if opts.Rules, err = rules.Load(); err != nil {
+		return err
+	}
+	opts.Rules.SetInstance(cfg.Instance)
+	useCachedCapabilities(cfg, client)
+	if opts.Source, err = watchSource(cfg, client, "notifications"); err != nil {
+		return err
+	}
+
+	if *desktop || !common.hasTargets() {
+		// A host without a desktop session still reaches the other
+		// targets, and the notification is not retried for it.
+		desktop := watch.Target{Name: "desktop", Send: func(_ context.Context, _ string, payload any) error {
+			group := payload.(mastodon.GroupedNotification)
+			if err := watch.Desktop(output.NotificationSummary(group), output.NotificationBody(group, 200)); err != nil {
+				opts.Logf("Desktop notification for %s failed: %v", group.MostRecent, err)
+			}
+			return nil
+		}}
+		opts.Targets = append([]watch.Target{desktop}, opts.Targets...)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return watch.Notifications(ctx, client, watch.NotificationOptions{
+		Options: opts,
+		Filter:  filter,
+	})
+}
+
+func runWatchStatuses(source string, args []string) error {
+	fs := flag.NewFlagSet("watch "+source, flag.ExitOnError)
+	var common watchFlags
+	common.register(fs)
+	types := fs.String("types", "", "Only these kinds of status: post, reply, boost (comma-separated)")
+	fs.Parse(args)
+
+	timeline := mastodon.Timeline{Kind: source}
+	switch {
+	case source == "timeline" && fs.NArg() <= 1:
+		timeline.Kind = "home"
+		if fs.NArg() == 1 {
+			timeline.Kind = fs.Arg(0)
+		}
+		if timeline.Kind != "home" && timeline.Kind != "local" && timeline.Kind != "federated" {
+			return fmt.Errorf("timeline must be one of: home, local, federated")
+		}
+	case source != "timeline" && fs.NArg() == 1:
+		timeline.Name = strings.TrimSpace(strings.TrimPrefix(fs.Arg(0), "#"))
+		if timeline.Name == "" && source == "hashtag" {
+			return fmt.Errorf("tag cannot be empty")
+		}
+		if timeline.Name == "" {
+			return fmt.Errorf("list ID cannot be empty")
+		}
+	case source == "hashtag":
+		return fmt.Errorf("usage: mastodon watch hashtag [flags] <tag>")
+	case source == "list":
+		return fmt.Errorf("usage: mastodon watch list [flags] <list-id>")
+	default:
+		return fmt.Errorf("usage: mastodon watch timeline [flags] [home|local|federated]")
+	}
+	var kinds []string
+	for _, kind := range strings.Split(*types, ",") {
+		if kind = strings.TrimSpace(kind); kind == "" {
+			continue
+		}
+		if !slices.Contains(watch.StatusKinds, kind) {
+			return fmt.Errorf("unknown status type %q (known: %s)", kind, strings.Join(watch.StatusKinds, ", "))
+		}
+		kinds = append(kinds, kind)
+	}
+	if !common.hasTargets() {
+		return fmt.Errorf("watch %s needs --exec or --webhook", source)
+	}
+	opts, err := common.options(kinds)
+	if err != nil {
+		return err
+	}
+
+	cfg, client, err := authenticatedClient()
+	if err != nil {
+		return err
+	}
+	if opts.Rules, err = rules.Load(); err != nil {
+		return err
+	}
+	opts.Rules.SetInstance(cfg.Instance)
+	useCachedCapabilities(cfg, client)
+	if opts.Source, err = watchSource(cfg, client, timeline.String()); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return watch.Statuses(ctx, client, watch.StatusOptions{
+		Options:  opts,
+		Timeline: timeline,
+	})
+}
+
+// watchSource names the cursor of what in the watch state after the
+// instance and the account ID saved at login, so it stays the same however
+// the watcher starts. Logins from before the ID was saved look it up.
+func watchSource(cfg *config.Config, client *mastodon.Client, what string) (string, error) {
+	accountID := cfg.AccountID
+	if accountID == "" {
+		account, err := client.VerifyCredentials()
+		if err != nil {
+			return "", fmt.Errorf("look up the watched account: %w (run `mastodon login` again to save it)", err)
+		}
+		accountID = account.ID
+	}
+	return strings.Join([]string{cfg.Instance, accountID, what}, " "), nil
+}
+
+func watchLogf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "%s "+format+"\n", append([]any{time.Now().Format("15:04:05")}, args...)...)
+}
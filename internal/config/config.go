@@ -1,18 +1,24 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 )
 
 type Config struct {
-	Instance     string `json:"instance"`
-	ClientID     string `json:"client_id"`
-	ClientSecret string `json:"client_secret"`
-	AccessToken  string `json:"access_token"`
-	RedirectURI  string `json:"redirect_uri"`
+	Instance         string   `json:"instance"`
+	ClientID         string   `json:"client_id"`
+	ClientSecret     string   `json:"client_secret"`
+	AccessToken      string   `json:"access_token"`
+	Account          string   `json:"account,omitempty"`
+	AccountID        string   `json:"account_id,omitempty"`
+	RedirectURI      string   `json:"redirect_uri"`
+	Scopes           string   `json:"scopes,omitempty"`
+	CWDefault        string   `json:"cw_default,omitempty"`
+	CWKeywords       []string `json:"cw_keywords,omitempty"`
+	MediaPreviews    string   `json:"media_previews,omitempty"`
+	ThreadVisibility string   `json:"thread_followup_visibility,omitempty"`
 }
 
 func Load() (*Config, error) {
@@ -21,19 +27,10 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return &Config{}, nil
-		}
-		return nil, fmt.Errorf("read config: %w", err)
-	}
-
 	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("parse config: %w", err)
+	if err := ReadJSON(path, "config", &cfg); err != nil {
+		return nil, err
 	}
-
 	return &cfg, nil
 }
 
@@ -42,22 +39,15 @@ func Save(cfg *Config) error {
 	if err != nil {
 		return err
 	}
+	return WriteJSON(path, "config", cfg)
+}
 
-	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
-		return fmt.Errorf("create config dir: %w", err)
-	}
-
-	data, err := json.MarshalIndent(cfg, "", "  ")
+func Dir() (string, error) {
+	path, err := Path()
 	if err != nil {
-		return fmt.Errorf("serialize config: %w", err)
+		return "", err
 	}
-	data = append(data, '\n')
-
-	if err := os.WriteFile(path, data, 0o600); err != nil {
-		return fmt.Errorf("write config: %w", err)
-	}
-
-	return nil
+	return filepath.Dir(path), nil
 }
 
 func Path() (string, error) {
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockWait = 5 * time.Second
+	// lockStale is how old a lock file gets before it is taken as left
+	// behind by a process that died holding it.
+	lockStale = 30 * time.Second
+)
+
+// ReadJSON decodes the file at path into v. A missing file leaves v as it
+// is. what names the contents in errors, e.g. "drafts".
+func ReadJSON(path, what string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read %s: %w", what, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parse %s: %w", what, err)
+	}
+	return nil
+}
+
+// WriteJSON stores v at path through a temporary file of its own and a
+// rename, so readers never see a partly written file. Callers that load a
+// file, change it and write it back hold Lock while doing so, or a TUI and
+// a CLI run writing at once lose one of the changes.
+func WriteJSON(path, what string, v any) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialize %s: %w", what, err)
+	}
+	data = append(data, '\n')
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("write %s: %w", what, err)
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write %s: %w", what, err)
+	}
+	return nil
+}
+
+// Lock takes the lock file next to path for a read-modify-write cycle and
+// returns the function that releases it. It waits up to lockWait for
+// another process to finish.
+func Lock(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create config dir: %w", err)
+	}
+	lock := path + ".lock"
+	deadline := time.Now().Add(lockWait)
+	for {
+		file, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("lock %s: %w", filepath.Base(path), err)
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another process; remove %s if none is running", filepath.Base(path), lock)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
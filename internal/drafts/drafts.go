@@ -0,0 +1,208 @@
+package drafts
+
+import (
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mastodoncli/internal/config"
+	"mastodoncli/internal/mastodon"
+)
+
+// Media is an attachment that has not been uploaded yet.
+type Media struct {
+	Path  string `json:"path"`
+	Alt   string `json:"alt,omitempty"`
+	Focus string `json:"focus,omitempty"`
+}
+
+// Draft is an unsent status. Account is "user@instance", so drafts written
+// under one login are kept apart from another's; it is empty when the
+// account was not known yet, and such drafts go with whoever is logged in.
+type Draft struct {
+	ID          string               `json:"id"`
+	Account     string               `json:"account"`
+	Text        string               `json:"text"`
+	Visibility  string               `json:"visibility,omitempty"`
+	SpoilerText string               `json:"spoiler_text,omitempty"`
+	Language    string               `json:"language,omitempty"`
+	InReplyToID string               `json:"in_reply_to_id,omitempty"`
+	Poll        *mastodon.PollParams `json:"poll,omitempty"`
+	Media       []Media              `json:"media,omitempty"`
+	ScheduledAt string               `json:"scheduled_at,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+// FromParams captures a status request as a draft.
+func FromParams(params mastodon.StatusParams, account string) Draft {
+	return Draft{
+		Account:     account,
+		Text:        params.Status,
+		Visibility:  params.Visibility,
+		SpoilerText: params.SpoilerText,
+		Language:    params.Language,
+		InReplyToID: params.InReplyToID,
+		Poll:        params.Poll,
+		ScheduledAt: params.ScheduledAt,
+	}
+}
+
+// Params returns the status request; media still has to be uploaded and
+// added as MediaIDs.
+func (d Draft) Params() mastodon.StatusParams {
+	return mastodon.StatusParams{
+		Status:      d.Text,
+		InReplyToID: d.InReplyToID,
+		SpoilerText: d.SpoilerText,
+		Visibility:  d.Visibility,
+		Language:    d.Language,
+		Poll:        d.Poll,
+		ScheduledAt: d.ScheduledAt,
+	}
+}
+
+// Uploads returns the attachments in the form the client uploads them.
+func (d Draft) Uploads() []mastodon.MediaUpload {
+	uploads := make([]mastodon.MediaUpload, 0, len(d.Media))
+	for _, media := range d.Media {
+		uploads = append(uploads, mastodon.MediaUpload{Path: media.Path, Description: media.Alt, Focus: media.Focus})
+	}
+	return uploads
+}
+
+// BelongsTo reports whether the draft can be used by account. Untagged
+// drafts belong to any account; an unknown account (empty) sees every
+// draft.
+func (d Draft) BelongsTo(account string) bool {
+	return account == "" || d.Account == "" || d.Account == account
+}
+
+// Empty reports whether there is nothing worth keeping.
+func (d Draft) Empty() bool {
+	return strings.TrimSpace(d.Text) == "" && strings.TrimSpace(d.SpoilerText) == "" && d.Poll == nil && len(d.Media) == 0
+}
+
+type Store struct {
+	NextID int     `json:"next_id"`
+	Drafts []Draft `json:"drafts"`
+}
+
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "drafts.json"), nil
+}
+
+func Load() (*Store, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{}
+	if err := config.ReadJSON(path, "drafts", store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *Store) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, "drafts", s)
+}
+
+// Update loads the store, applies fn and saves it, holding the lock so
+// concurrent updates are not lost.
+func Update(fn func(*Store) error) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	unlock, err := config.Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	store, err := Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(store); err != nil {
+		return err
+	}
+	return store.Save()
+}
+
+// Put adds draft, or replaces the one with the same ID, and returns its ID.
+func (s *Store) Put(draft Draft, now time.Time) string {
+	draft.UpdatedAt = now
+	for i := range s.Drafts {
+		if s.Drafts[i].ID == draft.ID {
+			draft.CreatedAt = s.Drafts[i].CreatedAt
+			s.Drafts[i] = draft
+			return draft.ID
+		}
+	}
+	s.NextID++
+	draft.ID = strconv.Itoa(s.NextID)
+	draft.CreatedAt = now
+	s.Drafts = append(s.Drafts, draft)
+	return draft.ID
+}
+
+func (s *Store) Get(id string) (Draft, bool) {
+	for _, draft := range s.Drafts {
+		if draft.ID == id {
+			return draft, true
+		}
+	}
+	return Draft{}, false
+}
+
+func (s *Store) Delete(id string) bool {
+	for i, draft := range s.Drafts {
+		if draft.ID == id {
+			s.Drafts = append(s.Drafts[:i], s.Drafts[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the drafts that belong to account, or all of them when
+// account is empty, most recently updated first.
+func (s *Store) List(account string) []Draft {
+	var drafts []Draft
+	for _, draft := range s.Drafts {
+		if draft.BelongsTo(account) {
+			drafts = append(drafts, draft)
+		}
+	}
+	sort.SliceStable(drafts, func(i, j int) bool {
+		return drafts[i].UpdatedAt.After(drafts[j].UpdatedAt)
+	})
+	return drafts
+}
+
+// AccountTag formats the owner of a draft, e.g. "alice@mastodon.social",
+// or returns "" when username is unknown.
+func AccountTag(username, instance string) string {
+	if username == "" {
+		return ""
+	}
+	host := instance
+	if parsed, err := url.Parse(instance); err == nil && parsed.Host != "" {
+		host = parsed.Host
+	}
+	return username + "@" + host
+}
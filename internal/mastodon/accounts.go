@@ -0,0 +1,41 @@
+package mastodon
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+var nextLinkPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// AccountFollowers returns one page of followers and the max_id of the next
+// page, which is empty on the last page. Follower pages are keyed by
+// internal follow IDs, so the cursor comes from the Link header.
+func (c *Client) AccountFollowers(id string, limit int, maxID string) ([]Account, string, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if maxID != "" {
+		query.Set("max_id", maxID)
+	}
+
+	var accounts []Account
+	header, err := c.requestJSONWithHeaders("GET", "/api/v1/accounts/"+url.PathEscape(id)+"/followers", query, nil, nil, &accounts)
+	if err != nil {
+		return nil, "", err
+	}
+	return accounts, nextMaxID(header), nil
+}
+
+// nextMaxID extracts max_id from the rel="next" Link header.
+func nextMaxID(header http.Header) string {
+	match := nextLinkPattern.FindStringSubmatch(header.Get("Link"))
+	if match == nil {
+		return ""
+	}
+	next, err := url.Parse(match[1])
+	if err != nil {
+		return ""
+	}
+	return next.Query().Get("max_id")
+}
@@ -0,0 +1,147 @@
+package mastodon
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Capabilities records what an instance runs and which optional APIs it
+// offers, so commands can refuse early instead of failing with a 404.
+type Capabilities struct {
+	// Software is the lower-case nodeinfo name, such as "mastodon",
+	// "gotosocial", "pleroma", "akkoma" or "iceshrimp".
+	Software    string                `json:"software"`
+	Version     string                `json:"version"`
+	APIVersion  int                   `json:"api_version,omitempty"`
+	Limits      InstanceConfiguration `json:"limits"`
+	Translation bool                  `json:"translation"`
+	Features    []string              `json:"features,omitempty"`
+	// StreamingURL is Instance.StreamingURL.
+	StreamingURL string `json:"streaming_url,omitempty"`
+
+	GroupedNotifications bool `json:"grouped_notifications"`
+	NotificationPolicy   bool `json:"notification_policy"`
+	StatusEdits          bool `json:"status_edits"`
+	ScheduledStatuses    bool `json:"scheduled_statuses"`
+
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// capabilities holds what SetCapabilities was given, per instance, for the
+// life of the process.
+var capabilities sync.Map
+
+var (
+	versionPattern    = regexp.MustCompile(`^(\d+)\.(\d+)`)
+	compatiblePattern = regexp.MustCompile(`\(compatible; ([^ ;)]+) ?([^ ;)]*)`)
+)
+
+// Capabilities returns the capabilities set with SetCapabilities. Without
+// them the client's checks give the instance the benefit of the doubt.
+func (c *Client) Capabilities() (*Capabilities, bool) {
+	cached, ok := capabilities.Load(c.baseURL)
+	if !ok {
+		return nil, false
+	}
+	return cached.(*Capabilities), true
+}
+
+// SetCapabilities hands the client capabilities detected earlier, such as
+// ones cached on disk.
+func (c *Client) SetCapabilities(caps *Capabilities) {
+	capabilities.Store(c.baseURL, caps)
+}
+
+// DetectCapabilities works out the capabilities from the instance
+// information and the nodeinfo. The nodeinfo is optional; without it the
+// software is guessed from the "(compatible; ...)" part of the version
+// other servers report.
+func (c *Client) DetectCapabilities(instance *Instance) *Capabilities {
+	caps := &Capabilities{
+		Version:      instance.Version,
+		APIVersion:   instance.APIVersion,
+		Limits:       instance.Configuration,
+		Translation:  instance.Translation,
+		StreamingURL: instance.StreamingURL,
+		CheckedAt:    time.Now(),
+	}
+	if info, err := c.NodeInfo(); err == nil && info.Software.Name != "" {
+		caps.Software = strings.ToLower(info.Software.Name)
+		caps.Version = info.Software.Version
+		caps.Features = info.Features
+	} else if match := compatiblePattern.FindStringSubmatch(instance.Version); match != nil {
+		caps.Software = strings.ToLower(match[1])
+		caps.Version = match[2]
+	} else {
+		caps.Software = "mastodon"
+	}
+	caps.detect()
+	return caps
+}
+
+// detect decides which optional APIs exist. Mastodon is judged by its
+// version; other servers only by the API version they advertise, and are
+// otherwise given the benefit of the doubt.
+func (caps *Capabilities) detect() {
+	if caps.Software == "mastodon" {
+		caps.GroupedNotifications = caps.APIVersion >= 2 || versionAtLeast(caps.Version, 4, 3)
+		caps.NotificationPolicy = caps.GroupedNotifications
+		caps.StatusEdits = versionAtLeast(caps.Version, 3, 5)
+		caps.ScheduledStatuses = versionAtLeast(caps.Version, 2, 7)
+		return
+	}
+	caps.GroupedNotifications = caps.APIVersion >= 2
+	caps.NotificationPolicy = caps.APIVersion >= 2
+	caps.StatusEdits = true
+	caps.ScheduledStatuses = true
+}
+
+// Name is the software and version for messages, e.g. "GoToSocial 0.17.0".
+func (caps *Capabilities) Name() string {
+	name := caps.Software
+	switch caps.Software {
+	case "mastodon":
+		name = "Mastodon"
+	case "gotosocial":
+		name = "GoToSocial"
+	case "pleroma":
+		name = "Pleroma"
+	case "akkoma":
+		name = "Akkoma"
+	case "iceshrimp", "iceshrimp.net":
+		name = "Iceshrimp"
+	}
+	if caps.Version == "" {
+		return name
+	}
+	return fmt.Sprintf("%s %s", name, caps.Version)
+}
+
+func versionAtLeast(version string, major, minor int) bool {
+	match := versionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return false
+	}
+	gotMajor, _ := strconv.Atoi(match[1])
+	gotMinor, _ := strconv.Atoi(match[2])
+	return gotMajor > major || (gotMajor == major && gotMinor >= minor)
+}
+
+// markUngrouped records that the grouped endpoint is missing after all.
+// Without capabilities set the entry knows nothing else, so the other APIs
+// get the benefit of the doubt and the limits stay unset.
+func (c *Client) markUngrouped() {
+	caps := &Capabilities{}
+	if cached, ok := capabilities.Load(c.baseURL); ok {
+		copied := *cached.(*Capabilities)
+		caps = &copied
+	} else {
+		caps.detect()
+	}
+	caps.GroupedNotifications = false
+	capabilities.Store(c.baseURL, caps)
+}
@@ -0,0 +1,115 @@
+package mastodon
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// MinPollDuration is the shortest poll the server accepts.
+const MinPollDuration = 5 * time.Minute
+
+// ErrPollClosingSoon comes with the edit parameters of a status whose poll
+// closes within MinPollDuration: the server refuses the time it has left,
+// and any longer duration moves its end.
+var ErrPollClosingSoon = errors.New("its poll closes in less than 5 minutes, too soon to keep through an edit")
+
+// StatusSource is the plain text a status was written in, before the
+// server rendered it to HTML.
+type StatusSource struct {
+	ID          string `json:"id"`
+	Text        string `json:"text"`
+	SpoilerText string `json:"spoiler_text"`
+}
+
+// EditParams replace a status. Media and polls that are left out are
+// removed, so EditParamsFor fills them from the current status.
+type EditParams struct {
+	Status      string      `json:"status"`
+	SpoilerText string      `json:"spoiler_text"`
+	Sensitive   bool        `json:"sensitive"`
+	Language    string      `json:"language,omitempty"`
+	MediaIDs    []string    `json:"media_ids"`
+	Poll        *PollParams `json:"poll,omitempty"`
+}
+
+// StatusEdit is one revision from a status's edit history.
+type StatusEdit struct {
+	Content          string            `json:"content"`
+	SpoilerText      string            `json:"spoiler_text"`
+	Sensitive        bool              `json:"sensitive"`
+	CreatedAt        string            `json:"created_at"`
+	Account          Account           `json:"account"`
+	MediaAttachments []MediaAttachment `json:"media_attachments"`
+	Poll             *struct {
+		Options []PollOption `json:"options"`
+	} `json:"poll"`
+}
+
+func (c *Client) GetStatusSource(id string) (*StatusSource, error) {
+	var source StatusSource
+	if err := c.requestJSON("GET", "/api/v1/statuses/"+url.PathEscape(id)+"/source", nil, nil, &source); err != nil {
+		return nil, err
+	}
+	return &source, nil
+}
+
+func (c *Client) EditStatus(id string, params EditParams) (*Status, error) {
+	if params.MediaIDs == nil {
+		params.MediaIDs = []string{}
+	}
+	var status Status
+	if err := c.requestJSON("PUT", "/api/v1/statuses/"+url.PathEscape(id), nil, params, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// StatusHistory returns every revision of a status, oldest first. The last
+// entry is the current version.
+func (c *Client) StatusHistory(id string) ([]StatusEdit, error) {
+	var edits []StatusEdit
+	if err := c.requestJSON("GET", "/api/v1/statuses/"+url.PathEscape(id)+"/history", nil, nil, &edits); err != nil {
+		return nil, err
+	}
+	return edits, nil
+}
+
+// EditParamsFor starts an edit from the current status and its source,
+// keeping attachments, the poll, the sensitive flag and the language. A
+// closed poll cannot be sent back, so such statuses are refused; a poll
+// about to close returns ErrPollClosingSoon along with the parameters. An
+// open poll hides its totals exactly when the server leaves out the counts.
+func EditParamsFor(status Status, source StatusSource, now time.Time) (EditParams, error) {
+	params := EditParams{
+		Status:      source.Text,
+		SpoilerText: source.SpoilerText,
+		Sensitive:   status.Sensitive,
+		Language:    status.Language,
+		MediaIDs:    make([]string, 0, len(status.MediaAttachments)),
+	}
+	for _, attachment := range status.MediaAttachments {
+		params.MediaIDs = append(params.MediaIDs, attachment.ID)
+	}
+	if poll := status.Poll; poll != nil {
+		expires, err := time.Parse(time.RFC3339, poll.ExpiresAt)
+		if poll.Expired || (err == nil && !expires.After(now)) {
+			return params, fmt.Errorf("status %s has a closed poll, which an edit would remove", status.ID)
+		}
+		// An unreadable end time keeps the poll open for a day.
+		left := 24 * time.Hour
+		if err == nil {
+			left = expires.Sub(now)
+		}
+		params.Poll = &PollParams{ExpiresIn: int(left.Seconds()), Multiple: poll.Multiple}
+		for _, option := range poll.Options {
+			params.Poll.Options = append(params.Poll.Options, option.Title)
+			params.Poll.HideTotals = option.VotesCount == nil
+		}
+		if left < MinPollDuration {
+			return params, fmt.Errorf("status %s: %w", status.ID, ErrPollClosingSoon)
+		}
+	}
+	return params, nil
+}
@@ -0,0 +1,31 @@
+package mastodon
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEditParamsForKeepsThePollEnd(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	status := Status{ID: "7", Poll: &Poll{
+		ExpiresAt: now.Add(time.Hour).Format(time.RFC3339),
+		Options:   []PollOption{{Title: "Yes"}, {Title: "No"}},
+	}}
+
+	params, err := EditParamsFor(status, StatusSource{Text: "Open?"}, now)
+	if err != nil {
+		t.Fatalf("EditParamsFor error: %v", err)
+	}
+	if params.Poll == nil || params.Poll.ExpiresIn != 3600 {
+		t.Fatalf("expected the poll to end in an hour, got %+v", params.Poll)
+	}
+
+	params, err = EditParamsFor(status, StatusSource{Text: "Open?"}, now.Add(58*time.Minute))
+	if !errors.Is(err, ErrPollClosingSoon) {
+		t.Fatalf("expected ErrPollClosingSoon, got %v", err)
+	}
+	if params.Poll == nil || params.Poll.ExpiresIn != 120 {
+		t.Fatalf("expected the time left with the error, got %+v", params.Poll)
+	}
+}
@@ -0,0 +1,283 @@
+package mastodon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Defaults used by Mastodon when an instance does not advertise limits.
+const (
+	DefaultMaxCharacters       = 500
+	DefaultURLLength           = 23
+	DefaultMaxMediaAttachments = 4
+	DefaultDescriptionLimit    = 1500
+)
+
+// InstanceConfiguration holds the limits an instance puts on statuses.
+type InstanceConfiguration struct {
+	Statuses         StatusConfiguration `json:"statuses"`
+	MediaAttachments MediaConfiguration  `json:"media_attachments"`
+}
+
+type StatusConfiguration struct {
+	MaxCharacters            int `json:"max_characters"`
+	MaxMediaAttachments      int `json:"max_media_attachments"`
+	CharactersReservedPerURL int `json:"characters_reserved_per_url"`
+}
+
+// MediaConfiguration limits uploads; zero sizes and an empty type list mean
+// the instance did not say.
+type MediaConfiguration struct {
+	SupportedMimeTypes []string `json:"supported_mime_types"`
+	ImageSizeLimit     int64    `json:"image_size_limit"`
+	VideoSizeLimit     int64    `json:"video_size_limit"`
+	DescriptionLimit   int      `json:"description_limit"`
+}
+
+// Rule is one of the server rules users agree to on sign-up.
+type Rule struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+	Hint string `json:"hint"`
+}
+
+// Instance describes a server. Fields missing from older APIs stay empty.
+type Instance struct {
+	Domain      string
+	Title       string
+	Version     string
+	Description string
+	Languages   []string
+	// ActiveUsers counts users active this month; Users, from the v1 API,
+	// counts all of them.
+	ActiveUsers      int
+	Users            int
+	Registrations    bool
+	ApprovalRequired bool
+	ContactEmail     string
+	ContactAccount   *Account
+	Rules            []Rule
+	Configuration    InstanceConfiguration
+	Translation      bool
+	// StreamingURL is where the streaming API lives when it has its own
+	// host, e.g. "wss://streaming.example"; empty means the instance host.
+	StreamingURL string
+	// APIVersion is api_versions.mastodon (Mastodon 4.3+), 0 when absent.
+	APIVersion int
+}
+
+// Instance reads /api/v2/instance, falling back to /api/v1/instance (and
+// Pleroma's max_toot_chars) on older servers. Missing limits are filled
+// with Mastodon's defaults.
+func (c *Client) Instance() (*Instance, error) {
+	var raw struct {
+		Domain           string          `json:"domain"`
+		URI              string          `json:"uri"`
+		Title            string          `json:"title"`
+		Version          string          `json:"version"`
+		Description      string          `json:"description"`
+		ShortDescription string          `json:"short_description"`
+		Languages        []string        `json:"languages"`
+		Registrations    json.RawMessage `json:"registrations"`
+		ApprovalRequired bool            `json:"approval_required"`
+		Usage            struct {
+			Users struct {
+				ActiveMonth int `json:"active_month"`
+			} `json:"users"`
+		} `json:"usage"`
+		Stats struct {
+			UserCount int `json:"user_count"`
+		} `json:"stats"`
+		Contact struct {
+			Email   string   `json:"email"`
+			Account *Account `json:"account"`
+		} `json:"contact"`
+		Email          string   `json:"email"`
+		ContactAccount *Account `json:"contact_account"`
+		Rules          []Rule   `json:"rules"`
+		Configuration  struct {
+			InstanceConfiguration
+			Translation struct {
+				Enabled bool `json:"enabled"`
+			} `json:"translation"`
+			URLs struct {
+				Streaming string `json:"streaming"`
+			} `json:"urls"`
+		} `json:"configuration"`
+		URLs struct {
+			StreamingAPI string `json:"streaming_api"`
+		} `json:"urls"`
+		MaxTootChars int `json:"max_toot_chars"`
+		APIVersions  struct {
+			Mastodon int `json:"mastodon"`
+		} `json:"api_versions"`
+	}
+	err := c.requestJSON("GET", "/api/v2/instance", nil, nil, &raw)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		err = c.requestJSON("GET", "/api/v1/instance", nil, nil, &raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	instance := &Instance{
+		Domain:           raw.Domain,
+		Title:            raw.Title,
+		Version:          raw.Version,
+		Description:      raw.ShortDescription,
+		Languages:        raw.Languages,
+		ActiveUsers:      raw.Usage.Users.ActiveMonth,
+		Users:            raw.Stats.UserCount,
+		ApprovalRequired: raw.ApprovalRequired,
+		ContactEmail:     raw.Contact.Email,
+		ContactAccount:   raw.Contact.Account,
+		Rules:            raw.Rules,
+		Configuration:    raw.Configuration.InstanceConfiguration,
+		Translation:      raw.Configuration.Translation.Enabled,
+		StreamingURL:     raw.Configuration.URLs.Streaming,
+		APIVersion:       raw.APIVersions.Mastodon,
+	}
+	if instance.Domain == "" {
+		instance.Domain = strings.TrimPrefix(strings.TrimPrefix(raw.URI, "https://"), "http://")
+	}
+	if instance.Description == "" {
+		instance.Description = raw.Description
+	}
+	if instance.ContactEmail == "" {
+		instance.ContactEmail = raw.Email
+	}
+	if instance.StreamingURL == "" {
+		instance.StreamingURL = raw.URLs.StreamingAPI
+	}
+	if instance.ContactAccount == nil {
+		instance.ContactAccount = raw.ContactAccount
+	}
+	// v1 sends registrations as a boolean, v2 as an object.
+	var registrations struct {
+		Enabled          bool `json:"enabled"`
+		ApprovalRequired bool `json:"approval_required"`
+	}
+	if err := json.Unmarshal(raw.Registrations, &registrations.Enabled); err != nil {
+		if json.Unmarshal(raw.Registrations, &registrations) == nil {
+			instance.ApprovalRequired = registrations.ApprovalRequired
+		}
+	}
+	instance.Registrations = registrations.Enabled
+
+	config := &instance.Configuration
+	if config.Statuses.MaxCharacters == 0 {
+		config.Statuses.MaxCharacters = raw.MaxTootChars
+	}
+	if config.Statuses.MaxCharacters == 0 {
+		config.Statuses.MaxCharacters = DefaultMaxCharacters
+	}
+	if config.Statuses.CharactersReservedPerURL == 0 {
+		config.Statuses.CharactersReservedPerURL = DefaultURLLength
+	}
+	if config.Statuses.MaxMediaAttachments == 0 {
+		config.Statuses.MaxMediaAttachments = DefaultMaxMediaAttachments
+	}
+	if config.MediaAttachments.DescriptionLimit == 0 {
+		config.MediaAttachments.DescriptionLimit = DefaultDescriptionLimit
+	}
+	return instance, nil
+}
+
+// InstanceConfiguration returns the limits of the instance from the
+// capabilities set on the client, asking the instance when none are set
+// or they carry no limits (see markUngrouped).
+func (c *Client) InstanceConfiguration() (*InstanceConfiguration, error) {
+	if caps, ok := c.Capabilities(); ok && caps.Limits.Statuses.MaxCharacters != 0 {
+		config := caps.Limits
+		return &config, nil
+	}
+	instance, err := c.Instance()
+	if err != nil {
+		return nil, err
+	}
+	return &instance.Configuration, nil
+}
+
+// ExtendedDescription returns the HTML "about" page of the instance.
+// Servers without the endpoint (before Mastodon 4.0) return "".
+func (c *Client) ExtendedDescription() (string, error) {
+	var description struct {
+		Content string `json:"content"`
+	}
+	err := c.requestJSON("GET", "/api/v1/instance/extended_description", nil, nil, &description)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return description.Content, nil
+}
+
+// NodeInfo is the part of a nodeinfo document that names the server
+// software.
+type NodeInfo struct {
+	Software struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"software"`
+	// Features lists metadata.features, which Pleroma and Akkoma send.
+	Features []string `json:"-"`
+}
+
+const nodeInfoSchema = "http://nodeinfo.diaspora.software/ns/schema/"
+
+// NodeInfo follows /.well-known/nodeinfo to the newest schema the server
+// offers. The document may live on another host, such as the web domain of
+// a split-domain setup; it is public, so no token is sent with it.
+func (c *Client) NodeInfo() (*NodeInfo, error) {
+	var index struct {
+		Links []struct {
+			Rel  string `json:"rel"`
+			Href string `json:"href"`
+		} `json:"links"`
+	}
+	if err := c.requestJSON("GET", "/.well-known/nodeinfo", nil, nil, &index); err != nil {
+		return nil, err
+	}
+	rel, href := "", ""
+	for _, link := range index.Links {
+		if strings.HasPrefix(link.Rel, nodeInfoSchema) && link.Rel > rel {
+			rel, href = link.Rel, link.Href
+		}
+	}
+	if href == "" {
+		return nil, fmt.Errorf("nodeinfo: no supported schema")
+	}
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("nodeinfo: %w", err)
+	}
+	target, err := base.Parse(href)
+	if err != nil {
+		return nil, fmt.Errorf("nodeinfo: %w", err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, fmt.Errorf("nodeinfo: unsupported link %q", href)
+	}
+
+	var document struct {
+		NodeInfo
+		Metadata struct {
+			// Misskey forks send an object here, so it is decoded on demand.
+			Features json.RawMessage `json:"features"`
+		} `json:"metadata"`
+	}
+	if err := c.getPublicJSON(target.String(), &document); err != nil {
+		return nil, err
+	}
+	info := document.NodeInfo
+	_ = json.Unmarshal(document.Metadata.Features, &info.Features)
+	return &info, nil
+}
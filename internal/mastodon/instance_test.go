@@ -0,0 +1,54 @@
+package mastodon
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNodeInfoFollowsLinksToAnotherHost(t *testing.T) {
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/nodeinfo/2.1" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Fatal("the token was sent to another host")
+		}
+		_, _ = w.Write([]byte(`{"software": {"name": "Mastodon", "version": "4.3.0"}}`))
+	}))
+	defer web.Close()
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/nodeinfo" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"links": [{"rel": "http://nodeinfo.diaspora.software/ns/schema/2.1", "href": "%s/nodeinfo/2.1"}]}`, web.URL)
+	}))
+	defer app.Close()
+
+	info, err := NewClient(app.URL, "token").NodeInfo()
+	if err != nil {
+		t.Fatalf("NodeInfo error: %v", err)
+	}
+	if info.Software.Name != "Mastodon" || info.Software.Version != "4.3.0" {
+		t.Fatalf("unexpected nodeinfo: %+v", info)
+	}
+}
+
+func TestInstanceKeepsApprovalWithoutRegistrations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/instance" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"domain": "social.example", "approval_required": true}`))
+	}))
+	defer server.Close()
+
+	instance, err := NewClient(server.URL, "").Instance()
+	if err != nil {
+		t.Fatalf("Instance error: %v", err)
+	}
+	if !instance.ApprovalRequired {
+		t.Fatal("approval_required was overwritten")
+	}
+}
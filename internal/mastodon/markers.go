@@ -0,0 +1,67 @@
+package mastodon
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type Marker struct {
+	LastReadID string `json:"last_read_id"`
+	Version    int    `json:"version"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// Markers returns the read positions for the given timelines ("home",
+// "notifications"), keyed by timeline.
+func (c *Client) Markers(timelines ...string) (map[string]Marker, error) {
+	query := url.Values{"timeline[]": timelines}
+	markers := make(map[string]Marker)
+	if err := c.requestJSON("GET", "/api/v1/markers", query, nil, &markers); err != nil {
+		return nil, err
+	}
+	return markers, nil
+}
+
+// SaveMarkers updates the read positions; empty IDs are left unchanged.
+func (c *Client) SaveMarkers(homeID, notificationsID string) error {
+	type position struct {
+		LastReadID string `json:"last_read_id"`
+	}
+	body := make(map[string]position, 2)
+	if homeID != "" {
+		body["home"] = position{LastReadID: homeID}
+	}
+	if notificationsID != "" {
+		body["notifications"] = position{LastReadID: notificationsID}
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	return c.requestJSON("POST", "/api/v1/markers", nil, body, nil)
+}
+
+// HomeTimelineAfter returns the statuses directly newer than minID, newest
+// first, which lets callers resume reading from a marker.
+func (c *Client) HomeTimelineAfter(limit int, minID string) ([]Status, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("min_id", minID)
+	var statuses []Status
+	if err := c.requestJSON("GET", "/api/v1/timelines/home", query, nil, &statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// CompareIDs orders Mastodon IDs, which are numeric strings that can exceed
+// 64 bits. It returns -1, 0 or 1.
+func CompareIDs(a, b string) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
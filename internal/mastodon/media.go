@@ -0,0 +1,244 @@
+package mastodon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MediaProcessingTimeout bounds how long UploadMedia waits for the server
+// to finish processing a large file.
+const MediaProcessingTimeout = 2 * time.Minute
+
+// ErrMissingAlt is returned by CheckMedia for images without a description.
+var ErrMissingAlt = errors.New("image has no alt text")
+
+// MediaUpload is a local file to attach to a status.
+type MediaUpload struct {
+	Path        string
+	Description string
+	// Focus is "x,y" with both coordinates between -1 and 1.
+	Focus string
+}
+
+// UploadMedia sends the file to /api/v2/media and, when the server
+// processes it asynchronously, polls until the attachment is ready.
+func (c *Client) UploadMedia(upload MediaUpload) (*MediaAttachment, error) {
+	const path = "/api/v2/media"
+	file, err := os.Open(upload.Path)
+	if err != nil {
+		return nil, fmt.Errorf("open media: %w", err)
+	}
+	defer file.Close()
+
+	body, writer := io.Pipe()
+	defer body.Close()
+	form := multipart.NewWriter(writer)
+	go func() {
+		err := writeMediaForm(form, file, upload)
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequest("POST", c.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("build POST %s: %w", path, err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if c.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("POST %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, responseError("POST", path, resp)
+	}
+	var attachment MediaAttachment
+	if err := json.NewDecoder(resp.Body).Decode(&attachment); err != nil {
+		return nil, fmt.Errorf("decode POST %s: %w", path, err)
+	}
+	if resp.StatusCode == http.StatusAccepted || attachment.URL == "" {
+		return c.waitForMedia(attachment.ID)
+	}
+	return &attachment, nil
+}
+
+// CheckMediaFiles validates uploads against the limits of this instance.
+// When the instance does not answer, only the attachment count and alt text
+// are checked.
+func (c *Client) CheckMediaFiles(uploads []MediaUpload, requireAlt bool) error {
+	if len(uploads) == 0 {
+		return nil
+	}
+	config, err := c.InstanceConfiguration()
+	if err != nil {
+		config = &InstanceConfiguration{
+			Statuses:         StatusConfiguration{MaxMediaAttachments: DefaultMaxMediaAttachments},
+			MediaAttachments: MediaConfiguration{DescriptionLimit: DefaultDescriptionLimit},
+		}
+	}
+	return config.CheckMedia(uploads, requireAlt)
+}
+
+// UploadMediaFiles uploads in order and returns the attachment IDs for
+// StatusParams.MediaIDs.
+func (c *Client) UploadMediaFiles(uploads []MediaUpload) ([]string, error) {
+	ids := make([]string, 0, len(uploads))
+	for _, upload := range uploads {
+		attachment, err := c.UploadMedia(upload)
+		if err != nil {
+			return nil, fmt.Errorf("upload %s: %w", filepath.Base(upload.Path), err)
+		}
+		ids = append(ids, attachment.ID)
+	}
+	return ids, nil
+}
+
+func writeMediaForm(form *multipart.Writer, file *os.File, upload MediaUpload) error {
+	if upload.Description != "" {
+		if err := form.WriteField("description", upload.Description); err != nil {
+			return err
+		}
+	}
+	if upload.Focus != "" {
+		if err := form.WriteField("focus", upload.Focus); err != nil {
+			return err
+		}
+	}
+	part, err := form.CreateFormFile("file", filepath.Base(upload.Path))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(part, file)
+	return err
+}
+
+// waitForMedia polls an attachment until the server has set its URL.
+func (c *Client) waitForMedia(id string) (*MediaAttachment, error) {
+	deadline := time.Now().Add(MediaProcessingTimeout)
+	delay := 500 * time.Millisecond
+	for {
+		var attachment MediaAttachment
+		if err := c.requestJSON("GET", "/api/v1/media/"+url.PathEscape(id), nil, nil, &attachment); err != nil {
+			return nil, err
+		}
+		if attachment.URL != "" {
+			return &attachment, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("media %s still processing after %s", id, MediaProcessingTimeout)
+		}
+		time.Sleep(delay)
+		if delay < 5*time.Second {
+			delay *= 2
+		}
+	}
+}
+
+// ParseFocus checks an "x,y" focus point.
+func ParseFocus(value string) (float64, float64, error) {
+	xs, ys, ok := strings.Cut(value, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("focus must be x,y")
+	}
+	x, errX := strconv.ParseFloat(strings.TrimSpace(xs), 64)
+	y, errY := strconv.ParseFloat(strings.TrimSpace(ys), 64)
+	if errX != nil || errY != nil || x < -1 || x > 1 || y < -1 || y > 1 {
+		return 0, 0, fmt.Errorf("focus must be x,y with both between -1 and 1")
+	}
+	return x, y, nil
+}
+
+// MediaType guesses a file's MIME type from its extension, or from its
+// first bytes when the extension is unknown.
+func MediaType(path string) (string, error) {
+	if byExt := mime.TypeByExtension(strings.ToLower(filepath.Ext(path))); byExt != "" {
+		mediaType, _, _ := strings.Cut(byExt, ";")
+		return mediaType, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("open media: %w", err)
+	}
+	defer file.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read media: %w", err)
+	}
+	mediaType, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+	return mediaType, nil
+}
+
+// CheckMedia validates uploads against the instance limits before anything
+// is sent. Images need a description when requireAlt is set.
+func (cfg InstanceConfiguration) CheckMedia(uploads []MediaUpload, requireAlt bool) error {
+	if max := cfg.Statuses.MaxMediaAttachments; max > 0 && len(uploads) > max {
+		return fmt.Errorf("at most %d attachments are allowed per status, got %d", max, len(uploads))
+	}
+	limits := cfg.MediaAttachments
+	for _, upload := range uploads {
+		info, err := os.Stat(upload.Path)
+		if err != nil {
+			return fmt.Errorf("media: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("%s: is a directory", upload.Path)
+		}
+		mediaType, err := MediaType(upload.Path)
+		if err != nil {
+			return err
+		}
+		if len(limits.SupportedMimeTypes) > 0 && !slices.Contains(limits.SupportedMimeTypes, mediaType) {
+			return fmt.Errorf("%s: %s is not supported by this instance", upload.Path, mediaType)
+		}
+
+		limit := limits.VideoSizeLimit
+		if strings.HasPrefix(mediaType, "image/") {
+			limit = limits.ImageSizeLimit
+		}
+		if limit > 0 && info.Size() > limit {
+			return fmt.Errorf("%s: %s is larger than the instance limit of %s", upload.Path, mediaSize(info.Size()), mediaSize(limit))
+		}
+
+		if upload.Focus != "" {
+			if _, _, err := ParseFocus(upload.Focus); err != nil {
+				return fmt.Errorf("%s: %w", upload.Path, err)
+			}
+		}
+		description := strings.TrimSpace(upload.Description)
+		if n := len([]rune(description)); limits.DescriptionLimit > 0 && n > limits.DescriptionLimit {
+			return fmt.Errorf("%s: alt text has %d characters, the limit is %d", upload.Path, n, limits.DescriptionLimit)
+		}
+		if requireAlt && description == "" && strings.HasPrefix(mediaType, "image/") {
+			return fmt.Errorf("%s: %w", upload.Path, ErrMissingAlt)
+		}
+	}
+	return nil
+}
+
+func mediaSize(size int64) string {
+	const mb = 1 << 20
+	if size >= mb {
+		return fmt.Sprintf("%.1f MB", float64(size)/mb)
+	}
+	return fmt.Sprintf("%d KB", (size+1023)/1024)
+}
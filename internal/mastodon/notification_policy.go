@@ -0,0 +1,93 @@
+package mastodon
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Notification policy values: notifications are shown, moved into
+// requests, or dropped.
+const (
+	PolicyAccept = "accept"
+	PolicyFilter = "filter"
+	PolicyDrop   = "drop"
+)
+
+// NotificationPolicy decides what happens to notifications from accounts
+// the user has no relationship with (Mastodon 4.3+).
+type NotificationPolicy struct {
+	ForNotFollowing    string `json:"for_not_following"`
+	ForNotFollowers    string `json:"for_not_followers"`
+	ForNewAccounts     string `json:"for_new_accounts"`
+	ForPrivateMentions string `json:"for_private_mentions"`
+	ForLimitedAccounts string `json:"for_limited_accounts"`
+	Summary            struct {
+		PendingRequestsCount      int `json:"pending_requests_count"`
+		PendingNotificationsCount int `json:"pending_notifications_count"`
+	} `json:"summary"`
+}
+
+// NotificationPolicyUpdate changes the named fields; empty ones are left
+// as they are.
+type NotificationPolicyUpdate struct {
+	ForNotFollowing    string `json:"for_not_following,omitempty"`
+	ForNotFollowers    string `json:"for_not_followers,omitempty"`
+	ForNewAccounts     string `json:"for_new_accounts,omitempty"`
+	ForPrivateMentions string `json:"for_private_mentions,omitempty"`
+	ForLimitedAccounts string `json:"for_limited_accounts,omitempty"`
+}
+
+// NotificationRequest groups the filtered notifications from one account.
+type NotificationRequest struct {
+	ID        string  `json:"id"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+	Account   Account `json:"account"`
+	// NotificationsCount is sent as a string by Mastodon.
+	NotificationsCount string  `json:"notifications_count"`
+	LastStatus         *Status `json:"last_status"`
+}
+
+func (c *Client) NotificationPolicy() (*NotificationPolicy, error) {
+	var policy NotificationPolicy
+	if err := c.requestJSON("GET", "/api/v2/notifications/policy", nil, nil, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (c *Client) UpdateNotificationPolicy(update NotificationPolicyUpdate) (*NotificationPolicy, error) {
+	var policy NotificationPolicy
+	if err := c.requestJSON("PATCH", "/api/v2/notifications/policy", nil, update, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// NotificationRequestsPage lists pending requests older than maxID and
+// returns the max_id of the next page, or "" after the last one.
+func (c *Client) NotificationRequestsPage(limit int, maxID string) ([]NotificationRequest, string, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if maxID != "" {
+		query.Set("max_id", maxID)
+	}
+	var requests []NotificationRequest
+	header, err := c.requestJSONWithHeaders("GET", "/api/v1/notifications/requests", query, nil, nil, &requests)
+	if err != nil {
+		return nil, "", err
+	}
+	return requests, nextMaxID(header), nil
+}
+
+// AcceptNotificationRequest moves the account's filtered notifications into
+// the main list and lets future ones through.
+func (c *Client) AcceptNotificationRequest(id string) error {
+	return c.requestJSON("POST", "/api/v1/notifications/requests/"+url.PathEscape(id)+"/accept", nil, nil, nil)
+}
+
+// DismissNotificationRequest drops the filtered notifications; future ones
+// are filtered again.
+func (c *Client) DismissNotificationRequest(id string) error {
+	return c.requestJSON("POST", "/api/v1/notifications/requests/"+url.PathEscape(id)+"/dismiss", nil, nil, nil)
+}
@@ -0,0 +1,311 @@
+package mastodon
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NotificationsPage reads ungrouped notifications from the v1 endpoint,
+// newest first. sinceID and maxID bound the page and are optional; types
+// limits the result to the given notification types when non-empty.
+func (c *Client) NotificationsPage(limit int, sinceID, maxID string, types []string) ([]Notification, error) {
+	notifications, _, err := c.UngroupedNotificationsPage(limit, sinceID, maxID, NotificationFilter{Types: types})
+	return notifications, err
+}
+
+// UngroupedNotificationsPage is NotificationsPage with a full filter. It
+// also returns the max_id of the next page, or "" after the last one.
+func (c *Client) UngroupedNotificationsPage(limit int, sinceID, maxID string, filter NotificationFilter) ([]Notification, string, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if sinceID != "" {
+		query.Set("since_id", sinceID)
+	}
+	if maxID != "" {
+		query.Set("max_id", maxID)
+	}
+	filter.query(query)
+
+	var notifications []Notification
+	header, err := c.requestJSONWithHeaders("GET", "/api/v1/notifications", query, nil, nil, &notifications)
+	if err != nil {
+		return nil, "", err
+	}
+	next := nextMaxID(header)
+	if next == "" && len(notifications) == limit {
+		next = notifications[len(notifications)-1].ID
+	}
+	return notifications, next, nil
+}
+
+// NotificationGroupsPage reads a page of grouped notifications like
+// FilteredNotificationsPage. Servers without /api/v2/notifications (older
+// Mastodon, GoToSocial, Akkoma and other forks) are read through
+// /api/v1/notifications and grouped here with GroupNotifications.
+func (c *Client) NotificationGroupsPage(limit int, maxID string, filter NotificationFilter) ([]GroupedNotification, string, error) {
+	if caps, ok := c.Capabilities(); ok && !caps.GroupedNotifications {
+		return c.ungroupedNotificationsPage(limit, maxID, filter)
+	}
+	groups, next, err := c.FilteredNotificationsPage(limit, maxID, filter)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		c.markUngrouped()
+		return c.ungroupedNotificationsPage(limit, maxID, filter)
+	}
+	return groups, next, err
+}
+
+func (c *Client) ungroupedNotificationsPage(limit int, maxID string, filter NotificationFilter) ([]GroupedNotification, string, error) {
+	notifications, next, err := c.UngroupedNotificationsPage(limit, "", maxID, filter)
+	if err != nil {
+		return nil, "", err
+	}
+	return GroupNotifications(notifications), next, nil
+}
+
+// maxSampleAccounts matches how many accounts Mastodon samples per group.
+const maxSampleAccounts = 8
+
+// GroupNotifications groups a newest-first page the way Mastodon does:
+// favourites and boosts of the same status, and follows on the same day,
+// become one group. Other types stay on their own. Servers that send a
+// group_key keep their own grouping.
+func GroupNotifications(notifications []Notification) []GroupedNotification {
+	var groups []GroupedNotification
+	index := make(map[string]int)
+	for _, notification := range notifications {
+		key := NotificationGroupKey(notification)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(groups)
+			groups = append(groups, GroupedNotification{
+				GroupKey:   key,
+				Type:       notification.Type,
+				Status:     notification.Status,
+				LatestAt:   notification.CreatedAt,
+				MostRecent: notification.ID,
+			})
+			i = len(groups) - 1
+		}
+		group := &groups[i]
+		group.Count++
+		if len(group.Accounts) < maxSampleAccounts && !slices.ContainsFunc(group.Accounts, func(account Account) bool {
+			return account.ID == notification.Account.ID
+		}) {
+			group.Accounts = append(group.Accounts, notification.Account)
+		}
+	}
+	return groups
+}
+
+// NotificationGroupKey is the GroupKey of the group GroupNotifications
+// puts notification in.
+func NotificationGroupKey(notification Notification) string {
+	if notification.GroupKey != "" {
+		return notification.GroupKey
+	}
+	switch notification.Type {
+	case "favourite", "reblog":
+		if notification.Status != nil {
+			return notification.Type + "-" + notification.Status.ID
+		}
+	case "follow":
+		if day, _, ok := strings.Cut(notification.CreatedAt, "T"); ok {
+			return "follow-" + day
+		}
+	}
+	return "ungrouped-" + notification.ID
+}
+
+// NotificationDetail carries the type-specific payloads that grouped
+// notifications leave out: the severance event, the moderation warning, the
+// report and the quoted status.
+type NotificationDetail struct {
+	ID                string                      `json:"id"`
+	Type              string                      `json:"type"`
+	Event             *RelationshipSeveranceEvent `json:"event"`
+	ModerationWarning *AccountWarning             `json:"moderation_warning"`
+	Report            *Report                     `json:"report"`
+	AnnualReport      *struct {
+		Year int `json:"year"`
+	} `json:"annual_report"`
+	Status *struct {
+		Quote *Quote `json:"quote"`
+	} `json:"status"`
+}
+
+// RelationshipSeveranceEvent reports follows lost to a moderation action.
+type RelationshipSeveranceEvent struct {
+	ID string `json:"id"`
+	// Type is domain_block, user_domain_block or account_suspension.
+	Type           string `json:"type"`
+	Purged         bool   `json:"purged"`
+	TargetName     string `json:"target_name"`
+	FollowersCount int    `json:"followers_count"`
+	FollowingCount int    `json:"following_count"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// AccountWarning is a moderation action taken against the user's account.
+type AccountWarning struct {
+	ID        string   `json:"id"`
+	Action    string   `json:"action"`
+	Text      string   `json:"text"`
+	StatusIDs []string `json:"status_ids"`
+	Appeal    *struct {
+		Text  string `json:"text"`
+		State string `json:"state"`
+	} `json:"appeal"`
+	CreatedAt string `json:"created_at"`
+}
+
+type Report struct {
+	ID            string   `json:"id"`
+	Category      string   `json:"category"`
+	Comment       string   `json:"comment"`
+	Forwarded     bool     `json:"forwarded"`
+	StatusIDs     []string `json:"status_ids"`
+	RuleIDs       []string `json:"rule_ids"`
+	TargetAccount Account  `json:"target_account"`
+	CreatedAt     string   `json:"created_at"`
+}
+
+// Quote is the status a post quotes; QuotedStatus is empty unless State is
+// accepted.
+type Quote struct {
+	State        string  `json:"state"`
+	QuotedStatus *Status `json:"quoted_status"`
+}
+
+// QuotedStatus returns the status quoted by the notification's status, if
+// any.
+func (d NotificationDetail) QuotedStatus() *Status {
+	if d.Status == nil || d.Status.Quote == nil {
+		return nil
+	}
+	return d.Status.Quote.QuotedStatus
+}
+
+func (c *Client) Notification(id string) (*NotificationDetail, error) {
+	var detail NotificationDetail
+	if err := c.requestJSON("GET", "/api/v1/notifications/"+url.PathEscape(id), nil, nil, &detail); err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
+
+// AuthorizeFollowRequest accepts the follow request from accountID.
+func (c *Client) AuthorizeFollowRequest(accountID string) error {
+	return c.requestJSON("POST", "/api/v1/follow_requests/"+url.PathEscape(accountID)+"/authorize", nil, nil, nil)
+}
+
+func (c *Client) RejectFollowRequest(accountID string) error {
+	return c.requestJSON("POST", "/api/v1/follow_requests/"+url.PathEscape(accountID)+"/reject", nil, nil, nil)
+}
+
+// NotificationTypes lists the types a NotificationFilter can name.
+var NotificationTypes = []string{
+	"mention", "status", "reblog", "follow", "follow_request", "favourite", "poll", "update",
+	"admin.sign_up", "admin.report", "severed_relationships", "moderation_warning",
+	"quote", "quoted_update", "annual_report",
+}
+
+// NotificationFilter narrows a notifications request to Types (all when
+// empty) minus ExcludeTypes.
+type NotificationFilter struct {
+	Types        []string
+	ExcludeTypes []string
+}
+
+// Matches reports whether notifications of type kind pass the filter, for
+// notifications that did not come through a filtered request.
+func (f NotificationFilter) Matches(kind string) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, kind) {
+		return false
+	}
+	return !slices.Contains(f.ExcludeTypes, kind)
+}
+
+func (f NotificationFilter) query(query url.Values) {
+	for _, kind := range f.Types {
+		query.Add("types[]", kind)
+	}
+	for _, kind := range f.ExcludeTypes {
+		query.Add("exclude_types[]", kind)
+	}
+}
+
+// FilteredNotificationsPage reads one page of grouped notifications older
+// than maxID (the newest when empty) and returns the max_id of the next
+// page, or "" after the last one. An empty filter reads every type.
+func (c *Client) FilteredNotificationsPage(limit int, maxID string, filter NotificationFilter) ([]GroupedNotification, string, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if maxID != "" {
+		query.Set("max_id", maxID)
+	}
+	filter.query(query)
+
+	var page notificationGroupsPage
+	header, err := c.requestJSONWithHeaders("GET", "/api/v2/notifications", query, nil, nil, &page)
+	if err != nil {
+		return nil, "", err
+	}
+	next := nextMaxID(header)
+	if next == "" && len(page.Groups) == limit {
+		next = page.Groups[len(page.Groups)-1].PageMinID
+	}
+	return page.resolve(), next, nil
+}
+
+// notificationGroupsPage is the /api/v2/notifications response: groups
+// refer to the accounts and statuses listed beside them by ID.
+type notificationGroupsPage struct {
+	Accounts []Account `json:"accounts"`
+	Statuses []Status  `json:"statuses"`
+	Groups   []struct {
+		GroupKey         string   `json:"group_key"`
+		Type             string   `json:"type"`
+		Count            int      `json:"notifications_count"`
+		MostRecentID     string   `json:"most_recent_notification_id"`
+		PageMinID        string   `json:"page_min_id"`
+		LatestAt         string   `json:"latest_page_notification_at"`
+		SampleAccountIDs []string `json:"sample_account_ids"`
+		StatusID         string   `json:"status_id"`
+	} `json:"notification_groups"`
+}
+
+// resolve joins each group with its sample accounts and status.
+func (page notificationGroupsPage) resolve() []GroupedNotification {
+	accounts := make(map[string]Account, len(page.Accounts))
+	for _, account := range page.Accounts {
+		accounts[account.ID] = account
+	}
+	statuses := make(map[string]*Status, len(page.Statuses))
+	for i := range page.Statuses {
+		statuses[page.Statuses[i].ID] = &page.Statuses[i]
+	}
+
+	groups := make([]GroupedNotification, 0, len(page.Groups))
+	for _, group := range page.Groups {
+		item := GroupedNotification{
+			GroupKey:   group.GroupKey,
+			Type:       group.Type,
+			Count:      group.Count,
+			Status:     statuses[group.StatusID],
+			LatestAt:   group.LatestAt,
+			MostRecent: group.MostRecentID,
+		}
+		for _, id := range group.SampleAccountIDs {
+			if account, ok := accounts[id]; ok {
+				item.Accounts = append(item.Accounts, account)
+			}
+		}
+		groups = append(groups, item)
+	}
+	return groups
+}
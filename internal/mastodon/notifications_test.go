@@ -0,0 +1,20 @@
+package mastodon
+
+import "testing"
+
+func TestGroupNotifications(t *testing.T) {
+	groups := GroupNotifications([]Notification{
+		{ID: "3", Type: "favourite", Account: Account{ID: "1"}, Status: &Status{ID: "5"}},
+		{ID: "2", Type: "favourite", Account: Account{ID: "2"}, Status: &Status{ID: "5"}},
+		{ID: "1", Type: "mention", Account: Account{ID: "2"}, Status: &Status{ID: "4"}},
+	})
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", groups)
+	}
+	if groups[0].Count != 2 || len(groups[0].Accounts) != 2 || groups[0].MostRecent != "3" {
+		t.Fatalf("unexpected favourite group: %+v", groups[0])
+	}
+	if groups[1].Type != "mention" || groups[1].Count != 1 || groups[1].MostRecent != "1" {
+		t.Fatalf("unexpected mention group: %+v", groups[1])
+	}
+}
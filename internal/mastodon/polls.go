@@ -0,0 +1,24 @@
+package mastodon
+
+import "net/url"
+
+func (c *Client) GetPoll(id string) (*Poll, error) {
+	var poll Poll
+	if err := c.requestJSON("GET", "/api/v1/polls/"+url.PathEscape(id), nil, nil, &poll); err != nil {
+		return nil, err
+	}
+	return &poll, nil
+}
+
+// VotePoll submits zero-based choice indexes.
+func (c *Client) VotePoll(id string, choices []int) (*Poll, error) {
+	body := struct {
+		Choices []int `json:"choices"`
+	}{Choices: choices}
+
+	var poll Poll
+	if err := c.requestJSON("POST", "/api/v1/polls/"+url.PathEscape(id)+"/votes", nil, body, &poll); err != nil {
+		return nil, err
+	}
+	return &poll, nil
+}
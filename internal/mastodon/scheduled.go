@@ -0,0 +1,192 @@
+package mastodon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinScheduleLead is how far ahead the server requires scheduled_at to be.
+const MinScheduleLead = 5 * time.Minute
+
+type ScheduledPoll struct {
+	Options    []string    `json:"options"`
+	ExpiresIn  json.Number `json:"expires_in"`
+	Multiple   bool        `json:"multiple"`
+	HideTotals bool        `json:"hide_totals"`
+}
+
+// ScheduledParams are the status parameters stored with a scheduled status.
+type ScheduledParams struct {
+	Text        string         `json:"text"`
+	Visibility  string         `json:"visibility"`
+	SpoilerText string         `json:"spoiler_text"`
+	Sensitive   bool           `json:"sensitive"`
+	InReplyToID string         `json:"in_reply_to_id"`
+	Language    string         `json:"language"`
+	MediaIDs    []string       `json:"media_ids"`
+	Poll        *ScheduledPoll `json:"poll"`
+}
+
+type ScheduledStatus struct {
+	ID               string            `json:"id"`
+	ScheduledAt      string            `json:"scheduled_at"`
+	Params           ScheduledParams   `json:"params"`
+	MediaAttachments []MediaAttachment `json:"media_attachments"`
+}
+
+// Time returns ScheduledAt parsed, or the zero time when it is malformed.
+func (s ScheduledStatus) Time() time.Time {
+	at, err := time.Parse(time.RFC3339Nano, s.ScheduledAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return at
+}
+
+// StatusParams converts the stored parameters back into a request that
+// schedules the same status.
+func (s ScheduledStatus) StatusParams() StatusParams {
+	params := StatusParams{
+		Status:      s.Params.Text,
+		InReplyToID: s.Params.InReplyToID,
+		SpoilerText: s.Params.SpoilerText,
+		Visibility:  s.Params.Visibility,
+		Language:    s.Params.Language,
+		Sensitive:   s.Params.Sensitive,
+		MediaIDs:    s.Params.MediaIDs,
+		ScheduledAt: s.ScheduledAt,
+	}
+	if poll := s.Params.Poll; poll != nil {
+		expiresIn, _ := poll.ExpiresIn.Int64()
+		params.Poll = &PollParams{
+			Options:    poll.Options,
+			ExpiresIn:  int(expiresIn),
+			Multiple:   poll.Multiple,
+			HideTotals: poll.HideTotals,
+		}
+	}
+	return params
+}
+
+// ScheduleStatus creates a status that the server publishes at
+// params.ScheduledAt.
+func (c *Client) ScheduleStatus(params StatusParams) (*ScheduledStatus, error) {
+	if params.ScheduledAt == "" {
+		return nil, fmt.Errorf("schedule status: missing scheduled_at")
+	}
+	var scheduled ScheduledStatus
+	if err := c.requestJSON("POST", "/api/v1/statuses", nil, params, &scheduled); err != nil {
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+// ScheduledStatuses returns every pending scheduled status, soonest first.
+func (c *Client) ScheduledStatuses() ([]ScheduledStatus, error) {
+	const pageLimit = 40
+
+	var all []ScheduledStatus
+	maxID := ""
+	for {
+		query := url.Values{}
+		query.Set("limit", strconv.Itoa(pageLimit))
+		if maxID != "" {
+			query.Set("max_id", maxID)
+		}
+		var page []ScheduledStatus
+		header, err := c.requestJSONWithHeaders("GET", "/api/v1/scheduled_statuses", query, nil, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		maxID = nextMaxID(header)
+		if len(page) == 0 || maxID == "" {
+			break
+		}
+	}
+	sortScheduled(all)
+	return all, nil
+}
+
+func (c *Client) GetScheduledStatus(id string) (*ScheduledStatus, error) {
+	var scheduled ScheduledStatus
+	if err := c.requestJSON("GET", "/api/v1/scheduled_statuses/"+url.PathEscape(id), nil, nil, &scheduled); err != nil {
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+func (c *Client) RescheduleStatus(id string, at time.Time) (*ScheduledStatus, error) {
+	body := map[string]string{"scheduled_at": at.UTC().Format(time.RFC3339)}
+	var scheduled ScheduledStatus
+	if err := c.requestJSON("PUT", "/api/v1/scheduled_statuses/"+url.PathEscape(id), nil, body, &scheduled); err != nil {
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+func (c *Client) CancelScheduledStatus(id string) error {
+	return c.requestJSON("DELETE", "/api/v1/scheduled_statuses/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// ReplaceScheduledStatus changes the text of a scheduled status. The API
+// can only move scheduled_at, so the old status is cancelled and a new one
+// is scheduled for the same time; media attachments are released by the
+// cancel and reused. If scheduling fails the original is restored, which
+// the server only accepts while it is at least MinScheduleLead away, so
+// nothing is cancelled closer to the time.
+func (c *Client) ReplaceScheduledStatus(old ScheduledStatus, text string) (*ScheduledStatus, error) {
+	if time.Until(old.Time()) < MinScheduleLead {
+		return nil, fmt.Errorf("scheduled status %s is due in less than %d minutes and can no longer be edited", old.ID, int(MinScheduleLead.Minutes()))
+	}
+	params := old.StatusParams()
+	params.Status = text
+	if err := c.CancelScheduledStatus(old.ID); err != nil {
+		return nil, err
+	}
+	scheduled, err := c.ScheduleStatus(params)
+	if err != nil {
+		if _, restoreErr := c.ScheduleStatus(old.StatusParams()); restoreErr != nil {
+			return nil, fmt.Errorf("%w (restoring the original also failed: %v)", err, restoreErr)
+		}
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+func sortScheduled(statuses []ScheduledStatus) {
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Time().Before(statuses[j].Time())
+	})
+}
+
+// scheduleLayouts are the accepted forms of a local schedule time.
+var scheduleLayouts = []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02T15:04:05"}
+
+// ParseScheduleTime reads "2026-11-01 09:00" in loc, or an RFC 3339 time
+// with its own offset, and checks it is at least MinScheduleLead after now.
+func ParseScheduleTime(value string, loc *time.Location, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		parsed := false
+		for _, layout := range scheduleLayouts {
+			if at, err = time.ParseInLocation(layout, value, loc); err == nil {
+				parsed = true
+				break
+			}
+		}
+		if !parsed {
+			return time.Time{}, fmt.Errorf("invalid time %q (use YYYY-MM-DD HH:MM)", value)
+		}
+	}
+	if at.Before(now.Add(MinScheduleLead)) {
+		return time.Time{}, fmt.Errorf("scheduled time must be at least %d minutes from now", int(MinScheduleLead.Minutes()))
+	}
+	return at, nil
+}
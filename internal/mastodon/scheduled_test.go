@@ -0,0 +1,62 @@
+package mastodon
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplaceScheduledStatusRestoresOnRejection(t *testing.T) {
+	at := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "DELETE" && r.URL.Path == "/api/v1/scheduled_statuses/7":
+			requests = append(requests, "cancel")
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == "POST" && r.URL.Path == "/api/v1/statuses":
+			var params StatusParams
+			if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+				t.Fatal(err)
+			}
+			requests = append(requests, "schedule "+params.Status)
+			if params.Status == "new text" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(`{"error":"Validation failed: Text character limit of 500 exceeded"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":"8","scheduled_at":"` + params.ScheduledAt + `"}`))
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	old := ScheduledStatus{ID: "7", ScheduledAt: at, Params: ScheduledParams{Text: "old text", Visibility: "public"}}
+	_, err := client.ReplaceScheduledStatus(old, "new text")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected the 422 to be returned, got %v", err)
+	}
+	if got := strings.Join(requests, ", "); got != "cancel, schedule new text, schedule old text" {
+		t.Fatalf("unexpected requests: %s", got)
+	}
+}
+
+func TestReplaceScheduledStatusRefusesCloseToTheTime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "token")
+	old := ScheduledStatus{ID: "7", ScheduledAt: time.Now().Add(2 * time.Minute).UTC().Format(time.RFC3339)}
+	if _, err := client.ReplaceScheduledStatus(old, "new text"); err == nil {
+		t.Fatal("expected an error for a status due in two minutes")
+	}
+}
@@ -0,0 +1,41 @@
+package mastodon
+
+import "net/url"
+
+type PollParams struct {
+	Options   []string `json:"options"`
+	ExpiresIn int      `json:"expires_in"`
+	Multiple  bool     `json:"multiple,omitempty"`
+	// HideTotals is always sent: an edit replaces the whole poll.
+	HideTotals bool `json:"hide_totals"`
+}
+
+type StatusParams struct {
+	Status      string      `json:"status"`
+	InReplyToID string      `json:"in_reply_to_id,omitempty"`
+	SpoilerText string      `json:"spoiler_text,omitempty"`
+	Visibility  string      `json:"visibility,omitempty"`
+	Language    string      `json:"language,omitempty"`
+	Sensitive   bool        `json:"sensitive,omitempty"`
+	MediaIDs    []string    `json:"media_ids,omitempty"`
+	Poll        *PollParams `json:"poll,omitempty"`
+	// ScheduledAt, an RFC 3339 time, turns the request into a scheduled
+	// status; see ScheduleStatus.
+	ScheduledAt string `json:"scheduled_at,omitempty"`
+}
+
+func (c *Client) PostStatus(params StatusParams) (*Status, error) {
+	var status Status
+	if err := c.requestJSON("POST", "/api/v1/statuses", nil, params, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (c *Client) GetStatus(id string) (*Status, error) {
+	var status Status
+	if err := c.requestJSON("GET", "/api/v1/statuses/"+url.PathEscape(id), nil, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,120 @@
+package mastodon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// streamingClient has no timeout: a stream stays open for hours.
+var streamingClient = &http.Client{}
+
+// StreamNotifications follows the user's notification stream and calls
+// handle for each notification. It returns like stream.
+func (c *Client) StreamNotifications(ctx context.Context, handle func(Notification)) error {
+	return c.stream(ctx, "/api/v1/streaming/user/notification", nil, func(event string, data []byte) {
+		var notification Notification
+		if event == "notification" && json.Unmarshal(data, &notification) == nil {
+			handle(notification)
+		}
+	})
+}
+
+// StreamStatuses follows the new statuses of timeline and calls handle for
+// each. It returns like stream.
+func (c *Client) StreamStatuses(ctx context.Context, timeline Timeline, handle func(Status)) error {
+	path, query := "", url.Values{}
+	switch timeline.Kind {
+	case "home":
+		path = "/api/v1/streaming/user"
+	case "local":
+		path = "/api/v1/streaming/public/local"
+	case "federated":
+		path = "/api/v1/streaming/public"
+	case "hashtag":
+		path = "/api/v1/streaming/hashtag"
+		query.Set("tag", strings.TrimPrefix(timeline.Name, "#"))
+	case "list":
+		path = "/api/v1/streaming/list"
+		query.Set("list", timeline.Name)
+	default:
+		return fmt.Errorf("unknown timeline: %s", timeline.Kind)
+	}
+	return c.stream(ctx, path, query, func(event string, data []byte) {
+		var status Status
+		if event == "update" && json.Unmarshal(data, &status) == nil {
+			handle(status)
+		}
+	})
+}
+
+// stream reads server-sent events from the streaming API, on its own host
+// when the instance has one. It returns nil when ctx is done, and an error
+// when the stream fails or the server closes it.
+func (c *Client) stream(ctx context.Context, path string, query url.Values, handle func(event string, data []byte)) error {
+	base := c.baseURL
+	if caps, ok := c.Capabilities(); ok && caps.StreamingURL != "" {
+		base = strings.TrimRight(caps.StreamingURL, "/")
+		base = strings.Replace(base, "wss://", "https://", 1)
+		base = strings.Replace(base, "ws://", "http://", 1)
+	}
+	endpoint := base + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("build GET %s: %w", path, err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if c.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	}
+	resp, err := streamingClient.Do(req)
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return responseError("GET", path, resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	var event string
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event != "" {
+				handle(event, []byte(data.String()))
+			}
+			event = ""
+			data.Reset()
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// Lines starting with ":" are heartbeats.
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("GET %s: %w", path, err)
+	}
+	return fmt.Errorf("GET %s: stream closed by the server", path)
+}
@@ -0,0 +1,57 @@
+package mastodon
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Timeline names a status timeline to read or stream.
+type Timeline struct {
+	// Kind is "home", "local", "federated", "hashtag" or "list".
+	Kind string
+	// Name is the hashtag, without "#", or the list ID.
+	Name string
+}
+
+func (t Timeline) String() string {
+	if t.Name == "" {
+		return t.Kind
+	}
+	return t.Kind + ":" + t.Name
+}
+
+// TimelinePage reads statuses of timeline newer than sinceID and older
+// than maxID, newest first; both bounds are optional.
+func (c *Client) TimelinePage(timeline Timeline, limit int, sinceID, maxID string) ([]Status, error) {
+	var path string
+	switch timeline.Kind {
+	case "home":
+		return c.HomeTimelinePage(limit, sinceID, maxID)
+	case "local":
+		return c.PublicTimelinePage(limit, true, false, sinceID, maxID)
+	case "federated":
+		return c.PublicTimelinePage(limit, false, false, sinceID, maxID)
+	case "hashtag":
+		path = "/api/v1/timelines/tag/" + url.PathEscape(strings.TrimPrefix(timeline.Name, "#"))
+	case "list":
+		path = "/api/v1/timelines/list/" + url.PathEscape(timeline.Name)
+	default:
+		return nil, fmt.Errorf("unknown timeline: %s", timeline.Kind)
+	}
+
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if sinceID != "" {
+		query.Set("since_id", sinceID)
+	}
+	if maxID != "" {
+		query.Set("max_id", maxID)
+	}
+	var statuses []Status
+	if err := c.requestJSON("GET", path, query, nil, &statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
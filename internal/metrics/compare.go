@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+
+	"mastodoncli/internal/mastodon"
+)
+
+// anomalyLookback is the number of preceding buckets the rolling mean and
+// deviation are computed over.
+const anomalyLookback = 7
+
+// anomalySigma is how many standard deviations a bucket has to move away
+// from the rolling mean to be flagged.
+const anomalySigma = 2.0
+
+type Anomaly int
+
+const (
+	AnomalyNone Anomaly = iota
+	AnomalySpike
+	AnomalyDip
+)
+
+func (a Anomaly) Marker() string {
+	switch a {
+	case AnomalySpike:
+		return "▲"
+	case AnomalyDip:
+		return "▼"
+	default:
+		return ""
+	}
+}
+
+// Change is one metric of a period comparison.
+type Change struct {
+	Name     string
+	Current  int
+	Previous int
+}
+
+// Percent formats the relative change, e.g. "+25%", "new" or "n/a".
+func (c Change) Percent() string {
+	if c.Previous == 0 {
+		if c.Current == 0 {
+			return "n/a"
+		}
+		return "new"
+	}
+	return fmt.Sprintf("%+.0f%%", float64(c.Current-c.Previous)*100/float64(c.Previous))
+}
+
+// Comparison holds a window, the equal-length window before it and the
+// anomaly flags of the current buckets.
+type Comparison struct {
+	Current   []DailyMetric
+	Previous  []DailyMetric
+	Anomalies []Anomaly
+}
+
+// Changes compares every series plus lost followers.
+func (c Comparison) Changes() []Change {
+	changes := make([]Change, 0, len(AllSeries)+1)
+	for _, series := range AllSeries {
+		changes = append(changes, Change{
+			Name:     series.Name,
+			Current:  sumSeries(c.Current, series.Value),
+			Previous: sumSeries(c.Previous, series.Value),
+		})
+	}
+	lost := func(day DailyMetric) int { return day.Lost }
+	return append(changes, Change{Name: "Lost", Current: sumSeries(c.Current, lost), Previous: sumSeries(c.Previous, lost)})
+}
+
+func sumSeries(days []DailyMetric, value func(DailyMetric) int) int {
+	total := 0
+	for _, day := range days {
+		total += value(day)
+	}
+	return total
+}
+
+// Previous returns the window of the same length that ends the day before w
+// starts.
+func (w Window) Previous() Window {
+	days := w.Days()
+	end := w.Start.AddDate(0, 0, -1)
+	return Window{Start: end.AddDate(0, 0, -days+1), End: end, Bucket: w.Bucket}
+}
+
+// FetchComparison syncs both periods and flags anomalies in the current
+// one, using the previous period as history for the rolling mean.
+func FetchComparison(client *mastodon.Client, window Window, progress func(scanned int)) (Comparison, error) {
+	previous := window.Previous()
+	history, followers, err := syncAll(client, Window{Start: previous.Start, End: window.End, Bucket: window.Bucket}, progress)
+	if err != nil {
+		return Comparison{}, err
+	}
+
+	comparison := Comparison{
+		Current:  aggregate(window, history, followers),
+		Previous: aggregate(previous, history, followers),
+	}
+	combined := append(append([]DailyMetric{}, comparison.Previous...), comparison.Current...)
+	flags := Anomalies(combined)
+	comparison.Anomalies = flags[len(flags)-len(comparison.Current):]
+	return comparison, nil
+}
+
+// Anomalies flags buckets whose engagement (the total of the default
+// series) is more than anomalySigma standard deviations above or below the
+// mean of the preceding anomalyLookback buckets. Buckets with fewer than
+// three predecessors are never flagged.
+func Anomalies(series []DailyMetric) []Anomaly {
+	selected := DefaultSeries()
+	engagement := func(day DailyMetric) int { return Total(day, selected) }
+	flags := make([]Anomaly, len(series))
+	for i := range series {
+		start := i - anomalyLookback
+		if start < 0 {
+			start = 0
+		}
+		window := series[start:i]
+		if len(window) < 3 {
+			continue
+		}
+
+		var mean float64
+		for _, day := range window {
+			mean += float64(engagement(day))
+		}
+		mean /= float64(len(window))
+		var variance float64
+		for _, day := range window {
+			diff := float64(engagement(day)) - mean
+			variance += diff * diff
+		}
+		deviation := math.Sqrt(variance / float64(len(window)))
+		if deviation == 0 {
+			continue
+		}
+
+		value := float64(engagement(series[i]))
+		switch {
+		case value > mean+anomalySigma*deviation:
+			flags[i] = AnomalySpike
+		case value < mean-anomalySigma*deviation:
+			flags[i] = AnomalyDip
+		}
+	}
+	return flags
+}
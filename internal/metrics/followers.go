@@ -0,0 +1,169 @@
+package metrics
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"mastodoncli/internal/config"
+	"mastodoncli/internal/mastodon"
+)
+
+// FollowerSnapshotInterval is how old the last followers snapshot may get
+// before "metrics followers" takes a new one.
+const FollowerSnapshotInterval = 6 * time.Hour
+
+const (
+	FollowerGained = "gained"
+	FollowerLost   = "lost"
+)
+
+type FollowerEvent struct {
+	At   time.Time `json:"at"`
+	ID   string    `json:"id"`
+	Acct string    `json:"acct"`
+	Kind string    `json:"kind"`
+}
+
+// FollowerStore keeps the last followers list and the differences between
+// consecutive snapshots. The API does not notify about unfollows, so
+// comparing snapshots is the only way to see them.
+type FollowerStore struct {
+	AccountID    string            `json:"account_id"`
+	LastSnapshot time.Time         `json:"last_snapshot,omitempty"`
+	Current      map[string]string `json:"current"`
+	Events       []FollowerEvent   `json:"events"`
+}
+
+type FollowerReport struct {
+	Gained []FollowerEvent
+	Lost   []FollowerEvent
+}
+
+func (r FollowerReport) Net() int {
+	return len(r.Gained) - len(r.Lost)
+}
+
+func FollowerStorePath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "followers.json"), nil
+}
+
+func LoadFollowerStore() (*FollowerStore, error) {
+	path, err := FollowerStorePath()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &FollowerStore{}
+	if err := config.ReadJSON(path, "follower snapshots", store); err != nil {
+		return nil, err
+	}
+	if store.Current == nil {
+		store.Current = make(map[string]string)
+	}
+	return store, nil
+}
+
+func (s *FollowerStore) Save() error {
+	path, err := FollowerStorePath()
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, "follower snapshots", s)
+}
+
+// Stale reports whether a new snapshot is due.
+func (s *FollowerStore) Stale(now time.Time) bool {
+	return s.LastSnapshot.IsZero() || now.Sub(s.LastSnapshot) >= FollowerSnapshotInterval
+}
+
+// Apply compares followers (ID to acct) with the previous snapshot and
+// records who appeared and who disappeared. The first snapshot of an
+// account only sets the baseline.
+func (s *FollowerStore) Apply(accountID string, followers map[string]string, at time.Time) {
+	if s.AccountID != accountID {
+		*s = FollowerStore{AccountID: accountID}
+	}
+	if !s.LastSnapshot.IsZero() {
+		var events []FollowerEvent
+		for id, acct := range followers {
+			if _, ok := s.Current[id]; !ok {
+				events = append(events, FollowerEvent{At: at, ID: id, Acct: acct, Kind: FollowerGained})
+			}
+		}
+		for id, acct := range s.Current {
+			if _, ok := followers[id]; !ok {
+				events = append(events, FollowerEvent{At: at, ID: id, Acct: acct, Kind: FollowerLost})
+			}
+		}
+		sort.Slice(events, func(i, j int) bool { return events[i].Acct < events[j].Acct })
+		s.Events = append(s.Events, events...)
+	}
+	s.Current = followers
+	s.LastSnapshot = at
+}
+
+// Report returns the follower changes observed inside window.
+func (s *FollowerStore) Report(window Window) FollowerReport {
+	var report FollowerReport
+	for _, event := range s.Events {
+		if !window.Contains(truncateDay(event.At.In(time.Local))) {
+			continue
+		}
+		if event.Kind == FollowerLost {
+			report.Lost = append(report.Lost, event)
+		} else {
+			report.Gained = append(report.Gained, event)
+		}
+	}
+	return report
+}
+
+// SnapshotFollowers pages through the followers list and applies it to
+// store. force skips the staleness check.
+func SnapshotFollowers(client *mastodon.Client, store *FollowerStore, force bool) error {
+	now := time.Now()
+	if !force && !store.Stale(now) {
+		return nil
+	}
+	account, err := client.VerifyCredentials()
+	if err != nil {
+		return err
+	}
+
+	const pageLimit = 80
+	followers := make(map[string]string, account.FollowersCount)
+	var maxID string
+	for {
+		page, next, err := client.AccountFollowers(account.ID, pageLimit, maxID)
+		if err != nil {
+			return err
+		}
+		for _, follower := range page {
+			followers[follower.ID] = follower.Acct
+		}
+		if next == "" || len(page) == 0 {
+			break
+		}
+		maxID = next
+	}
+
+	store.Apply(account.ID, followers, now)
+	return nil
+}
+
+// AddFollowerEvents counts lost followers per bucket.
+func (a *Aggregator) AddFollowerEvents(store *FollowerStore) {
+	for _, event := range store.Events {
+		if event.Kind != FollowerLost {
+			continue
+		}
+		if metric := a.bucketAt(event.At.In(time.Local)); metric != nil {
+			metric.Lost++
+		}
+	}
+}
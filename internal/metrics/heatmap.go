@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+)
+
+// Weekdays labels heatmap rows, Monday first.
+var Weekdays = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// Heatmap counts events per weekday (rows, Monday first) and hour of day.
+type Heatmap struct {
+	Cells [7][24]int
+}
+
+func (h *Heatmap) add(at time.Time, count int) {
+	h.Cells[(int(at.Weekday())+6)%7][at.Hour()] += count
+}
+
+func (h Heatmap) Max() int {
+	maxValue := 0
+	for _, row := range h.Cells {
+		for _, value := range row {
+			if value > maxValue {
+				maxValue = value
+			}
+		}
+	}
+	return maxValue
+}
+
+func (h Heatmap) Total() int {
+	total := 0
+	for _, row := range h.Cells {
+		for _, value := range row {
+			total += value
+		}
+	}
+	return total
+}
+
+// Peak returns the busiest weekday and hour, e.g. "Tue 14:00".
+func (h Heatmap) Peak() string {
+	bestDay, bestHour, best := 0, 0, 0
+	for day, row := range h.Cells {
+		for hour, value := range row {
+			if value > best {
+				bestDay, bestHour, best = day, hour, value
+			}
+		}
+	}
+	if best == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%s %02d:00", Weekdays[bestDay], bestHour)
+}
+
+// EngagementHeatmap spreads the recorded notifications inside window over
+// weekdays and hours in loc.
+func EngagementHeatmap(history *History, window Window, loc *time.Location) Heatmap {
+	var heatmap Heatmap
+	for key, counts := range history.Hours {
+		at, err := time.Parse(hourKeyLayout, key)
+		if err != nil || !window.Contains(truncateDay(at.In(time.Local))) {
+			continue
+		}
+		for _, count := range counts {
+			heatmap.add(at.In(loc), count)
+		}
+	}
+	return heatmap
+}
+
+// PostingHeatmap shows when the posts were published, in loc.
+func PostingHeatmap(posts []PostStat, loc *time.Location) Heatmap {
+	var heatmap Heatmap
+	for _, post := range posts {
+		heatmap.add(post.CreatedAt.In(loc), 1)
+	}
+	return heatmap
+}
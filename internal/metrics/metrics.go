@@ -2,106 +2,143 @@ package metrics
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"mastodoncli/internal/mastodon"
 )
 
 type DailyMetric struct {
-	Date    time.Time
-	Label   string
-	Follows int
-	Likes   int
-	Boosts  int
+	Date     time.Time
+	Label    string
+	Follows  int
+	Likes    int
+	Boosts   int
+	Mentions int
+	Replies  int
+	Quotes   int
+	// FollowRequests, Polls and Edits count follow_request, poll (a poll
+	// you voted in or created ended) and update (a boosted status was
+	// edited) notifications.
+	FollowRequests int
+	Polls          int
+	Edits          int
+	// Lost counts followers that disappeared between snapshots.
+	Lost int
+	// Followers is the last follower count recorded in the bucket, or 0
+	// when none was.
+	Followers int
 }
 
 type Aggregator struct {
-	windowStart time.Time
-	windowEnd   time.Time
-	byDay       map[string]*DailyMetric
+	window Window
+	byDay  map[string]*DailyMetric
 }
 
+// NewAggregator covers the last days calendar days up to now, bucketed by day.
 func NewAggregator(days int, now time.Time) *Aggregator {
-	if days < 1 {
-		days = 1
+	return NewWindowAggregator(LastDays(days, now, BucketDay))
+}
+
+func NewWindowAggregator(window Window) *Aggregator {
+	if window.Bucket == "" {
+		window.Bucket = BucketDay
 	}
-	end := truncateDay(now)
-	start := end.AddDate(0, 0, -days+1)
 	return &Aggregator{
-		windowStart: start,
-		windowEnd:   end,
-		byDay:       make(map[string]*DailyMetric, days),
+		window: window,
+		byDay:  make(map[string]*DailyMetric),
 	}
 }
 
 func (a *Aggregator) WindowStart() time.Time {
-	return a.windowStart
+	return a.window.Start
 }
 
 func (a *Aggregator) Add(notifications []mastodon.Notification) {
 	for _, notification := range notifications {
-		day := parseDay(notification.CreatedAt)
-		if day.IsZero() {
-			continue
-		}
-		if day.Before(a.windowStart) || day.After(a.windowEnd) {
+		metric := a.bucketFor(notification.CreatedAt)
+		if metric == nil {
 			continue
 		}
-		key := day.Format("2006-01-02")
-		metric, ok := a.byDay[key]
-		if !ok {
-			metric = &DailyMetric{Date: day, Label: day.Format("Jan 2")}
-			a.byDay[key] = metric
-		}
-		switch notification.Type {
-		case "follow":
-			metric.Follows++
-		case "favourite":
-			metric.Likes++
-		case "reblog":
-			metric.Boosts++
+		kind := notification.Type
+		if kind == KindMention && notification.Status != nil && notification.Status.InReplyToID != "" {
+			kind = KindReply
 		}
+		addKind(metric, kind, 1)
 	}
 }
 
 func (a *Aggregator) AddGrouped(groups []mastodon.GroupedNotification) {
 	for _, group := range groups {
-		if group.LatestAt == "" {
+		metric := a.bucketFor(group.LatestAt)
+		if metric == nil {
 			continue
 		}
-		day := parseDay(group.LatestAt)
-		if day.IsZero() {
-			continue
-		}
-		if day.Before(a.windowStart) || day.After(a.windowEnd) {
-			continue
-		}
-		key := day.Format("2006-01-02")
-		metric, ok := a.byDay[key]
-		if !ok {
-			metric = &DailyMetric{Date: day, Label: day.Format("Jan 2")}
-			a.byDay[key] = metric
+		kind := group.Type
+		if kind == KindMention && group.Status != nil && group.Status.InReplyToID != "" {
+			kind = KindReply
 		}
+		addKind(metric, kind, group.Count)
+	}
+}
 
-		switch group.Type {
-		case "follow":
-			metric.Follows += group.Count
-		case "favourite":
-			metric.Likes += group.Count
-		case "reblog":
-			metric.Boosts += group.Count
-		}
+func addKind(metric *DailyMetric, kind string, count int) {
+	switch kind {
+	case KindFollow:
+		metric.Follows += count
+	case KindFavourite:
+		metric.Likes += count
+	case KindReblog:
+		metric.Boosts += count
+	case KindMention:
+		metric.Mentions += count
+	case KindReply:
+		metric.Replies += count
+	case KindQuote:
+		metric.Quotes += count
+	case KindFollowRequest:
+		metric.FollowRequests += count
+	case KindPoll:
+		metric.Polls += count
+	case KindUpdate:
+		metric.Edits += count
+	}
+}
+
+func (a *Aggregator) bucketFor(timestamp string) *DailyMetric {
+	day := parseDay(timestamp)
+	if day.IsZero() {
+		return nil
+	}
+	return a.bucketAt(day)
+}
+
+// bucketAt returns the metric for the bucket containing at, or nil when it
+// falls outside the window.
+func (a *Aggregator) bucketAt(at time.Time) *DailyMetric {
+	day := truncateDay(at)
+	if !a.window.Contains(day) {
+		return nil
+	}
+	start := a.window.Bucket.Start(day)
+	key := start.Format("2006-01-02")
+	metric, ok := a.byDay[key]
+	if !ok {
+		metric = &DailyMetric{Date: start, Label: a.window.Bucket.Label(start)}
+		a.byDay[key] = metric
 	}
+	return metric
 }
 
 func (a *Aggregator) Series() []DailyMetric {
 	var series []DailyMetric
-	for day := a.windowStart; !day.After(a.windowEnd); day = day.AddDate(0, 0, 1) {
+	bucket := a.window.Bucket
+	for day := bucket.Start(a.window.Start); !day.After(a.window.End); day = bucket.Next(day) {
 		key := day.Format("2006-01-02")
 		if metric, ok := a.byDay[key]; ok {
 			series = append(series, *metric)
 		} else {
-			series = append(series, DailyMetric{Date: day, Label: day.Format("Jan 2")})
+			series = append(series, DailyMetric{Date: day, Label: bucket.Label(day)})
 		}
 	}
 	return series
@@ -125,43 +162,87 @@ func truncateDay(value time.Time) time.Time {
 	return time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, value.Location())
 }
 
-func FormatTotal(series []DailyMetric) string {
-	var follows, likes, boosts int
+// LabelWidth returns the widest label in series, at least 6 columns.
+func LabelWidth(series []DailyMetric) int {
+	width := 6
 	for _, day := range series {
-		follows += day.Follows
-		likes += day.Likes
-		boosts += day.Boosts
+		if len(day.Label) > width {
+			width = len(day.Label)
+		}
 	}
-	return fmt.Sprintf("Follows %d · Likes %d · Boosts %d", follows, likes, boosts)
+	return width
+}
+
+// FormatTotal sums the selected series over all buckets, e.g.
+// "Follows 3 · Likes 10 · Boosts 2".
+func FormatTotal(days []DailyMetric, selected []Series) string {
+	parts := make([]string, 0, len(selected)+1)
+	for _, series := range selected {
+		total := 0
+		for _, day := range days {
+			total += series.Value(day)
+		}
+		parts = append(parts, fmt.Sprintf("%s %d", series.Name, total))
+	}
+	lost := 0
+	for _, day := range days {
+		lost += day.Lost
+	}
+	if lost > 0 {
+		parts = append(parts, fmt.Sprintf("Lost %d", lost))
+	}
+	return strings.Join(parts, " · ")
 }
 
 func FetchDailyMetrics(client *mastodon.Client, days int, progress func(scanned int)) ([]DailyMetric, error) {
-	agg := NewAggregator(days, time.Now())
-	const pageLimit = 40
+	return FetchMetrics(client, LastDays(days, time.Now(), BucketDay), progress)
+}
 
-	var maxID string
-	scanned := 0
-	for {
-		page, err := client.GroupedNotificationsPage(pageLimit, maxID)
-		if err != nil {
-			return nil, err
-		}
-		if len(page) == 0 {
-			break
-		}
+// SyncHistory loads the local history, brings it up to date for window and
+// saves it.
+func SyncHistory(client *mastodon.Client, window Window, progress func(scanned int)) (*History, error) {
+	history, err := LoadHistory()
+	if err != nil {
+		return nil, err
+	}
+	if err := Sync(client, history, window.Start, progress); err != nil {
+		return nil, err
+	}
+	if err := history.Save(); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
 
-		agg.AddGrouped(page)
-		scanned += len(page)
-		if progress != nil {
-			progress(scanned)
-		}
+// FetchMetrics syncs the local history with the server and aggregates the
+// window from it, so only notifications newer than the last sync (or older
+// than anything recorded) are fetched. Lost followers come from the
+// snapshots taken by SnapshotFollowers; paging the whole followers list is
+// too costly to do on every load.
+func FetchMetrics(client *mastodon.Client, window Window, progress func(scanned int)) ([]DailyMetric, error) {
+	history, followers, err := syncAll(client, window, progress)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate(window, history, followers), nil
+}
 
-		oldest := parseDay(page[len(page)-1].LatestAt)
-		if !oldest.IsZero() && oldest.Before(agg.WindowStart()) {
-			break
-		}
-		maxID = page[len(page)-1].MostRecent
+func syncAll(client *mastodon.Client, window Window, progress func(scanned int)) (*History, *FollowerStore, error) {
+	history, err := SyncHistory(client, window, progress)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return agg.Series(), nil
+	followers, err := LoadFollowerStore()
+	if err != nil {
+		return nil, nil, err
+	}
+	return history, followers, nil
+}
+
+func aggregate(window Window, history *History, followers *FollowerStore) []DailyMetric {
+	agg := NewWindowAggregator(window)
+	agg.AddHistory(history)
+	agg.AddFollowerEvents(followers)
+	return agg.Series()
 }
@@ -36,3 +36,262 @@ func TestAggregatorSeries(t *testing.T) {
 		t.Fatalf("expected zeros for day 1: %+v", series[0])
 	}
 }
+
+func TestAggregatorWeekAndMonthBuckets(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(2025, 2, 14, 0, 0, 0, 0, time.Local)
+	groups := []mastodon.GroupedNotification{
+		{Type: "favourite", LatestAt: "2025-01-01T12:00:00Z", Count: 1},
+		{Type: "favourite", LatestAt: "2025-01-05T12:00:00Z", Count: 2},
+		{Type: "follow", LatestAt: "2025-01-06T12:00:00Z", Count: 4},
+		{Type: "reblog", LatestAt: "2025-02-10T12:00:00Z", Count: 8},
+		{Type: "reblog", LatestAt: "2025-03-01T12:00:00Z", Count: 16},
+	}
+
+	weekly := NewWindowAggregator(Window{Start: start, End: end, Bucket: BucketWeek})
+	weekly.AddGrouped(groups)
+	weeks := weekly.Series()
+	if len(weeks) != 7 {
+		t.Fatalf("expected 7 weeks, got %d", len(weeks))
+	}
+	if !weeks[0].Date.Equal(time.Date(2024, 12, 30, 0, 0, 0, 0, time.Local)) || weeks[0].Likes != 3 {
+		t.Fatalf("unexpected first week: %+v", weeks[0])
+	}
+	if weeks[1].Follows != 4 || weeks[6].Boosts != 8 {
+		t.Fatalf("unexpected weeks: %+v", weeks)
+	}
+
+	monthly := NewWindowAggregator(Window{Start: start, End: end, Bucket: BucketMonth})
+	monthly.AddGrouped(groups)
+	months := monthly.Series()
+	if len(months) != 2 {
+		t.Fatalf("expected 2 months, got %d", len(months))
+	}
+	if months[0].Label != "Jan 2025" || months[0].Likes != 3 || months[0].Follows != 4 || months[1].Boosts != 8 {
+		t.Fatalf("unexpected months: %+v", months)
+	}
+}
+
+func TestNewWindow(t *testing.T) {
+	now := time.Date(2026, 4, 10, 15, 0, 0, 0, time.Local)
+
+	window, err := NewWindow(90, "", "", BucketDay, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if window.Days() != 90 || window.Describe(now) != "90d" {
+		t.Fatalf("unexpected window: %+v", window)
+	}
+
+	window, err = NewWindow(7, "2026-01-01", "2026-03-31", BucketWeek, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if window.Days() != 90 || window.Describe(now) != "2026-01-01..2026-03-31, week" {
+		t.Fatalf("unexpected window: %+v", window)
+	}
+
+	if _, err := NewWindow(7, "2026-04-01", "2026-03-01", BucketDay, now); err == nil {
+		t.Fatal("expected error when since is after until")
+	}
+	if _, err := NewWindow(7, "01/02/2026", "", BucketDay, now); err == nil {
+		t.Fatal("expected error for malformed date")
+	}
+}
+
+func TestPostStatsUsesSnapshotsAndBreakdowns(t *testing.T) {
+	posted := time.Date(2026, 3, 2, 9, 15, 0, 0, time.Local)
+	statuses := []mastodon.Status{
+		{ID: "2", CreatedAt: posted.Format(time.RFC3339), FavouritesCount: 10, ReblogsCount: 2, RepliesCount: 1, Tags: []mastodon.Tag{{Name: "Go"}}},
+		{ID: "1", CreatedAt: posted.Add(-time.Hour).Format(time.RFC3339), FavouritesCount: 1, Tags: []mastodon.Tag{{Name: "go"}}},
+		{ID: "0", CreatedAt: posted.AddDate(0, 0, -10).Format(time.RFC3339), FavouritesCount: 50},
+	}
+	snapshots := &PostSnapshots{Posts: make(map[string]*postHistory)}
+	snapshots.Record(statuses[:1], posted.Add(30*time.Minute))
+	snapshots.Posts["2"].Snapshots[0].Favourites = 4
+	snapshots.Record(statuses[:1], posted.Add(5*time.Hour))
+
+	window := LastDays(7, posted, BucketDay)
+	posts := PostStats(statuses, snapshots, window)
+	if len(posts) != 2 || posts[0].ID != "2" {
+		t.Fatalf("unexpected ranking: %+v", posts)
+	}
+	if posts[0].Score() != 10+2*2+3 {
+		t.Fatalf("unexpected score: %d", posts[0].Score())
+	}
+	if posts[0].FirstHour == nil || posts[0].FirstHour.Favourites != 4 {
+		t.Fatalf("unexpected first hour: %+v", posts[0].FirstHour)
+	}
+	if posts[0].FirstDay == nil || posts[0].FirstDay.Favourites != 10 {
+		t.Fatalf("unexpected first day: %+v", posts[0].FirstDay)
+	}
+	if posts[1].FirstHour != nil {
+		t.Fatalf("expected no snapshot for post 1")
+	}
+
+	report := BuildPostsReport(posts)
+	if len(report.Hashtags) != 1 || report.Hashtags[0].Key != "#go" || report.Hashtags[0].Posts != 2 {
+		t.Fatalf("unexpected hashtags: %+v", report.Hashtags)
+	}
+	if len(report.Hours) != 2 || report.Hours[0].Key != "08:00" || report.Hours[1].Key != "09:00" {
+		t.Fatalf("unexpected hours: %+v", report.Hours)
+	}
+}
+
+func TestFollowerStoreTracksChurn(t *testing.T) {
+	first := time.Date(2026, 5, 1, 9, 0, 0, 0, time.Local)
+	second := first.Add(30 * time.Hour)
+	store := &FollowerStore{}
+
+	store.Apply("1", map[string]string{"a": "alice", "b": "bob"}, first)
+	if len(store.Events) != 0 {
+		t.Fatalf("expected baseline without events, got %+v", store.Events)
+	}
+	store.Apply("1", map[string]string{"a": "alice", "c": "carol"}, second)
+
+	window := LastDays(7, second, BucketDay)
+	report := store.Report(window)
+	if len(report.Gained) != 1 || report.Gained[0].Acct != "carol" {
+		t.Fatalf("unexpected gained: %+v", report.Gained)
+	}
+	if len(report.Lost) != 1 || report.Lost[0].Acct != "bob" || report.Net() != 0 {
+		t.Fatalf("unexpected lost: %+v", report.Lost)
+	}
+
+	agg := NewWindowAggregator(window)
+	agg.AddFollowerEvents(store)
+	series := agg.Series()
+	if series[len(series)-1].Lost != 1 {
+		t.Fatalf("expected churn on the last day: %+v", series[len(series)-1])
+	}
+}
+
+func TestEngagementHeatmapUsesTimezone(t *testing.T) {
+	history := &History{}
+	history.init()
+	// Monday 2026-03-02 23:xx UTC is Tuesday 08:xx in Tokyo.
+	history.Record(mastodon.Notification{ID: "1", Type: "favourite", CreatedAt: "2026-03-02T23:10:00Z"})
+	history.Record(mastodon.Notification{ID: "2", Type: "reblog", CreatedAt: "2026-03-02T23:40:00Z"})
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+	window := Window{Start: time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local), End: time.Date(2026, 3, 31, 0, 0, 0, 0, time.Local)}
+
+	heatmap := EngagementHeatmap(history, window, tokyo)
+	if heatmap.Cells[1][8] != 2 || heatmap.Total() != 2 || heatmap.Peak() != "Tue 08:00" {
+		t.Fatalf("unexpected heatmap: total %d peak %s", heatmap.Total(), heatmap.Peak())
+	}
+	if utc := EngagementHeatmap(history, window, time.UTC); utc.Cells[0][23] != 2 {
+		t.Fatalf("expected Monday 23:00 in UTC")
+	}
+}
+
+func TestAnomaliesAndComparison(t *testing.T) {
+	values := []int{10, 12, 9, 11, 10, 11, 10, 60, 10, 11, 9, 10, 12, 11, 10, 0}
+	series := make([]DailyMetric, len(values))
+	for i, value := range values {
+		series[i] = DailyMetric{Likes: value}
+	}
+	flags := Anomalies(series)
+	for i, flag := range flags {
+		want := AnomalyNone
+		switch i {
+		case 7:
+			want = AnomalySpike
+		case 15:
+			want = AnomalyDip
+		}
+		if flag != want {
+			t.Fatalf("day %d: expected %v, got %v", i, want, flag)
+		}
+	}
+
+	window := Window{Start: time.Date(2026, 3, 8, 0, 0, 0, 0, time.Local), End: time.Date(2026, 3, 14, 0, 0, 0, 0, time.Local)}
+	previous := window.Previous()
+	if !previous.Start.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)) || !previous.End.Equal(time.Date(2026, 3, 7, 0, 0, 0, 0, time.Local)) {
+		t.Fatalf("unexpected previous window: %+v", previous)
+	}
+
+	comparison := Comparison{
+		Current:  []DailyMetric{{Likes: 15, Follows: 2}},
+		Previous: []DailyMetric{{Likes: 10}},
+	}
+	changes := comparison.Changes()
+	if changes[1].Percent() != "+50%" || changes[0].Percent() != "new" || changes[2].Percent() != "n/a" {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestAggregatorCountsAllSeries(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	at := now.Format(time.RFC3339)
+	agg := NewAggregator(1, now)
+	agg.Add([]mastodon.Notification{
+		{Type: "quote", CreatedAt: at},
+		{Type: "follow_request", CreatedAt: at},
+		{Type: "poll", CreatedAt: at},
+		{Type: "update", CreatedAt: at},
+		{Type: "mention", CreatedAt: at, Status: &mastodon.Status{InReplyToID: "1"}},
+	})
+	day := agg.Series()[0]
+	if day.Quotes != 1 || day.FollowRequests != 1 || day.Polls != 1 || day.Edits != 1 || day.Replies != 1 || day.Mentions != 0 {
+		t.Fatalf("unexpected counts: %+v", day)
+	}
+
+	selected, err := ParseSeries("quotes, edits")
+	if err != nil {
+		t.Fatalf("parse series: %v", err)
+	}
+	if Total(day, selected) != 2 || FormatTotal([]DailyMetric{day}, selected) != "Quotes 1 · Edits 1" {
+		t.Fatalf("unexpected totals for %+v", selected)
+	}
+	if all, _ := ParseSeries("all"); len(all) != len(AllSeries) {
+		t.Fatalf("expected all series, got %d", len(all))
+	}
+	if _, err := ParseSeries("views"); err == nil {
+		t.Fatal("expected unknown series error")
+	}
+}
+
+func TestPostSnapshotsCompactResolvedHistories(t *testing.T) {
+	posted := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	status := mastodon.Status{ID: "1", CreatedAt: posted.Format(time.RFC3339)}
+	snapshots := &PostSnapshots{Posts: map[string]*postHistory{}}
+	for i, after := range []time.Duration{10 * time.Minute, 50 * time.Minute, 3 * time.Hour, 20 * time.Hour} {
+		status.FavouritesCount = i + 1
+		snapshots.Record([]mastodon.Status{status}, posted.Add(after))
+	}
+	if got := len(snapshots.Posts["1"].Snapshots); got != 4 {
+		t.Fatalf("expected 4 snapshots within the first day, got %d", got)
+	}
+
+	snapshots.Record(nil, posted.Add(30*time.Hour))
+	history := snapshots.Posts["1"].Snapshots
+	if len(history) != 2 || history[0].Favourites != 2 || history[1].Favourites != 4 {
+		t.Fatalf("unexpected compacted snapshots: %+v", history)
+	}
+	if hour := snapshots.within("1", time.Hour); hour == nil || hour.Favourites != 2 {
+		t.Fatalf("unexpected first hour: %+v", hour)
+	}
+}
+
+func TestPostSnapshotsRememberPostsForOfflineReports(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	snapshots := &PostSnapshots{Posts: map[string]*postHistory{}, Known: map[string]*knownPost{}}
+	snapshots.Remember([]PostStat{
+		{ID: "1", CreatedAt: now.Add(-time.Hour), Engagement: Engagement{Favourites: 1}},
+		{ID: "2", CreatedAt: now.Add(-2 * time.Hour), Engagement: Engagement{Reblogs: 2}},
+		{ID: "3", CreatedAt: now.AddDate(0, 0, -10), Engagement: Engagement{Replies: 5}},
+		{ID: "4", CreatedAt: now.AddDate(0, 0, -MaxDays-1)},
+	}, now)
+
+	posts := snapshots.Stats(LastDays(7, now, BucketDay))
+	if len(posts) != 2 || posts[0].ID != "2" || posts[1].ID != "1" {
+		t.Fatalf("unexpected posts: %+v", posts)
+	}
+	if _, ok := snapshots.Known["4"]; ok || len(snapshots.Known) != 3 {
+		t.Fatalf("expected posts older than MaxDays to be forgotten: %v", snapshots.Known)
+	}
+}
@@ -0,0 +1,385 @@
+package metrics
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"mastodoncli/internal/config"
+	"mastodoncli/internal/mastodon"
+)
+
+// Score weights: a reply costs the reader more than a boost, a boost more
+// than a favourite.
+const (
+	favouriteWeight = 1
+	reblogWeight    = 2
+	replyWeight     = 3
+)
+
+// snapshotHorizon is how long after posting snapshots are still recorded;
+// later counts only matter as current totals.
+const snapshotHorizon = 24 * time.Hour
+
+type Engagement struct {
+	Favourites int `json:"favourites"`
+	Reblogs    int `json:"reblogs"`
+	Replies    int `json:"replies"`
+}
+
+func (e Engagement) Score() int {
+	return e.Favourites*favouriteWeight + e.Reblogs*reblogWeight + e.Replies*replyWeight
+}
+
+type PostStat struct {
+	ID        string
+	URL       string
+	CreatedAt time.Time
+	Content   string
+	Tags      []string
+	Engagement
+	// FirstHour and FirstDay are the counts from the last snapshot taken
+	// within 1h and 24h of posting, or nil when none was recorded.
+	FirstHour *Engagement
+	FirstDay  *Engagement
+}
+
+type Breakdown struct {
+	Key   string
+	Posts int
+	Score int
+}
+
+func (b Breakdown) Average() float64 {
+	if b.Posts == 0 {
+		return 0
+	}
+	return float64(b.Score) / float64(b.Posts)
+}
+
+type PostsReport struct {
+	Posts    []PostStat
+	Hashtags []Breakdown
+	Hours    []Breakdown
+}
+
+type snapshot struct {
+	At time.Time `json:"at"`
+	Engagement
+}
+
+type postHistory struct {
+	CreatedAt time.Time  `json:"created_at"`
+	Snapshots []snapshot `json:"snapshots"`
+}
+
+// knownPost is the last fetched state of a post, kept so reports can be
+// built without the server.
+type knownPost struct {
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+	Content   string    `json:"content"`
+	Tags      []string  `json:"tags,omitempty"`
+	Engagement
+}
+
+// PostSnapshots records engagement counts of recent posts so the first
+// hour and first day can be reported later.
+type PostSnapshots struct {
+	Posts map[string]*postHistory `json:"posts"`
+	Known map[string]*knownPost   `json:"known,omitempty"`
+}
+
+func PostSnapshotsPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "posts.json"), nil
+}
+
+func LoadPostSnapshots() (*PostSnapshots, error) {
+	path, err := PostSnapshotsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := &PostSnapshots{}
+	if err := config.ReadJSON(path, "post snapshots", snapshots); err != nil {
+		return nil, err
+	}
+	if snapshots.Posts == nil {
+		snapshots.Posts = make(map[string]*postHistory)
+	}
+	if snapshots.Known == nil {
+		snapshots.Known = make(map[string]*knownPost)
+	}
+	return snapshots, nil
+}
+
+func (s *PostSnapshots) Save() error {
+	path, err := PostSnapshotsPath()
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, "post snapshots", s)
+}
+
+// Record stores the counts of statuses younger than a day, and trims the
+// histories of older posts down to the snapshots their reports still use.
+func (s *PostSnapshots) Record(statuses []mastodon.Status, now time.Time) {
+	for _, status := range statuses {
+		created, err := parseTimestamp(status.CreatedAt)
+		if err != nil || now.Sub(created) > snapshotHorizon {
+			continue
+		}
+		history, ok := s.Posts[status.ID]
+		if !ok {
+			history = &postHistory{CreatedAt: created}
+			s.Posts[status.ID] = history
+		}
+		history.Snapshots = append(history.Snapshots, snapshot{At: now, Engagement: engagementOf(status)})
+	}
+	for _, history := range s.Posts {
+		if now.Sub(history.CreatedAt) > snapshotHorizon {
+			history.compact()
+		}
+	}
+}
+
+// compact keeps only the snapshots behind the first-hour and first-day
+// values; no later snapshot can change them.
+func (h *postHistory) compact() {
+	hour, day := -1, -1
+	for i, snap := range h.Snapshots {
+		if snap.At.Sub(h.CreatedAt) <= time.Hour {
+			hour = i
+		}
+		if snap.At.Sub(h.CreatedAt) <= snapshotHorizon {
+			day = i
+		}
+	}
+	var kept []snapshot
+	for i, snap := range h.Snapshots {
+		if i == hour || i == day {
+			kept = append(kept, snap)
+		}
+	}
+	h.Snapshots = kept
+}
+
+// Remember keeps the latest counts and text of every fetched post, and
+// forgets posts older than the longest range a report can cover.
+func (s *PostSnapshots) Remember(posts []PostStat, now time.Time) {
+	for _, post := range posts {
+		s.Known[post.ID] = &knownPost{
+			URL:        post.URL,
+			CreatedAt:  post.CreatedAt.UTC(),
+			Content:    post.Content,
+			Tags:       post.Tags,
+			Engagement: post.Engagement,
+		}
+	}
+	oldest := now.AddDate(0, 0, -MaxDays)
+	for id, known := range s.Known {
+		if known.CreatedAt.Before(oldest) {
+			delete(s.Known, id)
+			delete(s.Posts, id)
+		}
+	}
+}
+
+// Stats returns the remembered posts inside window, ranked by score.
+func (s *PostSnapshots) Stats(window Window) []PostStat {
+	var posts []PostStat
+	for id, known := range s.Known {
+		created := known.CreatedAt.In(time.Local)
+		if !window.Contains(truncateDay(created)) {
+			continue
+		}
+		posts = append(posts, PostStat{
+			ID:         id,
+			URL:        known.URL,
+			CreatedAt:  created,
+			Content:    known.Content,
+			Tags:       known.Tags,
+			Engagement: known.Engagement,
+			FirstHour:  s.within(id, time.Hour),
+			FirstDay:   s.within(id, snapshotHorizon),
+		})
+	}
+	SortPosts(posts, "score")
+	return posts
+}
+
+// within returns the last snapshot taken no later than d after posting.
+func (s *PostSnapshots) within(id string, d time.Duration) *Engagement {
+	history, ok := s.Posts[id]
+	if !ok {
+		return nil
+	}
+	var found *Engagement
+	for i := range history.Snapshots {
+		if history.Snapshots[i].At.Sub(history.CreatedAt) <= d {
+			found = &history.Snapshots[i].Engagement
+		}
+	}
+	return found
+}
+
+func engagementOf(status mastodon.Status) Engagement {
+	return Engagement{
+		Favourites: status.FavouritesCount,
+		Reblogs:    status.ReblogsCount,
+		Replies:    status.RepliesCount,
+	}
+}
+
+// FetchPosts returns stats for the user's own statuses (boosts excluded)
+// posted inside window, and records snapshots for the recent ones.
+func FetchPosts(client *mastodon.Client, window Window) ([]PostStat, error) {
+	account, err := client.VerifyCredentials()
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := LoadPostSnapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	const pageLimit = 40
+	var statuses []mastodon.Status
+	var maxID string
+	for {
+		page, err := client.AccountStatuses(account.ID, pageLimit, false, true, maxID)
+		if err != nil {
+			return nil, err
+		}
+		if len(page) == 0 {
+			break
+		}
+		statuses = append(statuses, page...)
+		maxID = page[len(page)-1].ID
+
+		oldest := parseDay(page[len(page)-1].CreatedAt)
+		if !oldest.IsZero() && oldest.Before(window.Start) {
+			break
+		}
+	}
+
+	snapshots.Record(statuses, time.Now())
+	posts := PostStats(statuses, snapshots, window)
+	snapshots.Remember(posts, time.Now())
+	if err := snapshots.Save(); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// PostStats converts the statuses inside window, ranked by score.
+func PostStats(statuses []mastodon.Status, snapshots *PostSnapshots, window Window) []PostStat {
+	var posts []PostStat
+	for _, status := range statuses {
+		if status.Reblog != nil {
+			continue
+		}
+		created, err := parseTimestamp(status.CreatedAt)
+		if err != nil || !window.Contains(truncateDay(created.In(time.Local))) {
+			continue
+		}
+		post := PostStat{
+			ID:         status.ID,
+			URL:        status.URL,
+			CreatedAt:  created.In(time.Local),
+			Content:    status.Content,
+			Engagement: engagementOf(status),
+		}
+		for _, tag := range status.Tags {
+			post.Tags = append(post.Tags, strings.ToLower(tag.Name))
+		}
+		if snapshots != nil {
+			post.FirstHour = snapshots.within(status.ID, time.Hour)
+			post.FirstDay = snapshots.within(status.ID, snapshotHorizon)
+		}
+		posts = append(posts, post)
+	}
+	SortPosts(posts, "score")
+	return posts
+}
+
+// SortPosts orders posts by the given key (score, favourites, reblogs or
+// replies), highest first, newest first on ties.
+func SortPosts(posts []PostStat, by string) {
+	value := func(post PostStat) int {
+		switch by {
+		case "favourites":
+			return post.Favourites
+		case "reblogs":
+			return post.Reblogs
+		case "replies":
+			return post.Replies
+		default:
+			return post.Score()
+		}
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		if value(posts[i]) != value(posts[j]) {
+			return value(posts[i]) > value(posts[j])
+		}
+		return posts[i].CreatedAt.After(posts[j].CreatedAt)
+	})
+}
+
+// BuildPostsReport adds the hashtag and posting-hour breakdowns.
+func BuildPostsReport(posts []PostStat) PostsReport {
+	report := PostsReport{Posts: posts}
+
+	tags := make(map[string]*Breakdown)
+	hours := make([]Breakdown, 24)
+	for i := range hours {
+		hours[i].Key = fmt.Sprintf("%02d:00", i)
+	}
+	for _, post := range posts {
+		for _, tag := range post.Tags {
+			entry, ok := tags[tag]
+			if !ok {
+				entry = &Breakdown{Key: "#" + tag}
+				tags[tag] = entry
+			}
+			entry.Posts++
+			entry.Score += post.Score()
+		}
+		hour := &hours[post.CreatedAt.Hour()]
+		hour.Posts++
+		hour.Score += post.Score()
+	}
+
+	for _, entry := range tags {
+		report.Hashtags = append(report.Hashtags, *entry)
+	}
+	sort.Slice(report.Hashtags, func(i, j int) bool {
+		if report.Hashtags[i].Score != report.Hashtags[j].Score {
+			return report.Hashtags[i].Score > report.Hashtags[j].Score
+		}
+		return report.Hashtags[i].Key < report.Hashtags[j].Key
+	})
+	for _, hour := range hours {
+		if hour.Posts > 0 {
+			report.Hours = append(report.Hours, hour)
+		}
+	}
+	return report
+}
+
+// PostsBetween returns the posts created in [start, end).
+func PostsBetween(posts []PostStat, start, end time.Time) []PostStat {
+	var matched []PostStat
+	for _, post := range posts {
+		if !post.CreatedAt.Before(start) && post.CreatedAt.Before(end) {
+			matched = append(matched, post)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import "time"
+
+// Report is what an export covers for one window.
+type Report struct {
+	Window     Window
+	Generated  time.Time
+	Series     []DailyMetric
+	Anomalies  []Anomaly
+	Engagement Heatmap
+	Posting    Heatmap
+	Posts      []PostStat
+	Followers  FollowerReport
+}
+
+// LoadReport builds a report from the local history, followers and post
+// stores only; nothing is fetched, so it shows whatever earlier runs
+// collected.
+func LoadReport(window Window, now time.Time) (Report, error) {
+	history, err := LoadHistory()
+	if err != nil {
+		return Report{}, err
+	}
+	followers, err := LoadFollowerStore()
+	if err != nil {
+		return Report{}, err
+	}
+	snapshots, err := LoadPostSnapshots()
+	if err != nil {
+		return Report{}, err
+	}
+
+	series := aggregate(window, history, followers)
+	posts := snapshots.Stats(window)
+	return Report{
+		Window:     window,
+		Generated:  now,
+		Series:     series,
+		Anomalies:  Anomalies(series),
+		Engagement: EngagementHeatmap(history, window, time.Local),
+		Posting:    PostingHeatmap(posts, time.Local),
+		Posts:      posts,
+		Followers:  followers.Report(window),
+	}, nil
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Series describes one countable metric for legends, toggles and output.
+type Series struct {
+	Key   string
+	Name  string
+	Short string
+	Value func(DailyMetric) int
+}
+
+// AllSeries lists every series in display order.
+var AllSeries = []Series{
+	{Key: "follows", Name: "Follows", Short: "F", Value: func(d DailyMetric) int { return d.Follows }},
+	{Key: "likes", Name: "Likes", Short: "L", Value: func(d DailyMetric) int { return d.Likes }},
+	{Key: "boosts", Name: "Boosts", Short: "B", Value: func(d DailyMetric) int { return d.Boosts }},
+	{Key: "mentions", Name: "Mentions", Short: "M", Value: func(d DailyMetric) int { return d.Mentions }},
+	{Key: "replies", Name: "Replies", Short: "R", Value: func(d DailyMetric) int { return d.Replies }},
+	{Key: "quotes", Name: "Quotes", Short: "Q", Value: func(d DailyMetric) int { return d.Quotes }},
+	{Key: "requests", Name: "Requests", Short: "FR", Value: func(d DailyMetric) int { return d.FollowRequests }},
+	{Key: "polls", Name: "Polls", Short: "P", Value: func(d DailyMetric) int { return d.Polls }},
+	{Key: "edits", Name: "Edits", Short: "E", Value: func(d DailyMetric) int { return d.Edits }},
+}
+
+// DefaultSeriesKeys are shown until the user picks others.
+var DefaultSeriesKeys = []string{"follows", "likes", "boosts", "mentions", "replies"}
+
+// DefaultSeries resolves DefaultSeriesKeys.
+func DefaultSeries() []Series {
+	selected := make([]Series, 0, len(DefaultSeriesKeys))
+	for _, key := range DefaultSeriesKeys {
+		if series, ok := SeriesByKey(key); ok {
+			selected = append(selected, series)
+		}
+	}
+	return selected
+}
+
+// ParseSeries resolves a comma-separated list of series keys; "all" selects
+// every series.
+func ParseSeries(value string) ([]Series, error) {
+	if strings.TrimSpace(value) == "all" {
+		return AllSeries, nil
+	}
+	var selected []Series
+	for _, key := range strings.Split(value, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		series, ok := SeriesByKey(key)
+		if !ok {
+			return nil, fmt.Errorf("unknown series %q (expected all or any of: %s)", key, strings.Join(seriesKeys(), ", "))
+		}
+		selected = append(selected, series)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("select at least one series")
+	}
+	return selected, nil
+}
+
+func SeriesByKey(key string) (Series, bool) {
+	for _, series := range AllSeries {
+		if series.Key == key {
+			return series, true
+		}
+	}
+	return Series{}, false
+}
+
+func seriesKeys() []string {
+	keys := make([]string, 0, len(AllSeries))
+	for _, series := range AllSeries {
+		keys = append(keys, series.Key)
+	}
+	return keys
+}
+
+// Total sums the selected series for one bucket.
+func Total(day DailyMetric, selected []Series) int {
+	total := 0
+	for _, series := range selected {
+		total += series.Value(day)
+	}
+	return total
+}
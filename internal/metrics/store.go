@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+
+	"mastodoncli/internal/config"
+	"mastodoncli/internal/mastodon"
+)
+
+// Notification kinds counted in the history. KindReply is recorded for
+// mentions that reply to another status, so replies can be told apart from
+// plain mentions; the others are the notification types of the API.
+const (
+	KindFollow        = "follow"
+	KindFavourite     = "favourite"
+	KindReblog        = "reblog"
+	KindMention       = "mention"
+	KindReply         = "reply"
+	KindQuote         = "quote"
+	KindFollowRequest = "follow_request"
+	KindPoll          = "poll"
+	KindUpdate        = "update"
+)
+
+const hourKeyLayout = "2006-01-02T15"
+
+// History is the local time series behind the metrics views. Counts are
+// kept per UTC hour and notification kind so any range can be re-bucketed
+// in local time without asking the server again.
+type History struct {
+	AccountID    string                    `json:"account_id"`
+	NewestID     string                    `json:"newest_id,omitempty"`
+	OldestID     string                    `json:"oldest_id,omitempty"`
+	CompleteFrom time.Time                 `json:"complete_from,omitempty"`
+	Hours        map[string]map[string]int `json:"hours"`
+	Followers    map[string]int            `json:"followers"`
+}
+
+func HistoryPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "metrics.json"), nil
+}
+
+// LoadHistory reads the store, returning an empty history when none exists.
+func LoadHistory() (*History, error) {
+	path, err := HistoryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	history := &History{}
+	if err := config.ReadJSON(path, "metrics history", history); err != nil {
+		return nil, err
+	}
+	history.init()
+	return history, nil
+}
+
+func (h *History) Save() error {
+	path, err := HistoryPath()
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, "metrics history", h)
+}
+
+func (h *History) init() {
+	if h.Hours == nil {
+		h.Hours = make(map[string]map[string]int)
+	}
+	if h.Followers == nil {
+		h.Followers = make(map[string]int)
+	}
+}
+
+// Reset drops everything recorded and binds the store to accountID.
+func (h *History) Reset(accountID string) {
+	*h = History{AccountID: accountID}
+	h.init()
+}
+
+// Record counts one notification. Notifications without a parseable
+// timestamp are ignored.
+func (h *History) Record(notification mastodon.Notification) {
+	at, err := parseTimestamp(notification.CreatedAt)
+	if err != nil {
+		return
+	}
+	kind := notification.Type
+	if kind == KindMention && notification.Status != nil && notification.Status.InReplyToID != "" {
+		kind = KindReply
+	}
+
+	key := at.UTC().Format(hourKeyLayout)
+	counts, ok := h.Hours[key]
+	if !ok {
+		counts = make(map[string]int)
+		h.Hours[key] = counts
+	}
+	counts[kind]++
+
+	if h.NewestID == "" || mastodon.CompareIDs(notification.ID, h.NewestID) > 0 {
+		h.NewestID = notification.ID
+	}
+	if h.OldestID == "" || mastodon.CompareIDs(notification.ID, h.OldestID) < 0 {
+		h.OldestID = notification.ID
+	}
+}
+
+// RecordFollowers stores the follower count observed at at; later samples
+// in the same hour replace earlier ones.
+func (h *History) RecordFollowers(at time.Time, count int) {
+	h.Followers[at.UTC().Format(hourKeyLayout)] = count
+}
+
+// AddHistory folds the recorded counts and follower samples into the
+// window. Each bucket reports the last follower count seen in it.
+func (a *Aggregator) AddHistory(history *History) {
+	for key, counts := range history.Hours {
+		at, err := time.Parse(hourKeyLayout, key)
+		if err != nil {
+			continue
+		}
+		metric := a.bucketAt(at.In(time.Local))
+		if metric == nil {
+			continue
+		}
+		for kind, count := range counts {
+			addKind(metric, kind, count)
+		}
+	}
+
+	keys := make([]string, 0, len(history.Followers))
+	for key := range history.Followers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		at, err := time.Parse(hourKeyLayout, key)
+		if err != nil {
+			continue
+		}
+		if metric := a.bucketAt(at.In(time.Local)); metric != nil {
+			metric.Followers = history.Followers[key]
+		}
+	}
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		parsed, err = time.Parse(time.RFC3339, value)
+	}
+	return parsed, err
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mastodoncli/internal/mastodon"
+)
+
+func TestSyncFetchesOnlyNewNotifications(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	now := time.Now().UTC()
+	stamp := func(ago time.Duration) string { return now.Add(-ago).Format(time.RFC3339) }
+	pages := map[string]string{
+		"|":     fmt.Sprintf(`[{"id":"30","type":"favourite","created_at":%q},{"id":"20","type":"mention","created_at":%q,"status":{"id":"9","in_reply_to_id":"8"}}]`, stamp(time.Hour), stamp(2*time.Hour)),
+		"|20":   fmt.Sprintf(`[{"id":"10","type":"follow","created_at":%q}]`, stamp(30*24*time.Hour)),
+		"30|":   fmt.Sprintf(`[{"id":"40","type":"reblog","created_at":%q}]`, stamp(time.Minute)),
+		"30|40": `[]`,
+	}
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/accounts/verify_credentials":
+			_, _ = w.Write([]byte(`{"id":"1","followers_count":42}`))
+		case "/api/v1/notifications":
+			key := r.URL.Query().Get("since_id") + "|" + r.URL.Query().Get("max_id")
+			requests = append(requests, key)
+			body, ok := pages[key]
+			if !ok {
+				t.Fatalf("unexpected page %q", key)
+			}
+			_, _ = w.Write([]byte(body))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client := mastodon.NewClient(server.URL, "token")
+
+	window := LastDays(7, time.Now(), BucketDay)
+	series, err := FetchMetrics(client, window, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	totals := DailyMetric{}
+	for _, day := range series {
+		totals.Follows += day.Follows
+		totals.Likes += day.Likes
+		totals.Replies += day.Replies
+	}
+	if totals.Likes != 1 || totals.Replies != 1 || totals.Follows != 0 {
+		t.Fatalf("unexpected totals after first sync: %+v", totals)
+	}
+	if series[len(series)-1].Followers != 42 {
+		t.Fatalf("expected follower count for today, got %+v", series[len(series)-1])
+	}
+
+	requests = nil
+	series, err = FetchMetrics(client, window, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 || requests[0] != "30|" {
+		t.Fatalf("expected only newer pages, got %v", requests)
+	}
+	boosts := 0
+	for _, day := range series {
+		boosts += day.Boosts
+	}
+	if boosts != 1 {
+		t.Fatalf("expected the new boost to be counted, got %d", boosts)
+	}
+
+	history, err := LoadHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.NewestID != "40" || history.OldestID != "10" {
+		t.Fatalf("unexpected history bounds: %+v", history)
+	}
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"time"
+
+	"mastodoncli/internal/mastodon"
+)
+
+const syncPageLimit = 80
+
+// Sync brings history up to date: it records notifications newer than the
+// last sync, then backfills older ones until start is covered or the
+// server has nothing older. progress receives the running count of
+// notifications fetched.
+func Sync(client *mastodon.Client, history *History, start time.Time, progress func(scanned int)) error {
+	account, err := client.VerifyCredentials()
+	if err != nil {
+		return err
+	}
+	if history.AccountID != account.ID {
+		history.Reset(account.ID)
+	}
+	history.RecordFollowers(time.Now(), account.FollowersCount)
+
+	scanned := 0
+	report := func(count int) {
+		scanned += count
+		if progress != nil {
+			progress(scanned)
+		}
+	}
+
+	if history.NewestID != "" {
+		newest := history.NewestID
+		var maxID string
+		for {
+			page, err := client.NotificationsPage(syncPageLimit, newest, maxID, nil)
+			if err != nil {
+				return err
+			}
+			if len(page) == 0 {
+				break
+			}
+			for _, notification := range page {
+				history.Record(notification)
+			}
+			report(len(page))
+			maxID = page[len(page)-1].ID
+		}
+	}
+
+	if history.NewestID != "" && !start.Before(history.CompleteFrom) {
+		return nil
+	}
+
+	maxID := history.OldestID
+	for {
+		page, err := client.NotificationsPage(syncPageLimit, "", maxID, nil)
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, notification := range page {
+			history.Record(notification)
+		}
+		report(len(page))
+		maxID = page[len(page)-1].ID
+
+		oldest, err := parseTimestamp(page[len(page)-1].CreatedAt)
+		if err == nil && oldest.Before(start) {
+			break
+		}
+	}
+	if history.CompleteFrom.IsZero() || start.Before(history.CompleteFrom) {
+		history.CompleteFrom = start
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxDays bounds a report range to ten years of daily buckets.
+const MaxDays = 3650
+
+// Bucket is the calendar unit metrics are grouped by.
+type Bucket string
+
+const (
+	BucketDay   Bucket = "day"
+	BucketWeek  Bucket = "week"
+	BucketMonth Bucket = "month"
+)
+
+// Buckets lists the supported buckets in picker order.
+var Buckets = []Bucket{BucketDay, BucketWeek, BucketMonth}
+
+func ParseBucket(value string) (Bucket, error) {
+	switch Bucket(value) {
+	case "":
+		return BucketDay, nil
+	case BucketDay, BucketWeek, BucketMonth:
+		return Bucket(value), nil
+	default:
+		return "", fmt.Errorf("bucket must be one of: day, week, month")
+	}
+}
+
+// Start truncates day to the first day of its bucket. Weeks start on Monday.
+func (b Bucket) Start(day time.Time) time.Time {
+	day = truncateDay(day)
+	switch b {
+	case BucketWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case BucketMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	default:
+		return day
+	}
+}
+
+// Next returns the start of the bucket after the one starting at start.
+func (b Bucket) Next(start time.Time) time.Time {
+	switch b {
+	case BucketWeek:
+		return start.AddDate(0, 0, 7)
+	case BucketMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+func (b Bucket) Label(start time.Time) string {
+	switch b {
+	case BucketWeek:
+		return "Wk " + start.Format("Jan 2")
+	case BucketMonth:
+		return start.Format("Jan 2006")
+	default:
+		return start.Format("Jan 2")
+	}
+}
+
+// Window is an inclusive range of calendar days in local time.
+type Window struct {
+	Start  time.Time
+	End    time.Time
+	Bucket Bucket
+}
+
+// LastDays covers days calendar days ending on the day of now.
+func LastDays(days int, now time.Time, bucket Bucket) Window {
+	if days < 1 {
+		days = 1
+	}
+	end := truncateDay(now)
+	return Window{Start: end.AddDate(0, 0, -days+1), End: end, Bucket: bucket}
+}
+
+// NewWindow builds a window from since and until (YYYY-MM-DD, both
+// optional). A missing since falls back to days before until; a missing
+// until means today.
+func NewWindow(days int, since, until string, bucket Bucket, now time.Time) (Window, error) {
+	end := truncateDay(now)
+	if until != "" {
+		parsed, err := ParseDate(until)
+		if err != nil {
+			return Window{}, fmt.Errorf("invalid --until: %w", err)
+		}
+		end = parsed
+	}
+	window := LastDays(days, end, bucket)
+	if since != "" {
+		start, err := ParseDate(since)
+		if err != nil {
+			return Window{}, fmt.Errorf("invalid --since: %w", err)
+		}
+		if start.After(end) {
+			return Window{}, fmt.Errorf("--since must not be after --until")
+		}
+		window.Start = start
+	}
+	return window, nil
+}
+
+func ParseDate(value string) (time.Time, error) {
+	parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD, got %q", value)
+	}
+	return parsed, nil
+}
+
+func (w Window) Contains(day time.Time) bool {
+	return !day.Before(w.Start) && !day.After(w.End)
+}
+
+// Days returns the number of calendar days in the window.
+func (w Window) Days() int {
+	days := 0
+	for day := w.Start; !day.After(w.End); day = day.AddDate(0, 0, 1) {
+		days++
+	}
+	return days
+}
+
+// Describe returns a short label such as "30d" or "2026-01-01..2026-03-31, week".
+func (w Window) Describe(today time.Time) string {
+	var label string
+	if w.End.Equal(truncateDay(today)) {
+		label = fmt.Sprintf("%dd", w.Days())
+	} else {
+		label = w.Start.Format("2006-01-02") + ".." + w.End.Format("2006-01-02")
+	}
+	if w.Bucket != "" && w.Bucket != BucketDay {
+		label += ", " + string(w.Bucket)
+	}
+	return label
+}
@@ -0,0 +1,53 @@
+package output
+
+import "strings"
+
+const (
+	CWNever    = "never"
+	CWAlways   = "always"
+	CWKeywords = "keywords"
+)
+
+// CWPolicy decides whether a content warning starts expanded. With the
+// keywords mode, warnings mentioning one of Keywords stay collapsed and
+// all others are expanded.
+type CWPolicy struct {
+	Mode     string
+	Keywords []string
+}
+
+func ValidCWMode(mode string) bool {
+	switch mode {
+	case "", CWNever, CWAlways, CWKeywords:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p CWPolicy) Expand(spoilerText string) bool {
+	if strings.TrimSpace(spoilerText) == "" {
+		return true
+	}
+	switch p.Mode {
+	case CWAlways:
+		return true
+	case CWKeywords:
+		lower := strings.ToLower(spoilerText)
+		for _, keyword := range p.Keywords {
+			keyword = strings.ToLower(strings.TrimSpace(keyword))
+			if keyword != "" && strings.Contains(lower, keyword) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// RevealSensitive reports whether media marked sensitive is previewed
+// without expanding the status first.
+func (p CWPolicy) RevealSensitive() bool {
+	return p.Mode == CWAlways
+}
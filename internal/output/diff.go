@@ -0,0 +1,160 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mastodoncli/internal/mastodon"
+)
+
+type DiffOp int
+
+const (
+	DiffSame DiffOp = iota
+	DiffAdded
+	DiffRemoved
+)
+
+type DiffPart struct {
+	Op   DiffOp
+	Text string
+}
+
+var diffToken = regexp.MustCompile(`\s+|[^\s]+`)
+
+// DiffWords compares two texts word by word. Runs of the same kind are
+// merged, so the result alternates between kept, removed and added text.
+func DiffWords(old, new string) []DiffPart {
+	a := diffToken.FindAllString(old, -1)
+	b := diffToken.FindAllString(new, -1)
+
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var parts []DiffPart
+	add := func(op DiffOp, text string) {
+		if n := len(parts); n > 0 && parts[n-1].Op == op {
+			parts[n-1].Text += text
+			return
+		}
+		parts = append(parts, DiffPart{Op: op, Text: text})
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			add(DiffSame, a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			add(DiffRemoved, a[i])
+			i++
+		default:
+			add(DiffAdded, b[j])
+			j++
+		}
+	}
+	return parts
+}
+
+// FormatDiff marks removals as [-text-] and additions as {+text+}, like
+// git diff --word-diff.
+func FormatDiff(parts []DiffPart) string {
+	var b strings.Builder
+	for _, part := range parts {
+		switch part.Op {
+		case DiffAdded:
+			fmt.Fprintf(&b, "{+%s+}", part.Text)
+		case DiffRemoved:
+			fmt.Fprintf(&b, "[-%s-]", part.Text)
+		default:
+			b.WriteString(part.Text)
+		}
+	}
+	return b.String()
+}
+
+// EditText is the revision as plain text, with the content warning on
+// its own first line.
+func EditText(edit mastodon.StatusEdit) string {
+	text := StripHTML(edit.Content)
+	if spoiler := strings.TrimSpace(StripHTML(edit.SpoilerText)); spoiler != "" {
+		text = "CW: " + spoiler + "\n" + text
+	}
+	return text
+}
+
+// EditChanges lists what changed between two revisions besides the text.
+func EditChanges(prev, edit mastodon.StatusEdit) []string {
+	var changes []string
+	if prev.Sensitive != edit.Sensitive {
+		if edit.Sensitive {
+			changes = append(changes, "marked sensitive")
+		} else {
+			changes = append(changes, "no longer sensitive")
+		}
+	}
+	if len(prev.MediaAttachments) != len(edit.MediaAttachments) {
+		changes = append(changes, fmt.Sprintf("attachments %d → %d", len(prev.MediaAttachments), len(edit.MediaAttachments)))
+	} else {
+		for i := range edit.MediaAttachments {
+			if prev.MediaAttachments[i].Description != edit.MediaAttachments[i].Description {
+				changes = append(changes, fmt.Sprintf("alt text of attachment %d", i+1))
+			}
+		}
+	}
+	if (prev.Poll == nil) != (edit.Poll == nil) {
+		if edit.Poll != nil {
+			changes = append(changes, "poll added")
+		} else {
+			changes = append(changes, "poll removed")
+		}
+	} else if prev.Poll != nil && !samePollOptions(prev.Poll.Options, edit.Poll.Options) {
+		changes = append(changes, "poll options changed")
+	}
+	return changes
+}
+
+func samePollOptions(a, b []mastodon.PollOption) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Title != b[i].Title {
+			return false
+		}
+	}
+	return true
+}
+
+// PrintHistory prints every revision after the first as a word diff
+// against the one before it.
+func PrintHistory(edits []mastodon.StatusEdit) {
+	if len(edits) == 0 {
+		fmt.Println("No edit history returned.")
+		return
+	}
+	fmt.Printf("%sOriginal:%s %s\n", colorYellow, colorReset, edits[0].CreatedAt)
+	fmt.Println(EditText(edits[0]))
+	for i := 1; i < len(edits); i++ {
+		fmt.Println("----")
+		fmt.Printf("%sEdit %d:%s %s\n", colorYellow, i, colorReset, edits[i].CreatedAt)
+		if changes := EditChanges(edits[i-1], edits[i]); len(changes) > 0 {
+			fmt.Printf("Changed: %s\n", strings.Join(changes, ", "))
+		}
+		fmt.Println(FormatDiff(DiffWords(EditText(edits[i-1]), EditText(edits[i]))))
+	}
+}
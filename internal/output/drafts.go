@@ -0,0 +1,55 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"mastodoncli/internal/drafts"
+)
+
+// PrintDrafts lists drafts one per line; the owning account is shown when
+// showAccount is set.
+func PrintDrafts(list []drafts.Draft, showAccount bool) {
+	if len(list) == 0 {
+		fmt.Println("No drafts.")
+		return
+	}
+	for _, draft := range list {
+		var tags []string
+		if showAccount && draft.Account != "" {
+			tags = append(tags, draft.Account)
+		}
+		tags = append(tags, DraftTags(draft)...)
+		suffix := ""
+		if len(tags) > 0 {
+			suffix = "  [" + strings.Join(tags, ", ") + "]"
+		}
+		fmt.Printf("%s  %s  %s%s\n", draft.ID, draft.UpdatedAt.Local().Format(ScheduleTimeLayout), DraftExcerpt(draft, 60), suffix)
+	}
+}
+
+// DraftTags summarises what a draft carries besides its text.
+func DraftTags(draft drafts.Draft) []string {
+	var tags []string
+	if draft.InReplyToID != "" {
+		tags = append(tags, "reply to "+draft.InReplyToID)
+	}
+	if draft.Visibility != "" {
+		tags = append(tags, draft.Visibility)
+	}
+	if draft.SpoilerText != "" {
+		tags = append(tags, "CW: "+draft.SpoilerText)
+	}
+	if draft.Poll != nil {
+		tags = append(tags, "poll")
+	}
+	if len(draft.Media) > 0 {
+		tags = append(tags, fmt.Sprintf("%d media", len(draft.Media)))
+	}
+	return tags
+}
+
+// DraftExcerpt returns the first width runes of the text on one line.
+func DraftExcerpt(draft drafts.Draft, width int) string {
+	return excerpt(draft.Text, width)
+}
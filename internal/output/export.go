@@ -0,0 +1,361 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+
+	"mastodoncli/internal/metrics"
+)
+
+// seriesColors match the TUI chart colors.
+var seriesColors = map[string]string{
+	"follows":  "#5faf00",
+	"likes":    "#ffd700",
+	"boosts":   "#0087ff",
+	"mentions": "#af87ff",
+	"replies":  "#ff8700",
+	"quotes":   "#00d7ff",
+	"requests": "#87ff00",
+	"polls":    "#d787af",
+	"edits":    "#949494",
+}
+
+const lostColor = "#d75f5f"
+
+// WriteMetricsCSV writes one row per bucket with every series.
+func WriteMetricsCSV(w io.Writer, series []metrics.DailyMetric, anomalies []metrics.Anomaly) error {
+	writer := csv.NewWriter(w)
+	header := []string{"date", "label"}
+	for _, kind := range metrics.AllSeries {
+		header = append(header, kind.Key)
+	}
+	header = append(header, "lost", "followers", "anomaly")
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	for i, day := range series {
+		record := []string{day.Date.Format("2006-01-02"), day.Label}
+		for _, kind := range metrics.AllSeries {
+			record = append(record, strconv.Itoa(kind.Value(day)))
+		}
+		record = append(record, strconv.Itoa(day.Lost), strconv.Itoa(day.Followers), anomalyName(anomalies, i))
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("write csv: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	return nil
+}
+
+// WriteMetricsJSON writes the buckets as an array of objects keyed like the
+// CSV columns.
+func WriteMetricsJSON(w io.Writer, series []metrics.DailyMetric, anomalies []metrics.Anomaly) error {
+	rows := make([]map[string]any, 0, len(series))
+	for i, day := range series {
+		row := map[string]any{
+			"date":      day.Date.Format("2006-01-02"),
+			"label":     day.Label,
+			"lost":      day.Lost,
+			"followers": day.Followers,
+		}
+		for _, kind := range metrics.AllSeries {
+			row[kind.Key] = kind.Value(day)
+		}
+		if name := anomalyName(anomalies, i); name != "" {
+			row["anomaly"] = name
+		}
+		rows = append(rows, row)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(rows); err != nil {
+		return fmt.Errorf("write json: %w", err)
+	}
+	return nil
+}
+
+func anomalyName(anomalies []metrics.Anomaly, i int) string {
+	if i >= len(anomalies) {
+		return ""
+	}
+	switch anomalies[i] {
+	case metrics.AnomalySpike:
+		return "spike"
+	case metrics.AnomalyDip:
+		return "dip"
+	default:
+		return ""
+	}
+}
+
+type reportPost struct {
+	Date    string
+	Score   int
+	Counts  string
+	Early   string
+	Excerpt string
+	URL     string
+}
+
+type reportLegend struct {
+	Name  string
+	Color string
+}
+
+// WriteMetricsHTML writes a single-file report: inline CSS and SVG charts,
+// no scripts and no external resources.
+func WriteMetricsHTML(w io.Writer, report metrics.Report, selected []metrics.Series) error {
+	const topPosts = 10
+
+	legend := make([]reportLegend, 0, len(selected))
+	for _, kind := range selected {
+		legend = append(legend, reportLegend{Name: kind.Name, Color: seriesColors[kind.Key]})
+	}
+	var posts []reportPost
+	for i, post := range report.Posts {
+		if i == topPosts {
+			break
+		}
+		posts = append(posts, reportPost{
+			Date:    post.CreatedAt.Format("Jan 2 15:04"),
+			Score:   post.Score(),
+			Counts:  fmt.Sprintf("♥%d ⟳%d ↩%d", post.Favourites, post.Reblogs, post.Replies),
+			Early:   fmt.Sprintf("1h %s · 24h %s", FormatEngagement(post.FirstHour), FormatEngagement(post.FirstDay)),
+			Excerpt: PostExcerpt(post, 140),
+			URL:     post.URL,
+		})
+	}
+
+	data := map[string]any{
+		"Window":     report.Window.Describe(report.Generated),
+		"Generated":  report.Generated.Format("2006-01-02 15:04 MST"),
+		"Total":      metrics.FormatTotal(report.Series, selected),
+		"Legend":     legend,
+		"LostColor":  lostColor,
+		"Activity":   template.HTML(activitySVG(report.Series, report.Anomalies, selected)),
+		"Growth":     template.HTML(followersSVG(report.Series)),
+		"Gained":     len(report.Followers.Gained),
+		"Lost":       len(report.Followers.Lost),
+		"Net":        fmt.Sprintf("%+d", report.Followers.Net()),
+		"Engagement": template.HTML(heatmapSVG(report.Engagement)),
+		"EngPeak":    report.Engagement.Peak(),
+		"Posting":    template.HTML(heatmapSVG(report.Posting)),
+		"PostPeak":   report.Posting.Peak(),
+		"Posts":      posts,
+		"PostCount":  len(report.Posts),
+	}
+	if err := reportTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("write html: %w", err)
+	}
+	return nil
+}
+
+// activitySVG stacks the selected series per bucket and marks anomalies.
+func activitySVG(series []metrics.DailyMetric, anomalies []metrics.Anomaly, selected []metrics.Series) string {
+	const width, height, left, bottom, top = 860, 260, 40, 30, 16
+	if len(series) == 0 {
+		return "<p>No buckets.</p>"
+	}
+
+	maxTotal := 1
+	for _, day := range series {
+		if total := metrics.Total(day, selected); total > maxTotal {
+			maxTotal = total
+		}
+	}
+	plotHeight := float64(height - bottom - top)
+	step := float64(width-left) / float64(len(series))
+	barWidth := step * 0.8
+	labelEvery := (len(series) + 11) / 12
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" role="img">`, width, height)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#999"/>`, left, height-bottom, width, height-bottom)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="axis" text-anchor="end">%d</text>`, left-4, top+4, maxTotal)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="axis" text-anchor="end">0</text>`, left-4, height-bottom)
+	for i, day := range series {
+		x := float64(left) + float64(i)*step + (step-barWidth)/2
+		y := float64(height - bottom)
+		for _, kind := range selected {
+			value := kind.Value(day)
+			if value == 0 {
+				continue
+			}
+			h := float64(value) * plotHeight / float64(maxTotal)
+			y -= h
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s %s: %d</title></rect>`,
+				x, y, barWidth, h, seriesColors[kind.Key], html.EscapeString(day.Label), kind.Name, value)
+		}
+		if marker := anomalyMarker(anomalies, i); marker != "" {
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" class="marker" text-anchor="middle">%s</text>`, x+barWidth/2, y-3, marker)
+		}
+		if i%labelEvery == 0 {
+			fmt.Fprintf(&b, `<text x="%.1f" y="%d" class="axis" text-anchor="middle">%s</text>`,
+				x+barWidth/2, height-bottom+16, html.EscapeString(day.Label))
+		}
+	}
+	b.WriteString("</svg>")
+	return b.String()
+}
+
+func anomalyMarker(anomalies []metrics.Anomaly, i int) string {
+	if i >= len(anomalies) {
+		return ""
+	}
+	return anomalies[i].Marker()
+}
+
+// followersSVG draws the recorded follower count, carried forward over
+// buckets without a count, with lost followers as bars below the line.
+func followersSVG(series []metrics.DailyMetric) string {
+	const width, height, left, bottom, top = 860, 180, 56, 30, 16
+
+	counts := make([]int, len(series))
+	last := 0
+	low, high := 0, 0
+	for i, day := range series {
+		if day.Followers > 0 {
+			last = day.Followers
+		}
+		counts[i] = last
+		if last == 0 {
+			continue
+		}
+		if low == 0 || last < low {
+			low = last
+		}
+		if last > high {
+			high = last
+		}
+	}
+	if high == 0 {
+		return "<p>No follower counts recorded yet.</p>"
+	}
+	if high == low {
+		low--
+	}
+
+	plotHeight := float64(height - bottom - top)
+	step := float64(width-left) / float64(len(series))
+	var points []string
+	for i, count := range counts {
+		if count == 0 {
+			continue
+		}
+		x := float64(left) + (float64(i)+0.5)*step
+		y := float64(top) + float64(high-count)*plotHeight/float64(high-low)
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" role="img">`, width, height)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#999"/>`, left, height-bottom, width, height-bottom)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="axis" text-anchor="end">%d</text>`, left-4, top+4, high)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="axis" text-anchor="end">%d</text>`, left-4, height-bottom, low)
+	for i, day := range series {
+		if day.Lost == 0 {
+			continue
+		}
+		h := lostBarHeight(day.Lost)
+		x := float64(left) + float64(i)*step + step*0.3
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s lost %d</title></rect>`,
+			x, float64(height-bottom)-h, step*0.4, h, lostColor, html.EscapeString(day.Label), day.Lost)
+	}
+	fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`, strings.Join(points, " "), seriesColors["follows"])
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="axis" text-anchor="start">%s</text>`, left, height-bottom+16, html.EscapeString(series[0].Label))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="axis" text-anchor="end">%s</text>`, width, height-bottom+16, html.EscapeString(series[len(series)-1].Label))
+	b.WriteString("</svg>")
+	return b.String()
+}
+
+// lostBarHeight caps a lost-followers bar so a bad day doesn't swamp the
+// line.
+func lostBarHeight(lost int) float64 {
+	const perFollower, limit = 4, 24
+	return float64(min(lost*perFollower, limit))
+}
+
+// heatmapSVG draws a weekday by hour grid shaded by count.
+func heatmapSVG(heatmap metrics.Heatmap) string {
+	const cell, gap, left, top = 30, 2, 40, 18
+	maxValue := heatmap.Max()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" role="img">`, left+24*cell, top+7*cell)
+	for hour := 0; hour < 24; hour += 3 {
+		fmt.Fprintf(&b, `<text x="%d" y="%d" class="axis">%02d</text>`, left+hour*cell, top-5, hour)
+	}
+	for day, row := range heatmap.Cells {
+		y := top + day*cell
+		fmt.Fprintf(&b, `<text x="0" y="%d" class="axis">%s</text>`, y+cell/2+4, metrics.Weekdays[day])
+		for hour, value := range row {
+			fill, opacity := "#e8e8e8", 1.0
+			if value > 0 {
+				fill = "#2da44e"
+				opacity = 0.15 + 0.85*float64(value)/float64(maxValue)
+			}
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s" fill-opacity="%.2f"><title>%s %02d:00 %d</title></rect>`,
+				left+hour*cell, y, cell-gap, cell-gap, fill, opacity, metrics.Weekdays[day], hour, value)
+		}
+	}
+	b.WriteString("</svg>")
+	return b.String()
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Mastodon metrics · {{.Window}}</title>
+<style>
+body { font: 14px/1.5 system-ui, sans-serif; color: #222; max-width: 900px; margin: 2em auto; padding: 0 1em; }
+h1 { margin-bottom: 0; }
+h2 { margin-top: 2em; border-bottom: 1px solid #ddd; }
+.muted { color: #777; }
+.legend span { margin-right: 1em; }
+.swatch { display: inline-block; width: .8em; height: .8em; margin-right: .3em; }
+svg { width: 100%; height: auto; }
+svg .axis { font-size: 11px; fill: #666; }
+svg .marker { font-size: 12px; fill: #c0c; }
+table { border-collapse: collapse; width: 100%; }
+td { border-top: 1px solid #eee; padding: .4em .3em; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>Mastodon metrics</h1>
+<p class="muted">{{.Window}} · generated {{.Generated}}</p>
+<p>{{.Total}}</p>
+
+<h2>Activity</h2>
+<p class="legend">{{range .Legend}}<span><i class="swatch" style="background: {{.Color}}"></i>{{.Name}}</span>{{end}}<span>▲ spike ▼ dip</span></p>
+{{.Activity}}
+
+<h2>Followers</h2>
+<p>New {{.Gained}} · Lost {{.Lost}} · Net {{.Net}} <span class="legend"><span><i class="swatch" style="background: {{.LostColor}}"></i>lost</span></span></p>
+{{.Growth}}
+
+<h2>Engagement by weekday and hour</h2>
+<p class="muted">Peak {{.EngPeak}}</p>
+{{.Engagement}}
+
+<h2>Your posts by weekday and hour</h2>
+<p class="muted">Peak {{.PostPeak}}</p>
+{{.Posting}}
+
+<h2>Top posts</h2>
+{{if .Posts}}<table>
+{{range $i, $post := .Posts}}<tr><td>{{$post.Date}}</td><td>score {{$post.Score}}<br><span class="muted">{{$post.Counts}}</span></td><td>{{if $post.URL}}<a href="{{$post.URL}}">{{$post.Excerpt}}</a>{{else}}{{$post.Excerpt}}{{end}}<br><span class="muted">{{$post.Early}}</span></td></tr>
+{{end}}</table>
+<p class="muted">Posts in range: {{.PostCount}}.</p>{{else}}<p class="muted">No posts recorded; run <code>mastodon metrics posts</code> for this range first.</p>{{end}}
+</body>
+</html>
+`))
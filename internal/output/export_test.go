@@ -0,0 +1,86 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"mastodoncli/internal/metrics"
+)
+
+func exportSeries() ([]metrics.DailyMetric, []metrics.Anomaly) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+	series := []metrics.DailyMetric{
+		{Date: day, Label: "Mar 2", Follows: 1, Likes: 4, Lost: 1, Followers: 40},
+		{Date: day.AddDate(0, 0, 1), Label: "<Mar 3>", Boosts: 2, Edits: 1, Followers: 41},
+	}
+	return series, []metrics.Anomaly{metrics.AnomalyNone, metrics.AnomalySpike}
+}
+
+func TestWriteMetricsCSV(t *testing.T) {
+	series, anomalies := exportSeries()
+	var buf bytes.Buffer
+	if err := WriteMetricsCSV(&buf, series, anomalies); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected a header and 2 rows, got %d records", len(records))
+	}
+	header := strings.Join(records[0], ",")
+	if header != "date,label,follows,likes,boosts,mentions,replies,quotes,requests,polls,edits,lost,followers,anomaly" {
+		t.Fatalf("unexpected header: %s", header)
+	}
+	if row := strings.Join(records[1], ","); row != "2026-03-02,Mar 2,1,4,0,0,0,0,0,0,0,1,40," {
+		t.Fatalf("unexpected first row: %s", row)
+	}
+	if row := strings.Join(records[2], ","); row != "2026-03-03,<Mar 3>,0,0,2,0,0,0,0,0,1,0,41,spike" {
+		t.Fatalf("unexpected second row: %s", row)
+	}
+}
+
+func TestWriteMetricsJSON(t *testing.T) {
+	series, anomalies := exportSeries()
+	var buf bytes.Buffer
+	if err := WriteMetricsJSON(&buf, series, anomalies); err != nil {
+		t.Fatal(err)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, buf.String())
+	}
+	if len(rows) != 2 || rows[0]["date"] != "2026-03-02" || rows[0]["likes"] != 4.0 || rows[1]["anomaly"] != "spike" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+	if _, ok := rows[0]["anomaly"]; ok {
+		t.Fatalf("expected no anomaly key on an ordinary day: %v", rows[0])
+	}
+}
+
+func TestWriteMetricsHTMLEscapesLabels(t *testing.T) {
+	series, anomalies := exportSeries()
+	now := time.Date(2026, 3, 3, 12, 0, 0, 0, time.Local)
+	report := metrics.Report{
+		Window:    metrics.LastDays(2, now, metrics.BucketDay),
+		Generated: now,
+		Series:    series,
+		Anomalies: anomalies,
+	}
+	var buf bytes.Buffer
+	if err := WriteMetricsHTML(&buf, report, metrics.AllSeries); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	if strings.Contains(page, "<Mar 3>") {
+		t.Fatalf("label was not escaped")
+	}
+	if !strings.Contains(page, "&lt;Mar 3&gt;") {
+		t.Fatalf("expected the escaped label in the report")
+	}
+}
@@ -6,15 +6,37 @@ import (
 	"strings"
 
 	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/preview"
+	"mastodoncli/internal/rules"
 )
 
-func PrintStatuses(statuses []mastodon.Status) {
+// DisplayOptions controls how statuses and notifications are printed.
+type DisplayOptions struct {
+	Rules      *rules.Set
+	Context    rules.Context
+	ShowHidden bool
+	CW         CWPolicy
+	Previews   preview.Protocol
+	Cache      *preview.Cache
+	// Details holds notification payloads by notification ID; see
+	// NotificationNeedsDetail.
+	Details map[string]*mastodon.NotificationDetail
+}
+
+func PrintStatuses(statuses []mastodon.Status, opts DisplayOptions) {
 	if len(statuses) == 0 {
 		fmt.Println("No statuses returned.")
 		return
 	}
 
+	hidden := 0
 	for _, item := range statuses {
+		verdict := opts.Rules.Status(item, opts.Context)
+		if verdict.Action == rules.ActionHide && !opts.ShowHidden {
+			hidden++
+			continue
+		}
+
 		display := &item
 		boostedBy := ""
 		if item.Reblog != nil {
@@ -23,7 +45,6 @@ func PrintStatuses(statuses []mastodon.Status) {
 		}
 
 		name := strings.TrimSpace(StripHTML(display.Account.DisplayName))
-		body := WrapText(StripHTML(display.Content), 80)
 
 		fmt.Println("----")
 		if name != "" && name != display.Account.Acct {
@@ -32,41 +53,136 @@ func PrintStatuses(statuses []mastodon.Status) {
 			fmt.Printf("%sAuthor:%s @%s\n", colorCyan, colorReset, display.Account.Acct)
 		}
 		fmt.Printf("%sTime:%s   %s\n", colorYellow, colorReset, display.CreatedAt)
+		fmt.Printf("ID:     %s\n", display.ID)
 		if boostedBy != "" {
 			fmt.Printf("Boost:  %s\n", boostedBy)
 		}
-		fmt.Println("Text:")
-		fmt.Println(body)
+		if verdict.Matched() {
+			printVerdict(verdict)
+		}
+		if verdict.Action != rules.ActionCollapse {
+			printStatusBody(*display, opts)
+		}
 		fmt.Println()
 	}
+	printHiddenCount(hidden, "statuses")
 }
 
-func PrintNotifications(notifications []mastodon.GroupedNotification) {
+func PrintNotifications(notifications []mastodon.GroupedNotification, opts DisplayOptions) {
 	if len(notifications) == 0 {
 		fmt.Println("No notifications returned.")
 		return
 	}
 
+	hidden := 0
 	for _, item := range notifications {
+		verdict := opts.Rules.Notification(item)
+		if verdict.Action == rules.ActionHide && !opts.ShowHidden {
+			hidden++
+			continue
+		}
+
 		fmt.Println("----")
-		fmt.Printf("%sType:%s  %s (%d)\n", colorCyan, colorReset, notificationTypeLabel(item.Type), item.Count)
-		fmt.Printf("%sFrom:%s  %s\n", colorCyan, colorReset, notificationAccountsLabel(item.Accounts))
+		fmt.Printf("%sType:%s  %s (%d)\n", colorCyan, colorReset, NotificationTypeLabel(item.Type), item.Count)
+		fmt.Printf("%sFrom:%s  %s\n", colorCyan, colorReset, NotificationAccountsLabel(item.Accounts))
+		if headline := NotificationHeadline(item.Type); headline != "" {
+			fmt.Printf("        %s\n", headline)
+		}
 		if item.LatestAt != "" {
 			fmt.Printf("%sTime:%s  %s\n", colorYellow, colorReset, item.LatestAt)
 		} else {
 			fmt.Printf("%sTime:%s  Unknown\n", colorYellow, colorReset)
 		}
-
-		if item.Status != nil {
-			fmt.Println("Text:")
-			body := WrapText(StripHTML(item.Status.Content), 80)
-			if body == "" {
-				body = "(no text)"
+		if verdict.Matched() {
+			printVerdict(verdict)
+		}
+		if detail := opts.Details[item.MostRecent]; detail != nil {
+			for _, line := range NotificationDetailLines(*detail) {
+				fmt.Printf("        %s\n", line)
 			}
-			fmt.Println(body)
+		}
+
+		if item.Status != nil && verdict.Action != rules.ActionCollapse {
+			printStatusBody(*item.Status, opts)
 		}
 		fmt.Println()
 	}
+	printHiddenCount(hidden, "notifications")
+}
+
+func printStatusBody(status mastodon.Status, opts DisplayOptions) {
+	spoiler := strings.TrimSpace(StripHTML(status.SpoilerText))
+	if spoiler != "" {
+		fmt.Printf("%sCW:%s     %s\n", colorMagenta, colorReset, spoiler)
+	}
+	if status.Sensitive && len(status.MediaAttachments) > 0 {
+		fmt.Printf("%sMedia:%s  marked sensitive\n", colorMagenta, colorReset)
+	}
+	expanded := opts.CW.Expand(spoiler)
+	if !expanded {
+		fmt.Println("Text:   (hidden behind content warning, use --expand-cw)")
+	} else {
+		body := WrapText(StripHTML(status.Content), 80)
+		if body == "" {
+			body = "(no text)"
+		}
+		fmt.Println("Text:")
+		fmt.Println(body)
+		if status.Poll != nil {
+			PrintPoll(*status.Poll)
+		}
+	}
+
+	printAttachments(status, expanded && (spoiler != "" || opts.CW.RevealSensitive()), opts)
+}
+
+// printAttachments lists the media of status; revealed controls previews of
+// sensitive media.
+func printAttachments(status mastodon.Status, revealed bool, opts DisplayOptions) {
+	if len(status.MediaAttachments) == 0 {
+		return
+	}
+
+	fmt.Printf("%sMedia:%s\n", colorCyan, colorReset)
+	for i, attachment := range status.MediaAttachments {
+		fmt.Printf("  %d. %s\n", i+1, AttachmentSummary(attachment))
+		fmt.Printf("     Alt: %s\n", AttachmentAlt(attachment))
+		fmt.Printf("     URL: %s\n", AttachmentURL(attachment))
+
+		if opts.Previews == "" || opts.Previews == preview.ProtocolOff || opts.Cache == nil || !Previewable(attachment) {
+			continue
+		}
+		if status.Sensitive && !revealed {
+			fmt.Println("     (preview hidden for sensitive media, use --expand-cw)")
+			continue
+		}
+		img, err := opts.Cache.Image(attachment.PreviewURL)
+		if err != nil {
+			fmt.Printf("     (preview unavailable: %v)\n", err)
+			continue
+		}
+		rendered, err := preview.Render(img, opts.Previews, 40, 20)
+		if err != nil {
+			fmt.Printf("     (preview unavailable: %v)\n", err)
+			continue
+		}
+		fmt.Println(rendered)
+	}
+}
+
+func printVerdict(verdict rules.Verdict) {
+	color := colorMagenta
+	if verdict.Action == rules.ActionHighlight {
+		color = colorGreen
+	}
+	fmt.Printf("%sRule:%s   %s\n", color, colorReset, verdict.Reason())
+}
+
+func printHiddenCount(hidden int, noun string) {
+	if hidden == 0 {
+		return
+	}
+	fmt.Printf("Hid %d %s matching local rules (use --show-hidden to list them).\n", hidden, noun)
 }
 
 func StripHTML(input string) string {
@@ -124,7 +240,8 @@ func WrapText(text string, width int) string {
 	return builder.String()
 }
 
-func notificationTypeLabel(value string) string {
+// NotificationTypeLabel names a notification type for people, e.g. "Boost".
+func NotificationTypeLabel(value string) string {
 	switch value {
 	case "mention":
 		return "Mention"
@@ -146,23 +263,35 @@ func notificationTypeLabel(value string) string {
 		return "Sign up"
 	case "admin.report":
 		return "Report"
+	case "severed_relationships":
+		return "Severed relationships"
+	case "moderation_warning":
+		return "Moderation warning"
+	case "quote":
+		return "Quote"
+	case "quoted_update":
+		return "Quoted post edited"
+	case "annual_report":
+		return "Annual report"
 	default:
 		return value
 	}
 }
 
-func notificationAccountsLabel(accounts []mastodon.Account) string {
+// NotificationAccountsLabel shows the first account and how many others.
+func NotificationAccountsLabel(accounts []mastodon.Account) string {
 	if len(accounts) == 0 {
 		return "Unknown"
 	}
 	if len(accounts) == 1 {
-		return formatAccount(accounts[0])
+		return FormatAccount(accounts[0])
 	}
-	first := formatAccount(accounts[0])
+	first := FormatAccount(accounts[0])
 	return fmt.Sprintf("%s +%d", first, len(accounts)-1)
 }
 
-func formatAccount(account mastodon.Account) string {
+// FormatAccount reads like "Alice (@alice@example.social)".
+func FormatAccount(account mastodon.Account) string {
 	name := strings.TrimSpace(StripHTML(account.DisplayName))
 	if name != "" && name != account.Acct {
 		return fmt.Sprintf("%s (@%s)", name, account.Acct)
@@ -171,7 +300,9 @@ func formatAccount(account mastodon.Account) string {
 }
 
 const (
-	colorReset  = "\033[0m"
-	colorCyan   = "\033[36m"
-	colorYellow = "\033[33m"
+	colorReset   = "\033[0m"
+	colorCyan    = "\033[36m"
+	colorYellow  = "\033[33m"
+	colorGreen   = "\033[32m"
+	colorMagenta = "\033[35m"
 )
@@ -0,0 +1,148 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"mastodoncli/internal/mastodon"
+)
+
+// PrintInstance prints what the instance says about itself, what it runs
+// and supports, its rules and contact, and the extended description.
+func PrintInstance(instance mastodon.Instance, caps mastodon.Capabilities, about string) {
+	title := instance.Domain
+	if instance.Title != "" && instance.Title != instance.Domain {
+		title = fmt.Sprintf("%s (%s)", instance.Title, instance.Domain)
+	}
+	fmt.Println(title)
+	if description := strings.TrimSpace(StripHTML(instance.Description)); description != "" {
+		fmt.Println(WrapText(description, 78))
+	}
+	fmt.Println()
+
+	software := caps.Name()
+	if caps.APIVersion > 0 {
+		software += fmt.Sprintf(" (API v%d)", caps.APIVersion)
+	}
+	fmt.Printf("Software:      %s\n", software)
+	switch {
+	case instance.ActiveUsers > 0:
+		fmt.Printf("Users:         %d active this month\n", instance.ActiveUsers)
+	case instance.Users > 0:
+		fmt.Printf("Users:         %d\n", instance.Users)
+	}
+	if len(instance.Languages) > 0 {
+		fmt.Printf("Languages:     %s\n", strings.Join(instance.Languages, ", "))
+	}
+	fmt.Printf("Registrations: %s\n", registrationsLabel(instance))
+
+	limits := caps.Limits
+	fmt.Printf("Posts:         %d characters, links count as %d, %d attachments\n",
+		limits.Statuses.MaxCharacters, limits.Statuses.CharactersReservedPerURL, limits.Statuses.MaxMediaAttachments)
+	media := []string{fmt.Sprintf("alt text up to %d characters", limits.MediaAttachments.DescriptionLimit)}
+	if limits.MediaAttachments.ImageSizeLimit > 0 {
+		media = append(media, "images up to "+byteSize(limits.MediaAttachments.ImageSizeLimit))
+	}
+	if limits.MediaAttachments.VideoSizeLimit > 0 {
+		media = append(media, "videos up to "+byteSize(limits.MediaAttachments.VideoSizeLimit))
+	}
+	fmt.Printf("Media:         %s\n", strings.Join(media, ", "))
+
+	fmt.Printf("Supports:      %s\n", supportedLabel(caps))
+	if len(caps.Features) > 0 {
+		fmt.Printf("Features:      %s\n", strings.Join(caps.Features, ", "))
+	}
+
+	if instance.ContactEmail != "" || instance.ContactAccount != nil {
+		var contact []string
+		if instance.ContactEmail != "" {
+			contact = append(contact, instance.ContactEmail)
+		}
+		if instance.ContactAccount != nil {
+			contact = append(contact, FormatAccount(*instance.ContactAccount))
+		}
+		fmt.Printf("Contact:       %s\n", strings.Join(contact, " · "))
+	}
+
+	if len(instance.Rules) > 0 {
+		fmt.Println()
+		fmt.Println("Rules:")
+		for i, rule := range instance.Rules {
+			fmt.Printf("%3d. %s\n", i+1, strings.TrimSpace(rule.Text))
+			if hint := strings.TrimSpace(rule.Hint); hint != "" {
+				fmt.Printf("     %s\n", hint)
+			}
+		}
+	}
+
+	if paragraphs := htmlParagraphs(about); len(paragraphs) > 0 {
+		fmt.Println()
+		fmt.Println("About:")
+		for _, paragraph := range paragraphs {
+			fmt.Println(WrapText(paragraph, 78))
+			fmt.Println()
+		}
+	}
+}
+
+// paragraphBreaks end a block of text in the extended description.
+var paragraphBreaks = strings.NewReplacer(
+	"</p>", "\n", "<br>", "\n", "<br/>", "\n", "<br />", "\n", "</li>", "\n",
+	"</h1>", "\n", "</h2>", "\n", "</h3>", "\n", "</h4>", "\n",
+)
+
+// htmlParagraphs splits HTML into its non-empty blocks of plain text.
+func htmlParagraphs(input string) []string {
+	var paragraphs []string
+	for _, block := range strings.Split(paragraphBreaks.Replace(input), "\n") {
+		if text := StripHTML(block); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	return paragraphs
+}
+
+func registrationsLabel(instance mastodon.Instance) string {
+	switch {
+	case !instance.Registrations:
+		return "closed"
+	case instance.ApprovalRequired:
+		return "open, approval required"
+	default:
+		return "open"
+	}
+}
+
+func supportedLabel(caps mastodon.Capabilities) string {
+	features := []struct {
+		name      string
+		supported bool
+	}{
+		{"grouped notifications", caps.GroupedNotifications},
+		{"notification policy", caps.NotificationPolicy},
+		{"edits", caps.StatusEdits},
+		{"scheduled posts", caps.ScheduledStatuses},
+		{"translation", caps.Translation},
+	}
+	var yes, no []string
+	for _, feature := range features {
+		if feature.supported {
+			yes = append(yes, feature.name)
+		} else {
+			no = append(no, feature.name)
+		}
+	}
+	label := strings.Join(yes, ", ")
+	if len(no) > 0 {
+		label += "; not " + strings.Join(no, ", ")
+	}
+	return label
+}
+
+func byteSize(size int64) string {
+	const mb = 1 << 20
+	if size >= mb {
+		return fmt.Sprintf("%.0f MB", float64(size)/mb)
+	}
+	return fmt.Sprintf("%d KB", (size+1023)/1024)
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"mastodoncli/internal/mastodon"
+)
+
+// AttachmentSummary describes an attachment as "image 1920x1080".
+func AttachmentSummary(attachment mastodon.MediaAttachment) string {
+	kind := attachment.Type
+	if kind == "" {
+		kind = "unknown"
+	}
+	if size := attachment.Meta.Original; size != nil && size.Width > 0 && size.Height > 0 {
+		return fmt.Sprintf("%s %dx%d", kind, size.Width, size.Height)
+	}
+	return kind
+}
+
+// AttachmentURL prefers the original file, falling back to the remote URL
+// for media the instance has not cached.
+func AttachmentURL(attachment mastodon.MediaAttachment) string {
+	if attachment.URL != "" {
+		return attachment.URL
+	}
+	if attachment.RemoteURL != "" {
+		return attachment.RemoteURL
+	}
+	return attachment.PreviewURL
+}
+
+func AttachmentAlt(attachment mastodon.MediaAttachment) string {
+	alt := strings.TrimSpace(attachment.Description)
+	if alt == "" {
+		return "(no description)"
+	}
+	return alt
+}
+
+// Previewable reports whether the attachment has a still image preview.
+func Previewable(attachment mastodon.MediaAttachment) bool {
+	switch attachment.Type {
+	case "image", "gifv", "video":
+		return attachment.PreviewURL != ""
+	default:
+		return false
+	}
+}
@@ -1,19 +1,203 @@
 package output
 
 import (
+	"encoding/csv"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
 
 	"mastodoncli/internal/metrics"
 )
 
-func PrintDailyMetrics(series []metrics.DailyMetric) {
+// PrintDailyMetrics prints the selected counts for each bucket; anomalies,
+// when given, is aligned with series and marks spikes and dips.
+func PrintDailyMetrics(series []metrics.DailyMetric, anomalies []metrics.Anomaly, selected []metrics.Series) {
 	if len(series) == 0 {
 		fmt.Println("No metrics returned.")
 		return
 	}
 
-	for _, day := range series {
-		fmt.Printf("%-6s  F:%d  L:%d  B:%d\n", day.Label, day.Follows, day.Likes, day.Boosts)
+	width := metrics.LabelWidth(series)
+	for i, day := range series {
+		line := fmt.Sprintf("%-*s", width, day.Label)
+		for _, kind := range selected {
+			line += fmt.Sprintf("  %s:%d", kind.Short, kind.Value(day))
+		}
+		if day.Lost > 0 {
+			line += fmt.Sprintf("  Lost:%d", day.Lost)
+		}
+		if day.Followers > 0 {
+			line += fmt.Sprintf("  Followers:%d", day.Followers)
+		}
+		if i < len(anomalies) && anomalies[i] != metrics.AnomalyNone {
+			line += "  " + anomalies[i].Marker()
+		}
+		fmt.Println(line)
 	}
-	fmt.Println(metrics.FormatTotal(series))
+	fmt.Println(metrics.FormatTotal(series, selected))
+}
+
+// PrintComparison prints each metric for the current and previous period
+// with the relative change.
+func PrintComparison(comparison metrics.Comparison) {
+	current, previous := comparison.Current, comparison.Previous
+	if len(current) == 0 || len(previous) == 0 {
+		return
+	}
+	fmt.Printf("Compared with %s – %s:\n", previous[0].Date.Format("Jan 2"), previous[len(previous)-1].Date.Format("Jan 2"))
+	for _, change := range comparison.Changes() {
+		fmt.Printf("  %-9s %5d vs %5d  %s\n", change.Name, change.Current, change.Previous, change.Percent())
+	}
+}
+
+// PrintPostsReport lists the top limit posts, then the hashtag and posting
+// hour breakdowns.
+func PrintPostsReport(report metrics.PostsReport, limit int) {
+	if len(report.Posts) == 0 {
+		fmt.Println("No posts in range.")
+		return
+	}
+
+	fmt.Println("Top posts:")
+	for i, post := range report.Posts {
+		if i == limit {
+			break
+		}
+		fmt.Printf("%2d. %s  score %d  fav %d  boost %d  reply %d\n",
+			i+1, post.CreatedAt.Format("Jan 2 15:04"), post.Score(), post.Favourites, post.Reblogs, post.Replies)
+		fmt.Printf("    1h: %s  24h: %s\n", FormatEngagement(post.FirstHour), FormatEngagement(post.FirstDay))
+		fmt.Printf("    %s\n", PostExcerpt(post, 72))
+		if post.URL != "" {
+			fmt.Printf("    %s\n", post.URL)
+		}
+	}
+
+	if len(report.Hashtags) > 0 {
+		fmt.Println()
+		fmt.Println("By hashtag:")
+		for _, entry := range report.Hashtags {
+			printBreakdown(entry)
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("By posting hour:")
+	for _, entry := range report.Hours {
+		printBreakdown(entry)
+	}
+}
+
+func printBreakdown(entry metrics.Breakdown) {
+	fmt.Printf("  %-20s %3d posts  score %4d  avg %.1f\n", entry.Key, entry.Posts, entry.Score, entry.Average())
+}
+
+// FormatEngagement renders a snapshot as "fav/boost/reply", or "n/a" when
+// none was recorded.
+func FormatEngagement(engagement *metrics.Engagement) string {
+	if engagement == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%d/%d/%d", engagement.Favourites, engagement.Reblogs, engagement.Replies)
+}
+
+// PostExcerpt returns the first width runes of the post text.
+func PostExcerpt(post metrics.PostStat, width int) string {
+	text := strings.Join(strings.Fields(StripHTML(post.Content)), " ")
+	if text == "" {
+		return "(no text)"
+	}
+	runes := []rune(text)
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return text
+}
+
+// PrintFollowerReport shows who followed and unfollowed inside the window.
+func PrintFollowerReport(store *metrics.FollowerStore, report metrics.FollowerReport) {
+	fmt.Printf("Followers: %d (snapshot %s)\n", len(store.Current), store.LastSnapshot.Local().Format("Jan 2 15:04"))
+	fmt.Printf("New %d · Lost %d · Net %+d\n", len(report.Gained), len(report.Lost), report.Net())
+	if len(store.Events) == 0 {
+		fmt.Println("No changes recorded yet; they show up once a later snapshot differs from this one.")
+		return
+	}
+	printFollowerEvents("New followers:", report.Gained)
+	printFollowerEvents("Lost followers:", report.Lost)
+}
+
+func printFollowerEvents(title string, events []metrics.FollowerEvent) {
+	if len(events) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println(title)
+	for _, event := range events {
+		fmt.Printf("  @%s  %s\n", event.Acct, event.At.Local().Format("Jan 2 15:04"))
+	}
+}
+
+type NamedHeatmap struct {
+	Name    string
+	Heatmap metrics.Heatmap
+}
+
+// PrintHeatmaps prints each heatmap as a weekday by hour matrix.
+func PrintHeatmaps(heatmaps []NamedHeatmap, zone string) {
+	for i, named := range heatmaps {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s by weekday and hour (%s), total %d, peak %s\n",
+			strings.ToUpper(named.Name[:1])+named.Name[1:], zone, named.Heatmap.Total(), named.Heatmap.Peak())
+		width := len(strconv.Itoa(named.Heatmap.Max()))
+		if width < 2 {
+			width = 2
+		}
+		fmt.Print("   ")
+		for hour := 0; hour < 24; hour++ {
+			fmt.Printf(" %*d", width, hour)
+		}
+		fmt.Println()
+		for day, row := range named.Heatmap.Cells {
+			fmt.Print(metrics.Weekdays[day])
+			for _, value := range row {
+				if value == 0 {
+					fmt.Printf(" %*s", width, ".")
+				} else {
+					fmt.Printf(" %*d", width, value)
+				}
+			}
+			fmt.Println()
+		}
+	}
+}
+
+// WriteHeatmapsCSV writes one row per source and weekday with a column per
+// hour.
+func WriteHeatmapsCSV(w io.Writer, heatmaps []NamedHeatmap) error {
+	writer := csv.NewWriter(w)
+	header := []string{"source", "weekday"}
+	for hour := 0; hour < 24; hour++ {
+		header = append(header, fmt.Sprintf("%02d", hour))
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	for _, named := range heatmaps {
+		for day, row := range named.Heatmap.Cells {
+			record := []string{named.Name, metrics.Weekdays[day]}
+			for _, value := range row {
+				record = append(record, strconv.Itoa(value))
+			}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("write csv: %w", err)
+			}
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,194 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"mastodoncli/internal/mastodon"
+)
+
+// NotificationNeedsDetail reports whether a notification type carries a
+// payload that only GET /api/v1/notifications/:id returns.
+func NotificationNeedsDetail(kind string) bool {
+	switch kind {
+	case "severed_relationships", "moderation_warning", "admin.report", "annual_report", "quote", "quoted_update":
+		return true
+	default:
+		return false
+	}
+}
+
+// NotificationHeadline says what happened, to be read after the accounts
+// that did it.
+func NotificationHeadline(kind string) string {
+	switch kind {
+	case "mention":
+		return "mentioned you"
+	case "status":
+		return "posted (you get notified of their posts)"
+	case "reblog":
+		return "boosted your post"
+	case "favourite":
+		return "favourited your post"
+	case "follow":
+		return "followed you"
+	case "follow_request":
+		return "asked to follow you"
+	case "poll":
+		return "a poll you voted in or created has ended"
+	case "update":
+		return "edited a post you interacted with"
+	case "admin.sign_up":
+		return "signed up"
+	case "admin.report":
+		return "filed a report"
+	case "severed_relationships":
+		return "some of your follows were severed by a moderation action"
+	case "moderation_warning":
+		return "moderators took action against your account"
+	case "quote":
+		return "quoted your post"
+	case "quoted_update":
+		return "edited a post you quoted"
+	case "annual_report":
+		return "your year in review is ready"
+	default:
+		return ""
+	}
+}
+
+// NotificationDetailLines describes the payload of a notification, one
+// fact per line.
+func NotificationDetailLines(detail mastodon.NotificationDetail) []string {
+	var lines []string
+	if event := detail.Event; event != nil {
+		lines = append(lines, severanceCause(*event))
+		lines = append(lines, fmt.Sprintf("Lost %d followers and %d follows.", event.FollowersCount, event.FollowingCount))
+		if event.Purged {
+			lines = append(lines, "The relationships were purged and cannot be restored.")
+		}
+	}
+	if warning := detail.ModerationWarning; warning != nil {
+		lines = append(lines, "Action: "+warningAction(warning.Action))
+		if text := strings.TrimSpace(warning.Text); text != "" {
+			lines = append(lines, "Reason: "+text)
+		}
+		if n := len(warning.StatusIDs); n > 0 {
+			lines = append(lines, fmt.Sprintf("Affects %d posts.", n))
+		}
+		if warning.Appeal != nil {
+			lines = append(lines, fmt.Sprintf("Appeal %s: %s", warning.Appeal.State, strings.TrimSpace(warning.Appeal.Text)))
+		} else {
+			lines = append(lines, "You can appeal in the web interface.")
+		}
+	}
+	if report := detail.Report; report != nil {
+		summary := fmt.Sprintf("Against @%s · %s · %d posts", report.TargetAccount.Acct, report.Category, len(report.StatusIDs))
+		if report.Forwarded {
+			summary += " · forwarded"
+		}
+		lines = append(lines, summary)
+		if comment := strings.TrimSpace(report.Comment); comment != "" {
+			lines = append(lines, "Comment: "+comment)
+		}
+	}
+	if detail.AnnualReport != nil {
+		lines = append(lines, fmt.Sprintf("Your %d in review is ready; open the web interface to see it.", detail.AnnualReport.Year))
+	}
+	if quoted := detail.QuotedStatus(); quoted != nil {
+		lines = append(lines, fmt.Sprintf("Quoting @%s: %s", quoted.Account.Acct, StripHTML(quoted.Content)))
+	}
+	return lines
+}
+
+// NotificationSummary says who did what in one line, e.g.
+// "Alice (@alice) +2 favourited your post".
+func NotificationSummary(group mastodon.GroupedNotification) string {
+	headline := NotificationHeadline(group.Type)
+	if headline == "" {
+		headline = NotificationTypeLabel(group.Type)
+	}
+	switch group.Type {
+	case "poll", "severed_relationships", "moderation_warning", "annual_report":
+		return strings.ToUpper(headline[:1]) + headline[1:]
+	}
+	return NotificationAccountsLabel(group.Accounts) + " " + headline
+}
+
+// NotificationBody is the start of the status a notification is about, or
+// only its content warning when it has one.
+func NotificationBody(group mastodon.GroupedNotification, width int) string {
+	if group.Status == nil {
+		return ""
+	}
+	if spoiler := strings.TrimSpace(StripHTML(group.Status.SpoilerText)); spoiler != "" {
+		return "CW: " + excerpt(spoiler, width)
+	}
+	return excerpt(StripHTML(group.Status.Content), width)
+}
+
+func severanceCause(event mastodon.RelationshipSeveranceEvent) string {
+	switch event.Type {
+	case "domain_block":
+		return fmt.Sprintf("An admin of your instance blocked %s.", event.TargetName)
+	case "user_domain_block":
+		return fmt.Sprintf("You blocked %s.", event.TargetName)
+	case "account_suspension":
+		return fmt.Sprintf("An admin of your instance suspended %s.", event.TargetName)
+	default:
+		return fmt.Sprintf("%s: %s", event.Type, event.TargetName)
+	}
+}
+
+func warningAction(action string) string {
+	switch action {
+	case "none":
+		return "warning"
+	case "disable":
+		return "account disabled"
+	case "mark_statuses_as_sensitive":
+		return "posts marked sensitive"
+	case "delete_statuses":
+		return "posts deleted"
+	case "sensitive":
+		return "account marked sensitive"
+	case "silence":
+		return "account limited"
+	case "suspend":
+		return "account suspended"
+	default:
+		return action
+	}
+}
+
+func PrintNotificationPolicy(policy mastodon.NotificationPolicy) {
+	rows := []struct{ name, value, about string }{
+		{"for_not_following", policy.ForNotFollowing, "people you don't follow"},
+		{"for_not_followers", policy.ForNotFollowers, "people who don't follow you"},
+		{"for_new_accounts", policy.ForNewAccounts, "accounts younger than 30 days"},
+		{"for_private_mentions", policy.ForPrivateMentions, "private mentions you didn't ask for"},
+		{"for_limited_accounts", policy.ForLimitedAccounts, "accounts limited by moderators"},
+	}
+	for _, row := range rows {
+		fmt.Printf("%-22s %-7s %s\n", row.name, row.value, row.about)
+	}
+	fmt.Printf("Pending: %d requests, %d notifications\n", policy.Summary.PendingRequestsCount, policy.Summary.PendingNotificationsCount)
+}
+
+func PrintNotificationRequests(requests []mastodon.NotificationRequest) {
+	if len(requests) == 0 {
+		fmt.Println("No notification requests.")
+		return
+	}
+	for _, request := range requests {
+		fmt.Printf("%s  %s  %s notifications  %s\n", request.ID, FormatAccount(request.Account), request.NotificationsCount, NotificationRequestExcerpt(request, 60))
+	}
+}
+
+// NotificationRequestExcerpt is the start of the request's last status.
+func NotificationRequestExcerpt(request mastodon.NotificationRequest, width int) string {
+	if request.LastStatus == nil {
+		return ""
+	}
+	return excerpt(StripHTML(request.LastStatus.Content), width)
+}
@@ -0,0 +1,105 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mastodoncli/internal/mastodon"
+)
+
+// PollPercent returns the share of votes for option, or false when the
+// server hides totals until the poll ends.
+func PollPercent(poll mastodon.Poll, option int) (int, bool) {
+	if option < 0 || option >= len(poll.Options) || poll.Options[option].VotesCount == nil {
+		return 0, false
+	}
+	total := poll.VotesCount
+	if poll.Multiple && poll.VotersCount != nil {
+		total = *poll.VotersCount
+	}
+	if total == 0 {
+		return 0, true
+	}
+	return (*poll.Options[option].VotesCount * 100) / total, true
+}
+
+func PollVotedFor(poll mastodon.Poll, option int) bool {
+	for _, vote := range poll.OwnVotes {
+		if vote == option {
+			return true
+		}
+	}
+	return false
+}
+
+// PollSummary reads like "12 votes · 9 voters · multiple choice · ends in 3h".
+func PollSummary(poll mastodon.Poll, now time.Time) string {
+	parts := []string{fmt.Sprintf("%d votes", poll.VotesCount)}
+	if poll.VotersCount != nil {
+		parts = append(parts, fmt.Sprintf("%d voters", *poll.VotersCount))
+	}
+	if poll.Multiple {
+		parts = append(parts, "multiple choice")
+	}
+	parts = append(parts, PollExpiry(poll, now))
+	if poll.Voted {
+		parts = append(parts, "voted")
+	}
+	return strings.Join(parts, " · ")
+}
+
+func PollExpiry(poll mastodon.Poll, now time.Time) string {
+	if poll.Expired {
+		return "closed"
+	}
+	if poll.ExpiresAt == "" {
+		return "no end date"
+	}
+	expires, err := time.Parse(time.RFC3339, poll.ExpiresAt)
+	if err != nil {
+		return "ends " + poll.ExpiresAt
+	}
+	remaining := expires.Sub(now)
+	if remaining <= 0 {
+		return "closed"
+	}
+	return "ends in " + FormatDuration(remaining)
+}
+
+// FormatDuration rounds to the two largest units, e.g. "2d 3h" or "5m".
+func FormatDuration(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	default:
+		return "<1m"
+	}
+}
+
+func PrintPoll(poll mastodon.Poll) {
+	fmt.Printf("%sPoll:%s   %s\n", colorCyan, colorReset, PollSummary(poll, time.Now()))
+	const barWidth = 20
+	for i, option := range poll.Options {
+		marker := " "
+		if PollVotedFor(poll, i) {
+			marker = "*"
+		}
+		pct, ok := PollPercent(poll, i)
+		if !ok {
+			fmt.Printf("  %s%d. %s\n", marker, i+1, option.Title)
+			continue
+		}
+		// Votes can outnumber voters on multiple-choice polls.
+		filled := min(pct*barWidth/100, barWidth)
+		bar := strings.Repeat("#", filled) + strings.Repeat(".", barWidth-filled)
+		fmt.Printf("  %s%d. [%s] %3d%%  %s\n", marker, i+1, bar, pct, option.Title)
+	}
+}
@@ -0,0 +1,85 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"mastodoncli/internal/mastodon"
+)
+
+// ScheduleTimeLayout is the form scheduled times are typed in; output adds
+// the zone abbreviation.
+const ScheduleTimeLayout = "2006-01-02 15:04"
+
+func PrintScheduledStatuses(statuses []mastodon.ScheduledStatus, loc *time.Location) {
+	if len(statuses) == 0 {
+		fmt.Println("No scheduled statuses.")
+		return
+	}
+	for _, status := range statuses {
+		fmt.Printf("%s  %s  %s\n", status.ID, status.Time().In(loc).Format(ScheduleTimeLayout+" MST"), ScheduledExcerpt(status, 60))
+	}
+}
+
+func PrintScheduledStatus(status mastodon.ScheduledStatus, loc *time.Location) {
+	fmt.Printf("ID:         %s\n", status.ID)
+	fmt.Printf("Scheduled:  %s (%s)\n", status.Time().In(loc).Format(ScheduleTimeLayout+" MST"), ScheduledIn(status, time.Now()))
+	for _, line := range ScheduledDetails(status) {
+		fmt.Println(line)
+	}
+	fmt.Println()
+	fmt.Println(status.Params.Text)
+}
+
+// ScheduledDetails lists the optional parameters that are set, one
+// "Name: value" line each.
+func ScheduledDetails(status mastodon.ScheduledStatus) []string {
+	params := status.Params
+	var lines []string
+	if params.Visibility != "" {
+		lines = append(lines, fmt.Sprintf("Visibility: %s", params.Visibility))
+	}
+	if params.SpoilerText != "" {
+		lines = append(lines, fmt.Sprintf("CW:         %s", params.SpoilerText))
+	}
+	if params.InReplyToID != "" {
+		lines = append(lines, fmt.Sprintf("Reply to:   %s", params.InReplyToID))
+	}
+	if params.Language != "" {
+		lines = append(lines, fmt.Sprintf("Language:   %s", params.Language))
+	}
+	if len(status.MediaAttachments) > 0 {
+		lines = append(lines, fmt.Sprintf("Media:      %d attachment(s)", len(status.MediaAttachments)))
+	}
+	if params.Poll != nil {
+		lines = append(lines, fmt.Sprintf("Poll:       %s", strings.Join(params.Poll.Options, " / ")))
+	}
+	return lines
+}
+
+// ScheduledExcerpt returns the first width runes of the text on one line.
+func ScheduledExcerpt(status mastodon.ScheduledStatus, width int) string {
+	return excerpt(status.Params.Text, width)
+}
+
+func excerpt(text string, width int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return "(no text)"
+	}
+	runes := []rune(text)
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return text
+}
+
+// ScheduledIn describes how far away the publish time is, e.g. "in 2h".
+func ScheduledIn(status mastodon.ScheduledStatus, now time.Time) string {
+	until := status.Time().Sub(now)
+	if until <= 0 {
+		return "due"
+	}
+	return "in " + FormatDuration(until)
+}
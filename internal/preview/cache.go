@@ -0,0 +1,146 @@
+package preview
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	maxDownloadBytes = 10 << 20
+	// maxPixels bounds what is decoded: a small file can claim dimensions
+	// whose pixels take gigabytes.
+	maxPixels = 40_000_000
+	// The cache is pruned when opened: files unused for maxCacheAge go
+	// first, then the least recently used until it fits maxCacheBytes.
+	maxCacheAge   = 30 * 24 * time.Hour
+	maxCacheBytes = 200 << 20
+)
+
+// Cache stores downloaded thumbnails under the user cache directory so
+// previews survive restarts without refetching.
+type Cache struct {
+	dir        string
+	httpClient *http.Client
+}
+
+func NewCache() (*Cache, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("resolve cache dir: %w", err)
+	}
+	cache := &Cache{
+		dir:        filepath.Join(base, "mastodon-cli", "media"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	cache.prune(time.Now())
+	return cache, nil
+}
+
+func (c *Cache) Image(url string) (image.Image, error) {
+	data, err := c.bytes(url)
+	if err != nil {
+		return nil, err
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, fmt.Errorf("decode image: %dx%d is too large to preview", config.Width, config.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	return img, nil
+}
+
+func (c *Cache) bytes(url string) ([]byte, error) {
+	sum := sha256.Sum256([]byte(url))
+	path := filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+		return data, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read cached media: %w", err)
+	}
+
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("download media: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download media: %s", resp.Status)
+	}
+	data, err = io.ReadAll(io.LimitReader(resp.Body, maxDownloadBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("download media: %w", err)
+	}
+	if len(data) > maxDownloadBytes {
+		return nil, fmt.Errorf("download media: larger than %d bytes", maxDownloadBytes)
+	}
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create cache dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("write cached media: %w", err)
+	}
+	return data, nil
+}
+
+// prune removes cached files unused for maxCacheAge, then the least
+// recently used ones until the rest fit maxCacheBytes. Files it cannot
+// read or remove are left for the next run.
+func (c *Cache) prune(now time.Time) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	type cached struct {
+		path string
+		size int64
+		used time.Time
+	}
+	var files []cached
+	var total int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		path := filepath.Join(c.dir, entry.Name())
+		if now.Sub(info.ModTime()) > maxCacheAge {
+			_ = os.Remove(path)
+			continue
+		}
+		files = append(files, cached{path: path, size: info.Size(), used: info.ModTime()})
+		total += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].used.Before(files[j].used) })
+	for _, file := range files {
+		if total <= maxCacheBytes {
+			return
+		}
+		if os.Remove(file.path) == nil {
+			total -= file.size
+		}
+	}
+}
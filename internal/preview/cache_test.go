@@ -0,0 +1,54 @@
+package preview
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheImageRefusesHugeDimensions(t *testing.T) {
+	cache := &Cache{dir: t.TempDir()}
+	url := "https://files.example/bomb.gif"
+	sum := sha256.Sum256([]byte(url))
+	// A GIF header claiming 65535x65535 pixels.
+	header := []byte("GIF89a\xff\xff\xff\xff\x00\x00\x00")
+	if err := os.WriteFile(filepath.Join(cache.dir, hex.EncodeToString(sum[:])), header, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := cache.Image(url)
+	if err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Fatalf("expected the image to be refused, got %v", err)
+	}
+}
+
+func TestCachePruneDropsOldAndLeastRecentlyUsed(t *testing.T) {
+	cache := &Cache{dir: t.TempDir()}
+	now := time.Now()
+	write := func(name string, size int64, used time.Time) {
+		path := filepath.Join(cache.dir, name)
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Truncate(path, size); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, used, used); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("stale", 1, now.Add(-maxCacheAge-time.Hour))
+	write("older", maxCacheBytes/2+1, now.Add(-2*time.Hour))
+	write("newer", maxCacheBytes/2, now.Add(-time.Hour))
+
+	cache.prune(now)
+	for name, want := range map[string]bool{"stale": false, "older": false, "newer": true} {
+		if _, err := os.Stat(filepath.Join(cache.dir, name)); (err == nil) != want {
+			t.Fatalf("%s kept = %v, want %v", name, err == nil, want)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"strings"
+)
+
+type Protocol string
+
+const (
+	ProtocolOff       Protocol = "off"
+	ProtocolAuto      Protocol = "auto"
+	ProtocolKitty     Protocol = "kitty"
+	ProtocolITerm2    Protocol = "iterm2"
+	ProtocolSixel     Protocol = "sixel"
+	ProtocolHalfBlock Protocol = "halfblock"
+)
+
+// cellPixels approximates the width of a terminal cell for protocols that
+// work in pixels.
+const cellPixels = 8
+
+func ParseProtocol(value string) (Protocol, error) {
+	switch Protocol(value) {
+	case "":
+		return ProtocolOff, nil
+	case ProtocolOff, ProtocolAuto, ProtocolKitty, ProtocolITerm2, ProtocolSixel, ProtocolHalfBlock:
+		return Protocol(value), nil
+	default:
+		return "", fmt.Errorf("media_previews must be one of: off, auto, kitty, iterm2, sixel, halfblock")
+	}
+}
+
+// Detect picks the best graphics protocol for the current terminal, falling
+// back to ANSI half blocks.
+func Detect() Protocol {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || program == "ghostty":
+		return ProtocolKitty
+	case program == "iTerm.app" || program == "WezTerm":
+		return ProtocolITerm2
+	case strings.Contains(term, "sixel") || term == "foot" || term == "mlterm" || program == "mlterm":
+		return ProtocolSixel
+	default:
+		return ProtocolHalfBlock
+	}
+}
+
+// Resolve turns auto into a concrete protocol.
+func (p Protocol) Resolve() Protocol {
+	if p == ProtocolAuto {
+		return Detect()
+	}
+	return p
+}
+
+// Render draws img at most cols cells wide and rows cells tall.
+func Render(img image.Image, protocol Protocol, cols, rows int) (string, error) {
+	switch protocol.Resolve() {
+	case ProtocolKitty:
+		return kitty(img, cols)
+	case ProtocolITerm2:
+		return iterm2(img, cols)
+	case ProtocolSixel:
+		return Sixel(img, cols, rows), nil
+	case ProtocolHalfBlock:
+		return HalfBlocks(img, cols, rows), nil
+	default:
+		return "", nil
+	}
+}
+
+// HalfBlocks renders two pixel rows per cell using "▀" with truecolor
+// foreground and background. It is the only mode that is safe inside the
+// TUI viewport.
+func HalfBlocks(img image.Image, cols, rows int) string {
+	width, height := fit(img.Bounds(), cols, rows*2)
+	if width == 0 || height == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x++ {
+			tr, tg, tb := sample(img, x, y, width, height)
+			br, bg, bb := tr, tg, tb
+			if y+1 < height {
+				br, bg, bb = sample(img, x, y+1, width, height)
+			}
+			fmt.Fprintf(&builder, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", tr, tg, tb, br, bg, bb)
+		}
+		builder.WriteString("\x1b[0m")
+		if y+2 < height {
+			builder.WriteByte('\n')
+		}
+	}
+	return builder.String()
+}
+
+// Sixel encodes img with a 6x6x6 color cube palette.
+func Sixel(img image.Image, cols, rows int) string {
+	width, height := fit(img.Bounds(), cols*cellPixels, rows*cellPixels*2)
+	if width == 0 || height == 0 {
+		return ""
+	}
+
+	indexes := make([]uint8, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b := sample(img, x, y, width, height)
+			indexes[y*width+x] = uint8(int(r)*6/256*36 + int(g)*6/256*6 + int(b)*6/256)
+		}
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "\x1bPq\"1;1;%d;%d", width, height)
+	for i := 0; i < 216; i++ {
+		fmt.Fprintf(&builder, "#%d;2;%d;%d;%d", i, (i/36)*100/5, ((i/6)%6)*100/5, (i%6)*100/5)
+	}
+
+	band := make([]byte, width)
+	for top := 0; top < height; top += 6 {
+		var used [216]bool
+		for y := top; y < top+6 && y < height; y++ {
+			for x := 0; x < width; x++ {
+				used[indexes[y*width+x]] = true
+			}
+		}
+		for color := 0; color < 216; color++ {
+			if !used[color] {
+				continue
+			}
+			for x := 0; x < width; x++ {
+				bits := 0
+				for dy := 0; dy < 6 && top+dy < height; dy++ {
+					if int(indexes[(top+dy)*width+x]) == color {
+						bits |= 1 << dy
+					}
+				}
+				band[x] = byte(63 + bits)
+			}
+			fmt.Fprintf(&builder, "#%d", color)
+			writeSixelRuns(&builder, band)
+			builder.WriteByte('$')
+		}
+		builder.WriteByte('-')
+	}
+	builder.WriteString("\x1b\\")
+	return builder.String()
+}
+
+func writeSixelRuns(builder *strings.Builder, band []byte) {
+	for i := 0; i < len(band); {
+		j := i
+		for j < len(band) && band[j] == band[i] {
+			j++
+		}
+		if run := j - i; run > 3 {
+			fmt.Fprintf(builder, "!%d%c", run, band[i])
+		} else {
+			builder.WriteString(strings.Repeat(string(band[i]), run))
+		}
+		i = j
+	}
+}
+
+func kitty(img image.Image, cols int) (string, error) {
+	encoded, err := encodePNG(img)
+	if err != nil {
+		return "", err
+	}
+
+	const chunkSize = 4096
+	var builder strings.Builder
+	for offset := 0; offset < len(encoded); offset += chunkSize {
+		end := offset + chunkSize
+		more := 1
+		if end >= len(encoded) {
+			end = len(encoded)
+			more = 0
+		}
+		if offset == 0 {
+			fmt.Fprintf(&builder, "\x1b_Ga=T,f=100,c=%d,m=%d;%s\x1b\\", cols, more, encoded[offset:end])
+		} else {
+			fmt.Fprintf(&builder, "\x1b_Gm=%d;%s\x1b\\", more, encoded[offset:end])
+		}
+	}
+	return builder.String(), nil
+}
+
+func iterm2(img image.Image, cols int) (string, error) {
+	encoded, err := encodePNG(img)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("\x1b]1337;File=inline=1;width=%d;preserveAspectRatio=1:%s\a", cols, encoded), nil
+}
+
+func encodePNG(img image.Image) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("encode preview: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// fit scales bounds down to at most maxWidth x maxHeight, keeping the
+// aspect ratio. Images are never scaled up.
+func fit(bounds image.Rectangle, maxWidth, maxHeight int) (int, int) {
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 || maxWidth <= 0 || maxHeight <= 0 {
+		return 0, 0
+	}
+	if width > maxWidth {
+		height = height * maxWidth / width
+		width = maxWidth
+	}
+	if height > maxHeight {
+		width = width * maxHeight / height
+		height = maxHeight
+	}
+	if width == 0 {
+		width = 1
+	}
+	if height == 0 {
+		height = 1
+	}
+	return width, height
+}
+
+func sample(img image.Image, x, y, width, height int) (uint8, uint8, uint8) {
+	bounds := img.Bounds()
+	sx := bounds.Min.X + x*bounds.Dx()/width
+	sy := bounds.Min.Y + y*bounds.Dy()/height
+	r, g, b, _ := img.At(sx, sy).RGBA()
+	return uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)
+}
@@ -0,0 +1,46 @@
+package preview
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestHalfBlocksFitsRequestedSize(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	for x := 0; x < 100; x++ {
+		for y := 0; y < 50; y++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	out := HalfBlocks(img, 20, 10)
+	lines := strings.Split(out, "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 rows, got %d", len(lines))
+	}
+	if got := strings.Count(lines[0], "▀"); got != 20 {
+		t.Fatalf("expected 20 cells, got %d", got)
+	}
+	if !strings.Contains(lines[0], "\x1b[38;2;255;0;0m") {
+		t.Fatalf("expected red foreground, got %q", lines[0])
+	}
+}
+
+func TestSixelFraming(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	out := Sixel(img, 1, 1)
+	if !strings.HasPrefix(out, "\x1bPq\"1;1;4;4") || !strings.HasSuffix(out, "\x1b\\") {
+		t.Fatalf("unexpected sixel framing: %q", out)
+	}
+}
+
+func TestParseProtocol(t *testing.T) {
+	if p, err := ParseProtocol(""); err != nil || p != ProtocolOff {
+		t.Fatalf("expected empty value to mean off, got %q %v", p, err)
+	}
+	if _, err := ParseProtocol("ascii"); err == nil {
+		t.Fatal("expected error for unknown protocol")
+	}
+}
@@ -0,0 +1,328 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"mastodoncli/internal/config"
+	"mastodoncli/internal/mastodon"
+)
+
+type Action string
+
+const (
+	ActionNone      Action = ""
+	ActionHighlight Action = "highlight"
+	ActionCollapse  Action = "collapse"
+	ActionHide      Action = "hide"
+)
+
+type Context string
+
+const (
+	ContextHome          Context = "home"
+	ContextPublic        Context = "public"
+	ContextNotifications Context = "notifications"
+)
+
+type Field string
+
+const (
+	FieldContent    Field = "content"
+	FieldAccount    Field = "account"
+	FieldDomain     Field = "domain"
+	FieldLanguage   Field = "language"
+	FieldBoostsFrom Field = "boosts_from"
+	FieldHasMedia   Field = "has_media"
+)
+
+// Rule matches a single field against a keyword or a regular expression.
+// Keywords match case-insensitively: as a substring for content and as a
+// whole value for the other fields. has_media rules use "true" or "false".
+type Rule struct {
+	Name     string    `json:"name,omitempty"`
+	Field    Field     `json:"field"`
+	Pattern  string    `json:"pattern"`
+	Regex    bool      `json:"regex,omitempty"`
+	Action   Action    `json:"action"`
+	Contexts []Context `json:"contexts,omitempty"`
+}
+
+type File struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Verdict is the strongest action of all rules matching an item.
+type Verdict struct {
+	Action Action
+	Rule   string
+}
+
+func (v Verdict) Matched() bool {
+	return v.Action != ActionNone
+}
+
+func (v Verdict) Reason() string {
+	if !v.Matched() {
+		return ""
+	}
+	return fmt.Sprintf("%s by rule %q", v.Action, v.Rule)
+}
+
+type Set struct {
+	rules []compiledRule
+	// local is the host of the instance, the domain of accounts whose acct
+	// has none.
+	local string
+}
+
+type compiledRule struct {
+	Rule
+	pattern *regexp.Regexp
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rules.json"), nil
+}
+
+func Load() (*Set, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Set{}, nil
+		}
+		return nil, fmt.Errorf("read rules: %w", err)
+	}
+
+	return Parse(data)
+}
+
+func Parse(data []byte) (*Set, error) {
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse rules: %w", err)
+	}
+
+	set := &Set{rules: make([]compiledRule, 0, len(file.Rules))}
+	for i, rule := range file.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("#%d", i+1)
+		}
+		compiled, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+		set.rules = append(set.rules, compiled)
+	}
+	return set, nil
+}
+
+func compile(rule Rule) (compiledRule, error) {
+	switch rule.Action {
+	case ActionHide, ActionCollapse, ActionHighlight:
+	default:
+		return compiledRule{}, fmt.Errorf("action must be one of: hide, collapse, highlight")
+	}
+	for _, context := range rule.Contexts {
+		switch context {
+		case ContextHome, ContextPublic, ContextNotifications:
+		default:
+			return compiledRule{}, fmt.Errorf("unknown context: %s", context)
+		}
+	}
+	if rule.Pattern == "" {
+		return compiledRule{}, fmt.Errorf("pattern is required")
+	}
+
+	compiled := compiledRule{Rule: rule}
+	switch rule.Field {
+	case FieldContent, FieldAccount, FieldDomain, FieldLanguage, FieldBoostsFrom:
+	case FieldHasMedia:
+		if rule.Pattern != "true" && rule.Pattern != "false" {
+			return compiledRule{}, fmt.Errorf("has_media pattern must be true or false")
+		}
+		return compiled, nil
+	default:
+		return compiledRule{}, fmt.Errorf("unknown field: %s", rule.Field)
+	}
+
+	if rule.Regex {
+		pattern, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			return compiledRule{}, fmt.Errorf("invalid regex: %w", err)
+		}
+		compiled.pattern = pattern
+	}
+	return compiled, nil
+}
+
+// SetInstance names the instance the rules are used with, so domain rules
+// match its local accounts too.
+func (s *Set) SetInstance(instance string) {
+	s.local = instance
+	if parsed, err := url.Parse(instance); err == nil && parsed.Host != "" {
+		s.local = parsed.Host
+	}
+}
+
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.rules)
+}
+
+// Status returns the verdict for a status shown in the given context.
+// Boosts are matched against the boosted status, except for boosts_from.
+func (s *Set) Status(status mastodon.Status, context Context) Verdict {
+	if s.Len() == 0 {
+		return Verdict{}
+	}
+
+	var verdict Verdict
+	for _, rule := range s.rules {
+		if !rule.appliesTo(context) || !rule.matchStatus(status, s.local) {
+			continue
+		}
+		verdict = stronger(verdict, Verdict{Action: rule.Action, Rule: rule.Name})
+	}
+	return verdict
+}
+
+// Notification matches the notification status, if any, and the accounts
+// that triggered it.
+func (s *Set) Notification(item mastodon.GroupedNotification) Verdict {
+	if s.Len() == 0 {
+		return Verdict{}
+	}
+
+	var verdict Verdict
+	for _, rule := range s.rules {
+		if !rule.appliesTo(ContextNotifications) {
+			continue
+		}
+		matched := item.Status != nil && rule.matchStatus(*item.Status, s.local)
+		if !matched && (rule.Field == FieldAccount || rule.Field == FieldDomain) {
+			for _, account := range item.Accounts {
+				if rule.matchAccount(account, s.local) {
+					matched = true
+					break
+				}
+			}
+		}
+		if matched {
+			verdict = stronger(verdict, Verdict{Action: rule.Action, Rule: rule.Name})
+		}
+	}
+	return verdict
+}
+
+func (r compiledRule) appliesTo(context Context) bool {
+	if len(r.Contexts) == 0 {
+		return true
+	}
+	for _, candidate := range r.Contexts {
+		if candidate == context {
+			return true
+		}
+	}
+	return false
+}
+
+func (r compiledRule) matchStatus(status mastodon.Status, local string) bool {
+	display := &status
+	if status.Reblog != nil {
+		display = status.Reblog
+	}
+
+	switch r.Field {
+	case FieldContent:
+		text := plainText(display.SpoilerText) + "\n" + plainText(display.Content)
+		if r.pattern != nil {
+			return r.pattern.MatchString(text)
+		}
+		return strings.Contains(strings.ToLower(text), strings.ToLower(r.Pattern))
+	case FieldAccount, FieldDomain:
+		return r.matchAccount(display.Account, local)
+	case FieldLanguage:
+		return r.matchValue(display.Language)
+	case FieldBoostsFrom:
+		if status.Reblog == nil {
+			return false
+		}
+		return r.matchValue(status.Account.Acct)
+	case FieldHasMedia:
+		return (len(display.MediaAttachments) > 0) == (r.Pattern == "true")
+	default:
+		return false
+	}
+}
+
+func (r compiledRule) matchAccount(account mastodon.Account, local string) bool {
+	switch r.Field {
+	case FieldAccount:
+		return r.matchValue(account.Acct)
+	case FieldDomain:
+		return r.matchValue(accountDomain(account, local))
+	default:
+		return false
+	}
+}
+
+func (r compiledRule) matchValue(value string) bool {
+	if r.pattern != nil {
+		return r.pattern.MatchString(value)
+	}
+	return strings.EqualFold(strings.TrimPrefix(value, "@"), strings.TrimPrefix(r.Pattern, "@"))
+}
+
+// accountDomain returns the domain part of acct, or local for local
+// accounts, whose acct has none.
+func accountDomain(account mastodon.Account, local string) string {
+	_, domain, ok := strings.Cut(account.Acct, "@")
+	if !ok {
+		return local
+	}
+	return domain
+}
+
+func plainText(value string) string {
+	return html.UnescapeString(tagPattern.ReplaceAllString(value, " "))
+}
+
+func stronger(a, b Verdict) Verdict {
+	if rank(b.Action) > rank(a.Action) {
+		return b
+	}
+	return a
+}
+
+func rank(action Action) int {
+	switch action {
+	case ActionHighlight:
+		return 1
+	case ActionCollapse:
+		return 2
+	case ActionHide:
+		return 3
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,98 @@
+package rules
+
+import (
+	"testing"
+
+	"mastodoncli/internal/mastodon"
+)
+
+func TestSetStatusPicksStrongestAction(t *testing.T) {
+	set, err := Parse([]byte(`{"rules": [
+		{"name": "crypto", "field": "content", "pattern": "crypto", "action": "collapse"},
+		{"name": "spam", "field": "domain", "pattern": "spam\\.example$", "regex": true, "action": "hide", "contexts": ["public"]},
+		{"name": "friends", "field": "account", "pattern": "@alice", "action": "highlight"}
+	]}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	status := mastodon.Status{
+		Content: "<p>All about <b>Crypto</b></p>",
+		Account: mastodon.Account{Acct: "bob@spam.example"},
+	}
+
+	if got := set.Status(status, ContextHome); got.Action != ActionCollapse || got.Rule != "crypto" {
+		t.Fatalf("unexpected home verdict: %+v", got)
+	}
+	if got := set.Status(status, ContextPublic); got.Action != ActionHide || got.Rule != "spam" {
+		t.Fatalf("unexpected public verdict: %+v", got)
+	}
+
+	friend := mastodon.Status{Account: mastodon.Account{Acct: "alice"}}
+	if got := set.Status(friend, ContextHome); got.Action != ActionHighlight {
+		t.Fatalf("unexpected verdict for friend: %+v", got)
+	}
+}
+
+func TestSetStatusBoostsFrom(t *testing.T) {
+	set, err := Parse([]byte(`{"rules": [
+		{"field": "boosts_from", "pattern": "carol", "action": "hide"},
+		{"field": "has_media", "pattern": "true", "action": "collapse", "contexts": ["home"]}
+	]}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	boost := mastodon.Status{
+		Account: mastodon.Account{Acct: "carol"},
+		Reblog:  &mastodon.Status{Account: mastodon.Account{Acct: "dave"}},
+	}
+	if got := set.Status(boost, ContextHome); got.Action != ActionHide || got.Rule != "#1" {
+		t.Fatalf("unexpected boost verdict: %+v", got)
+	}
+	if got := set.Status(*boost.Reblog, ContextHome); got.Matched() {
+		t.Fatalf("expected original status to pass: %+v", got)
+	}
+
+	media := mastodon.Status{MediaAttachments: []mastodon.MediaAttachment{{Type: "image"}}}
+	if got := set.Status(media, ContextPublic); got.Matched() {
+		t.Fatalf("expected has_media rule to be scoped to home: %+v", got)
+	}
+}
+
+func TestSetDomainMatchesLocalAccounts(t *testing.T) {
+	set, err := Parse([]byte(`{"rules": [
+		{"field": "domain", "pattern": "social.example", "action": "collapse"}
+	]}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	local := mastodon.Status{Account: mastodon.Account{Acct: "alice"}}
+	if got := set.Status(local, ContextHome); got.Matched() {
+		t.Fatalf("expected no match before the instance is known: %+v", got)
+	}
+	set.SetInstance("https://social.example")
+	if got := set.Status(local, ContextHome); got.Action != ActionCollapse {
+		t.Fatalf("expected the local account to match its instance: %+v", got)
+	}
+	remote := mastodon.Status{Account: mastodon.Account{Acct: "alice@other.example"}}
+	if got := set.Status(remote, ContextHome); got.Matched() {
+		t.Fatalf("expected a remote account to keep its own domain: %+v", got)
+	}
+}
+
+func TestParseRejectsInvalidRules(t *testing.T) {
+	cases := []string{
+		`{"rules": [{"field": "content", "pattern": "x", "action": "delete"}]}`,
+		`{"rules": [{"field": "nope", "pattern": "x", "action": "hide"}]}`,
+		`{"rules": [{"field": "content", "pattern": "(", "regex": true, "action": "hide"}]}`,
+		`{"rules": [{"field": "has_media", "pattern": "yes", "action": "hide"}]}`,
+		`{"rules": [{"field": "content", "pattern": "x", "action": "hide", "contexts": ["thread"]}]}`,
+	}
+	for _, input := range cases {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Fatalf("expected error for %s", input)
+		}
+	}
+}
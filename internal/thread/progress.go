@@ -0,0 +1,76 @@
+package thread
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"time"
+
+	"mastodoncli/internal/config"
+)
+
+// Progress records which parts of a thread are already posted, so a run
+// that failed midway can continue where it stopped.
+type Progress struct {
+	Parts      []string  `json:"parts"`
+	PostedIDs  []string  `json:"posted_ids"`
+	Visibility string    `json:"visibility,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// LastID returns the status the next part replies to.
+func (p *Progress) LastID() string {
+	if len(p.PostedIDs) == 0 {
+		return ""
+	}
+	return p.PostedIDs[len(p.PostedIDs)-1]
+}
+
+type ProgressStore struct {
+	Threads map[string]*Progress `json:"threads"`
+}
+
+// Key identifies a thread by its parts and the settings they are posted
+// with; running the same command again finds the same entry.
+func Key(parts []string, settings ...string) string {
+	hash := sha256.New()
+	for _, values := range [][]string{parts, settings} {
+		for _, value := range values {
+			hash.Write([]byte(value))
+			hash.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func ProgressPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "threads.json"), nil
+}
+
+func LoadProgress() (*ProgressStore, error) {
+	path, err := ProgressPath()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &ProgressStore{Threads: map[string]*Progress{}}
+	if err := config.ReadJSON(path, "thread progress", store); err != nil {
+		return nil, err
+	}
+	if store.Threads == nil {
+		store.Threads = map[string]*Progress{}
+	}
+	return store, nil
+}
+
+func (s *ProgressStore) Save() error {
+	path, err := ProgressPath()
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, "thread progress", s)
+}
@@ -0,0 +1,180 @@
+package thread
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	urlPattern       = regexp.MustCompile(`https?://[^\s<>"]+`)
+	mentionPattern   = regexp.MustCompile(`(@[A-Za-z0-9_]+)@[A-Za-z0-9.-]+[A-Za-z0-9]`)
+	paragraphPattern = regexp.MustCompile(`\n[ \t]*\n`)
+	sentenceEnd      = regexp.MustCompile(`[.!?…]+["'”’)\]]*\s+`)
+	breakLine        = regexp.MustCompile(`(?m)^[ \t]*---+[ \t]*$`)
+)
+
+type Options struct {
+	// MaxCharacters is the budget per part, after any content warning.
+	MaxCharacters int
+	// URLLength is what a link counts for, whatever its real length.
+	URLLength int
+	// Counters appends "1/n" to every part.
+	Counters bool
+}
+
+// Length counts text the way Mastodon does: every link costs URLLength and
+// mentions count without their domain.
+func Length(text string, urlLength int) int {
+	text = mentionPattern.ReplaceAllString(text, "$1")
+	length := 0
+	last := 0
+	for _, match := range urlPattern.FindAllStringIndex(text, -1) {
+		length += utf8.RuneCountInString(text[last:match[0]]) + urlLength
+		last = match[1]
+	}
+	return length + utf8.RuneCountInString(text[last:])
+}
+
+// Split breaks text into parts that fit MaxCharacters. It keeps paragraphs
+// together where it can, then falls back to sentences, words and finally
+// characters. A line of "---" forces a break.
+func Split(text string, opts Options) ([]string, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("thread text is empty")
+	}
+	if !opts.Counters {
+		return pack(text, opts.MaxCharacters, opts.URLLength)
+	}
+
+	// The counter width depends on the number of parts, so grow the
+	// reservation until the split agrees with it.
+	for digits := 1; ; digits++ {
+		budget := opts.MaxCharacters - counterLength(digits)
+		parts, err := pack(text, budget, opts.URLLength)
+		if err != nil {
+			return nil, err
+		}
+		if len(fmt.Sprint(len(parts))) > digits {
+			continue
+		}
+		for i := range parts {
+			parts[i] += counter(i+1, len(parts))
+		}
+		return parts, nil
+	}
+}
+
+func counter(index, total int) string {
+	return fmt.Sprintf("\n\n%d/%d", index, total)
+}
+
+func counterLength(digits int) int {
+	return 2 + 2*digits + 1
+}
+
+func pack(text string, budget, urlLength int) ([]string, error) {
+	if budget < 20 {
+		return nil, fmt.Errorf("only %d characters left per part; raise the limit or shorten the content warning", budget)
+	}
+	fits := func(s string) bool { return Length(s, urlLength) <= budget }
+
+	var parts []string
+	for _, section := range breakLine.Split(text, -1) {
+		current := ""
+		flush := func() {
+			if current != "" {
+				parts = append(parts, current)
+				current = ""
+			}
+		}
+		for _, paragraph := range paragraphPattern.Split(strings.TrimSpace(section), -1) {
+			paragraph = strings.TrimSpace(paragraph)
+			if paragraph == "" {
+				continue
+			}
+			if current != "" && fits(current+"\n\n"+paragraph) {
+				current += "\n\n" + paragraph
+				continue
+			}
+			flush()
+			if fits(paragraph) {
+				current = paragraph
+				continue
+			}
+			for _, piece := range splitOversized(paragraph, fits) {
+				if current != "" && fits(current+" "+piece) {
+					current += " " + piece
+					continue
+				}
+				flush()
+				current = piece
+			}
+		}
+		flush()
+	}
+	return parts, nil
+}
+
+// splitOversized cuts a paragraph into pieces that each fit, preferring
+// sentence ends over word gaps over arbitrary characters.
+func splitOversized(paragraph string, fits func(string) bool) []string {
+	var pieces []string
+	for _, sentence := range sentences(paragraph) {
+		if fits(sentence) {
+			pieces = append(pieces, sentence)
+			continue
+		}
+		for _, word := range strings.Fields(sentence) {
+			if fits(word) {
+				pieces = append(pieces, word)
+				continue
+			}
+			pieces = append(pieces, splitRunes(word, fits)...)
+		}
+	}
+	return pieces
+}
+
+func sentences(paragraph string) []string {
+	var out []string
+	last := 0
+	for _, match := range sentenceEnd.FindAllStringIndex(paragraph, -1) {
+		out = append(out, strings.TrimSpace(paragraph[last:match[1]]))
+		last = match[1]
+	}
+	if rest := strings.TrimSpace(paragraph[last:]); rest != "" {
+		out = append(out, rest)
+	}
+	return out
+}
+
+func splitRunes(word string, fits func(string) bool) []string {
+	var out []string
+	runes := []rune(word)
+	for len(runes) > 0 {
+		n := len(runes)
+		for n > 1 && !fits(string(runes[:n])) {
+			n--
+		}
+		out = append(out, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return out
+}
+
+// visibilityRank orders visibilities from most to least public.
+var visibilityRank = map[string]int{"public": 0, "unlisted": 1, "private": 2, "direct": 3}
+
+// FollowUpVisibility returns the visibility for replies after the first
+// part: the configured one, unless the first part is more restricted.
+func FollowUpVisibility(first, configured string) string {
+	if configured == "" {
+		return first
+	}
+	if visibilityRank[first] > visibilityRank[configured] {
+		return first
+	}
+	return configured
+}
@@ -0,0 +1,60 @@
+package thread
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitKeepsParagraphsAndAddsCounters(t *testing.T) {
+	text := strings.Repeat("a", 60) + "\n\n" + strings.Repeat("b", 60) + "\n\n" + strings.Repeat("c", 60)
+
+	parts, err := Split(text, Options{MaxCharacters: 130, URLLength: 23, Counters: true})
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 parts, got %d: %q", len(parts), parts)
+	}
+	if parts[0] != strings.Repeat("a", 60)+"\n\n"+strings.Repeat("b", 60)+"\n\n1/2" {
+		t.Fatalf("unexpected first part: %q", parts[0])
+	}
+	if !strings.HasSuffix(parts[1], "\n\n2/2") {
+		t.Fatalf("expected a counter on the last part: %q", parts[1])
+	}
+	for _, part := range parts {
+		if Length(part, 23) > 130 {
+			t.Fatalf("part over the limit: %d", Length(part, 23))
+		}
+	}
+}
+
+func TestSplitFallsBackToSentencesAndCountsLinksAsURLLength(t *testing.T) {
+	link := "https://example.com/" + strings.Repeat("x", 200)
+	text := "First sentence is here. Second one follows! " + link + " is a long link."
+
+	parts, err := Split(text, Options{MaxCharacters: 50, URLLength: 23})
+	if err != nil {
+		t.Fatalf("split: %v", err)
+	}
+	want := []string{"First sentence is here. Second one follows!", link + " is a long link."}
+	if len(parts) != len(want) {
+		t.Fatalf("expected %d parts, got %q", len(want), parts)
+	}
+	for i := range want {
+		if parts[i] != want[i] {
+			t.Fatalf("part %d: got %q, want %q", i, parts[i], want[i])
+		}
+	}
+}
+
+func TestFollowUpVisibilityNeverWidens(t *testing.T) {
+	if got := FollowUpVisibility("public", "unlisted"); got != "unlisted" {
+		t.Fatalf("expected unlisted, got %q", got)
+	}
+	if got := FollowUpVisibility("private", "unlisted"); got != "private" {
+		t.Fatalf("expected private, got %q", got)
+	}
+	if got := FollowUpVisibility("unlisted", ""); got != "unlisted" {
+		t.Fatalf("expected unlisted, got %q", got)
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+	"mastodoncli/internal/preview"
+	"mastodoncli/internal/rules"
 	"mastodoncli/internal/ui/components"
 )
 
@@ -20,21 +23,47 @@ const (
 	tabProfile
 	tabMetrics
 	tabNotifications
+	tabScheduled
+	tabCount
 )
 
+// Options carries user settings loaded by the CLI into the TUI.
+type Options struct {
+	Rules    *rules.Set
+	CW       output.CWPolicy
+	Previews bool
+	Cache    *preview.Cache
+	// Account tags drafts written in the composer.
+	Account string
+}
+
 type model struct {
-	client            *mastodon.Client
-	activeTab         topTab
-	activeTimeline    timelineMode
-	timelineViews     map[timelineMode]*feedView
-	profileView       *feedView
-	notificationsView *notificationsView
-	metricsView       *metricsView
-	searchView        searchView
-	profileAccountID  string
-	spinner           spinner.Model
-	width             int
-	height            int
+	client         *mastodon.Client
+	rules          *rules.Set
+	cw             output.CWPolicy
+	showHidden     bool
+	previews       *previewState
+	showPreviews   bool
+	pollSelections map[string]map[int]bool
+	histories      map[string]*editHistory
+	// notificationDetails and followRequests are keyed by notification
+	// and account ID.
+	notificationDetails map[string]*notificationPayload
+	followRequests      map[string]string
+	markers             markersState
+	activeTab           topTab
+	activeTimeline      timelineMode
+	timelineViews       map[timelineMode]*feedView
+	profileView         *feedView
+	notificationsView   *notificationsView
+	metricsView         *metricsView
+	scheduledView       *scheduledView
+	composer            *composer
+	searchView          searchView
+	profileAccountID    string
+	spinner             spinner.Model
+	width               int
+	height              int
 }
 
 type feedErrMsg struct {
@@ -43,39 +72,49 @@ type feedErrMsg struct {
 	err  error
 }
 
-func Run(client *mastodon.Client) error {
-	m := newModel(client)
+func Run(client *mastodon.Client, opts Options) error {
+	m := newModel(client, opts)
 	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
 	return err
 }
 
-func newModel(client *mastodon.Client) model {
+func newModel(client *mastodon.Client, opts Options) model {
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
 	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("86"))
 
 	timelineViews := map[timelineMode]*feedView{
-		modeHome:      newFeedView("Home timeline"),
-		modeLocal:     newFeedView("Local timeline"),
-		modeFederated: newFeedView("Federated timeline"),
-		modeTrending:  newFeedView("Trending"),
+		modeHome:      newFeedView("Home timeline", rules.ContextHome),
+		modeLocal:     newFeedView("Local timeline", rules.ContextPublic),
+		modeFederated: newFeedView("Federated timeline", rules.ContextPublic),
+		modeTrending:  newFeedView("Trending", rules.ContextPublic),
 	}
 
-	profile := newFeedView("Profile")
+	profile := newFeedView("Profile", "")
 	metricsView := newMetricsView("Metrics")
 	notifications := newNotificationsView("Notifications")
 	search := newSearchView()
 
 	return model{
-		client:            client,
-		activeTab:         tabTimeline,
-		activeTimeline:    modeHome,
-		timelineViews:     timelineViews,
-		profileView:       profile,
-		metricsView:       metricsView,
-		notificationsView: notifications,
-		searchView:        search,
-		spinner:           sp,
+		client:              client,
+		rules:               opts.Rules,
+		cw:                  opts.CW,
+		previews:            newPreviewState(opts.Cache),
+		showPreviews:        opts.Previews,
+		pollSelections:      make(map[string]map[int]bool),
+		histories:           make(map[string]*editHistory),
+		notificationDetails: make(map[string]*notificationPayload),
+		followRequests:      make(map[string]string),
+		activeTab:           tabTimeline,
+		activeTimeline:      modeHome,
+		timelineViews:       timelineViews,
+		profileView:         profile,
+		metricsView:         metricsView,
+		notificationsView:   notifications,
+		scheduledView:       newScheduledView("Scheduled"),
+		composer:            newComposer(opts.Account),
+		searchView:          search,
+		spinner:             sp,
 	}
 }
 
@@ -83,7 +122,7 @@ func (m model) Init() tea.Cmd {
 	m.timelineView().list.SetItems([]list.Item{loadingTimelineItem()})
 	m.timelineView().list.StartSpinner()
 	return tea.Batch(
-		fetchTimelineCmd(m.client, modeHome, ""),
+		fetchHomeFromMarkerCmd(m.client),
 		m.spinner.Tick,
 	)
 }
@@ -110,12 +149,29 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, view.list.NewStatusMessage(fmt.Sprintf("Fetched %d new statuses.", len(msg.statuses)))
 		}
+		if msg.markersLoaded {
+			m.applyMarkers(msg)
+		}
 		m.setStatuses(view, msg.statuses)
+		if msg.markersLoaded {
+			selectOldestUnread(view)
+			view.selected = view.list.Index()
+		}
 		m.renderCurrentDetail()
 		if len(msg.statuses) == 0 {
 			return m, view.list.NewStatusMessage("No statuses returned.")
 		}
-		return m, view.list.NewStatusMessage(fmt.Sprintf("Loaded %d statuses.", len(msg.statuses)))
+		return m, tea.Batch(
+			view.list.NewStatusMessage(fmt.Sprintf("Loaded %d statuses.", len(msg.statuses))),
+			m.previewCmd(),
+		)
+	case markerTickMsg:
+		if msg.seq != m.markers.seq {
+			return m, nil
+		}
+		return m, m.pendingMarkersCmd()
+	case markersSavedMsg:
+		return m, m.markersSaved(msg)
 	case profileMsg:
 		view := m.profileView
 		view.loading = false
@@ -128,22 +184,81 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if len(msg.statuses) == 0 {
 			return m, view.list.NewStatusMessage("No statuses returned.")
 		}
-		return m, view.list.NewStatusMessage(fmt.Sprintf("Loaded %d statuses.", len(msg.statuses)))
+		return m, tea.Batch(
+			view.list.NewStatusMessage(fmt.Sprintf("Loaded %d statuses.", len(msg.statuses))),
+			m.previewCmd(),
+		)
 	case notificationsMsg:
 		view := m.notificationsView
+		if msg.filter != view.filter {
+			// A page for a chip that is no longer selected.
+			return m, nil
+		}
 		view.loading = false
 		view.list.StopSpinner()
+		view.nextMaxID = msg.next
+		if msg.older {
+			added := m.appendNotifications(view, msg.notifications)
+			m.renderCurrentDetail()
+			return m, view.list.NewStatusMessage(fmt.Sprintf("Loaded %d older notifications.", added))
+		}
 		m.setNotifications(view, msg.notifications)
 		m.renderCurrentDetail()
 		if len(msg.notifications) == 0 {
 			return m, view.list.NewStatusMessage("No notifications returned.")
 		}
-		return m, view.list.NewStatusMessage(fmt.Sprintf("Loaded %d notifications.", len(msg.notifications)))
+		return m, tea.Batch(
+			view.list.NewStatusMessage(fmt.Sprintf("Loaded %d notifications.", len(msg.notifications))),
+			m.previewCmd(),
+			m.notificationDetailCmd(),
+		)
+	case scheduledMsg:
+		view := m.scheduledView
+		view.loading = false
+		view.list.StopSpinner()
+		m.setScheduled(view, msg.statuses)
+		m.renderCurrentDetail()
+		return m, view.list.NewStatusMessage(fmt.Sprintf("Loaded %d scheduled posts.", len(msg.statuses)))
+	case scheduledSavedMsg:
+		return m, m.scheduledSaved(msg)
+	case scheduledCancelledMsg:
+		return m, m.scheduledCancelled(msg)
+	case draftTickMsg:
+		return m, m.draftTick(msg)
+	case composePostedMsg:
+		return m, m.composePosted(msg)
+	case pollVotedMsg:
+		if msg.err != nil {
+			return m, m.activeStatusMessage(fmt.Sprintf("Error: %v", msg.err))
+		}
+		m.updatePoll(*msg.poll)
+		m.renderCurrentDetail()
+		return m, m.activeStatusMessage("Vote recorded.")
+	case historyMsg:
+		return m, m.storeHistory(msg)
+	case notificationDetailMsg:
+		m.storeNotificationDetail(msg)
+		return m, nil
+	case followRequestMsg:
+		return m, m.followRequestAnswered(msg)
+	case requestsMsg:
+		return m, m.setRequests(msg)
+	case requestAnsweredMsg:
+		return m, m.requestAnswered(msg)
+	case previewMsg:
+		m.previews.store(msg)
+		m.renderCurrentDetail()
+		return m, nil
 	case metricsMsg:
 		view := m.metricsView
 		view.loading = false
 		view.list.StopSpinner()
 		view.progressActive = false
+		view.posts = msg.posts
+		view.previous = msg.previous
+		view.anomalies = msg.anomalies
+		view.engagementHeatmap = msg.engagement
+		view.postingHeatmap = msg.posting
 		m.setMetrics(view, msg.series)
 		m.renderCurrentDetail()
 		if len(msg.series) == 0 {
@@ -183,6 +298,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			view.list.StopSpinner()
 			return m, view.list.NewStatusMessage(fmt.Sprintf("Error: %v", msg.err))
 		}
+		if msg.tab == tabScheduled {
+			view := m.scheduledView
+			view.loading = false
+			view.list.StopSpinner()
+			return m, view.list.NewStatusMessage(fmt.Sprintf("Error: %v", msg.err))
+		}
 		if msg.tab == tabMetrics {
 			view := m.metricsView
 			view.loading = false
@@ -215,17 +336,38 @@ func (m model) View() string {
 }
 
 func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.composer.active {
+		return m.updateComposer(msg)
+	}
+	if m.activeTab == tabScheduled {
+		if m.scheduledView.editing != scheduledEditNone {
+			return m.updateScheduledEditor(msg)
+		}
+		if m.scheduledView.confirmCancel {
+			return m.confirmCancelScheduled(msg)
+		}
+	}
+	if m.activeTab == tabNotifications && m.notificationsView.confirmReject {
+		return m.confirmRejectRequest(msg)
+	}
+	if choice, ok := pollChoiceKey(msg.String()); ok && m.selectedPoll() != nil {
+		return m.choosePollOption(choice)
+	}
+
 	switch msg.String() {
 	case "q", "ctrl+c":
+		if save := m.pendingMarkersCmd(); save != nil {
+			return m, tea.Sequence(save, tea.Quit)
+		}
 		return m, tea.Quit
 	case "tab":
-		m.activeTab = (m.activeTab + 1) % 5
+		m.activeTab = (m.activeTab + 1) % tabCount
 		m.resizeAll()
 		m.renderCurrentDetail()
 		m.renderSearch()
 		return m, m.ensureTabLoaded()
 	case "shift+tab":
-		m.activeTab = (m.activeTab + 4) % 5
+		m.activeTab = (m.activeTab + tabCount - 1) % tabCount
 		m.resizeAll()
 		m.renderCurrentDetail()
 		m.renderSearch()
@@ -259,6 +401,12 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.renderCurrentDetail()
 		m.renderSearch()
 		return m, m.ensureTabLoaded()
+	case "S":
+		m.activeTab = tabScheduled
+		m.resizeAll()
+		m.renderCurrentDetail()
+		m.renderSearch()
+		return m, m.ensureTabLoaded()
 	case "h":
 		if m.activeTab == tabTimeline {
 			return m.switchTimelineMode(modeHome)
@@ -281,6 +429,25 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	case "r":
 		return m.refreshCurrent()
+	case "H":
+		return m.toggleHidden()
+	case "e":
+		return m.toggleContentWarning()
+	case "o":
+		return m.openOriginal()
+	case "i":
+		return m.togglePreviews()
+	case "V":
+		return m.submitPollVote()
+	case "d":
+		// Elsewhere "d" pages the list down.
+		if m.activeTab == tabTimeline || m.activeTab == tabProfile || m.activeTab == tabNotifications {
+			return m.toggleHistory()
+		}
+	case "c":
+		return m.openComposer(false)
+	case "C":
+		return m.openComposer(true)
 	case "7":
 		if m.activeTab == tabMetrics {
 			return m.switchMetricsRange(7)
@@ -289,12 +456,89 @@ func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.activeTab == tabMetrics {
 			return m.switchMetricsRange(30)
 		}
+	case "[":
+		if m.activeTab == tabMetrics {
+			return m.stepMetricsRange(-1)
+		}
+		if m.activeTab == tabNotifications {
+			return m.switchNotificationFilter(m.notificationsView.filter - 1)
+		}
+	case "]":
+		if m.activeTab == tabMetrics {
+			return m.stepMetricsRange(1)
+		}
+		if m.activeTab == tabNotifications {
+			return m.switchNotificationFilter(m.notificationsView.filter + 1)
+		}
+	case "M":
+		if m.activeTab == tabNotifications {
+			if m.notificationsView.filter == mentionsFilter {
+				return m.switchNotificationFilter(0)
+			}
+			return m.switchNotificationFilter(mentionsFilter)
+		}
+	case "O":
+		if m.activeTab == tabNotifications {
+			return m.loadOlderNotifications()
+		}
+	case "b":
+		if m.activeTab == tabMetrics {
+			return m.cycleMetricsBucket()
+		}
+	case "w":
+		if m.activeTab == tabMetrics {
+			return m.toggleHeatmap()
+		}
+	case "v":
+		if m.activeTab == tabMetrics {
+			return m.toggleComparison()
+		}
+	case ",", ".":
+		if m.activeTab == tabMetrics {
+			if msg.String() == "," {
+				return m.moveSeriesCursor(-1)
+			}
+			return m.moveSeriesCursor(1)
+		}
+	case " ":
+		if m.activeTab == tabMetrics {
+			return m.toggleSeries()
+		}
+	case "a":
+		if m.activeTab == tabScheduled {
+			return m.startScheduledEdit(scheduledEditTime)
+		}
+		if m.activeTab == tabNotifications && m.notificationsView.showRequests {
+			return m.answerRequest(true)
+		}
+		if m.activeTab == tabNotifications {
+			return m.answerFollowRequest(true)
+		}
+	case "x":
+		if m.activeTab == tabNotifications {
+			return m.askRejectRequest()
+		}
+	case "R":
+		if m.activeTab == tabNotifications {
+			return m.toggleRequests()
+		}
+	case "E":
+		if m.activeTab == tabScheduled {
+			return m.startScheduledEdit(scheduledEditText)
+		}
+	case "D":
+		if m.activeTab == tabScheduled {
+			return m.askCancelScheduled()
+		}
 	}
 
 	return m.updateActiveView(msg)
 }
 
 func (m model) updateActiveView(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.composer.active {
+		return m, m.updateComposerInputs(msg)
+	}
 	var cmd tea.Cmd
 	switch m.activeTab {
 	case tabTimeline:
@@ -303,6 +547,7 @@ func (m model) updateActiveView(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if view.list.Index() != view.selected {
 			view.selected = view.list.Index()
 			m.renderCurrentDetail()
+			cmd = tea.Batch(cmd, m.previewCmd(), m.advanceMarker())
 		}
 		view.detail, _ = view.detail.Update(msg)
 	case tabProfile:
@@ -311,6 +556,7 @@ func (m model) updateActiveView(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if view.list.Index() != view.selected {
 			view.selected = view.list.Index()
 			m.renderCurrentDetail()
+			cmd = tea.Batch(cmd, m.previewCmd())
 		}
 		view.detail, _ = view.detail.Update(msg)
 	case tabSearch:
@@ -321,6 +567,7 @@ func (m model) updateActiveView(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if view.list.Index() != view.selected {
 			view.selected = view.list.Index()
 			m.renderCurrentDetail()
+			cmd = tea.Batch(cmd, m.previewCmd(), m.advanceMarker(), m.notificationDetailCmd())
 		}
 		view.detail, _ = view.detail.Update(msg)
 	case tabMetrics:
@@ -331,12 +578,24 @@ func (m model) updateActiveView(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.renderCurrentDetail()
 		}
 		view.detail, _ = view.detail.Update(msg)
+	case tabScheduled:
+		view := m.scheduledView
+		if view.editing != scheduledEditNone {
+			// Cursor blink messages belong to the open editor.
+			return m, m.updateScheduledInputs(msg)
+		}
+		view.list, cmd = view.list.Update(msg)
+		if view.list.Index() != view.selected {
+			view.selected = view.list.Index()
+			m.renderCurrentDetail()
+		}
+		view.detail, _ = view.detail.Update(msg)
 	}
 	return m, cmd
 }
 
 func (m model) renderHeader() string {
-	tabs := []string{"Timeline", "Search", "Profile", "Metrics", "Notifications"}
+	tabs := []string{"Timeline", "Search", "Profile", "Metrics", "Notifications", "Scheduled"}
 	var parts []string
 	for i, name := range tabs {
 		style := components.TabStyle
@@ -353,6 +612,11 @@ func (m model) renderHeader() string {
 		modeRow = components.HeaderStyle.Render(modeRow)
 		return tabRow + "\n" + modeRow
 	}
+	if m.activeTab == tabNotifications {
+		modeRow := m.renderNotificationFilters()
+		modeRow = components.HeaderStyle.Render(modeRow)
+		return tabRow + "\n" + modeRow
+	}
 	if m.activeTab == tabMetrics {
 		modeRow := m.renderMetricsRanges()
 		modeRow = components.HeaderStyle.Render(modeRow)
@@ -363,6 +627,9 @@ func (m model) renderHeader() string {
 }
 
 func (m model) renderContent() string {
+	if m.composer.active {
+		return m.renderComposer()
+	}
 	switch m.activeTab {
 	case tabTimeline:
 		return m.renderFeed(m.timelineView())
@@ -374,6 +641,8 @@ func (m model) renderContent() string {
 		return m.renderMetrics(m.metricsView)
 	case tabNotifications:
 		return m.renderNotifications(m.notificationsView)
+	case tabScheduled:
+		return m.renderScheduled(m.scheduledView)
 	default:
 		return ""
 	}
@@ -417,6 +686,8 @@ func (m *model) renderCurrentDetail() {
 		m.renderNotificationsDetail(m.notificationsView)
 	case tabMetrics:
 		m.renderMetricsDetail(m.metricsView)
+	case tabScheduled:
+		m.renderScheduledDetail(m.scheduledView)
 	}
 }
 
@@ -433,12 +704,23 @@ func (m *model) renderDetail(view *feedView) {
 		return
 	}
 
-	index := view.list.Index()
-	if index < 0 || index >= len(view.statuses) {
-		index = 0
+	index := selectedStatusIndex(view)
+	if index < 0 {
+		view.detail.SetContent("No status selected.")
+		return
 	}
 
-	view.detail.SetContent(renderStatusDetail(view.statuses[index], view.detail.Width))
+	item := view.statuses[index]
+	state := statusDisplay{
+		verdict:  m.rules.Status(item, view.context),
+		expanded: m.cwExpanded(view.expanded, item),
+		previews: m.statusPreviews(),
+	}
+	if poll := displayStatus(&item).Poll; poll != nil {
+		state.pollSelection = m.pollSelections[poll.ID]
+	}
+	state.history = m.histories[displayStatus(&item).ID]
+	view.detail.SetContent(renderStatusDetail(item, state, view.detail.Width))
 }
 
 func (m *model) resizeAll() {
@@ -448,6 +730,8 @@ func (m *model) resizeAll() {
 	m.resizeFeed(m.profileView)
 	m.resizeNotifications(m.notificationsView)
 	m.resizeMetrics(m.metricsView)
+	m.resizeScheduled(m.scheduledView)
+	m.resizeComposer()
 
 	height := m.contentHeight()
 	m.searchView.viewport.Width = m.width
@@ -480,7 +764,7 @@ func (m *model) resizeNotifications(view *notificationsView) {
 
 func (m *model) contentHeight() int {
 	headerLines := 1
-	if m.activeTab == tabTimeline || m.activeTab == tabMetrics {
+	if m.activeTab == tabTimeline || m.activeTab == tabMetrics || m.activeTab == tabNotifications {
 		headerLines = 2
 	}
 	return components.Max(5, m.height-headerLines)
@@ -500,6 +784,8 @@ func (m model) isLoading() bool {
 		return m.notificationsView.loading
 	case tabMetrics:
 		return m.metricsView.loading
+	case tabScheduled:
+		return m.scheduledView.loading
 	default:
 		return false
 	}
@@ -517,6 +803,8 @@ func (m *model) ensureTabLoaded() tea.Cmd {
 		return m.ensureNotificationsLoaded()
 	case tabMetrics:
 		return m.ensureMetricsLoaded()
+	case tabScheduled:
+		return m.ensureScheduledLoaded()
 	default:
 		return nil
 	}
@@ -548,32 +836,33 @@ func (m *model) refreshCurrent() (tea.Model, tea.Cmd) {
 		)
 	case tabNotifications:
 		view := m.notificationsView
+		if view.showRequests {
+			if view.requestsLoading {
+				return m, nil
+			}
+			view.requestsLoading = true
+			view.list.StartSpinner()
+			return m, tea.Batch(fetchRequestsCmd(m.client), m.spinner.Tick)
+		}
 		if view.loading {
 			return m, nil
 		}
 		view.loading = true
 		view.list.StartSpinner()
 		return m, tea.Batch(
-			fetchNotificationsCmd(m.client),
+			fetchNotificationsCmd(m.client, view.filter, ""),
 			m.spinner.Tick,
 		)
 	case tabMetrics:
-		view := m.metricsView
-		if view.loading {
+		if m.metricsView.loading {
 			return m, nil
 		}
-		view.loading = true
-		view.progressActive = true
-		view.progressDone = 0
-		view.progressTotal = 0
-		view.list.StartSpinner()
-		progressCh := make(chan metricsProgressMsg, 4)
-		view.progressCh = progressCh
-		return m, tea.Batch(
-			fetchMetricsCmd(m.client, view.rangeDays, progressCh),
-			listenMetricsProgressCmd(progressCh),
-			m.spinner.Tick,
-		)
+		return m, m.loadMetrics()
+	case tabScheduled:
+		if m.scheduledView.loading {
+			return m, nil
+		}
+		return m, m.loadScheduled()
 	default:
 		return m, nil
 	}
@@ -0,0 +1,488 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"mastodoncli/internal/drafts"
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+	"mastodoncli/internal/ui/components"
+)
+
+// draftSaveDelay batches draft writes while typing.
+const draftSaveDelay = 2 * time.Second
+
+// composeVisibilities is the ctrl+t cycle; "" leaves the account default.
+var composeVisibilities = []string{"", "public", "unlisted", "private", "direct"}
+
+type composeField int
+
+const (
+	composeFieldText composeField = iota
+	composeFieldCW
+)
+
+// attachStep walks through the prompts for a new attachment.
+type attachStep int
+
+const (
+	attachNone attachStep = iota
+	attachPath
+	attachAlt
+	attachFocus
+)
+
+// composer holds the status being written. Every edit is written to the
+// drafts store shortly after, so nothing is lost if the TUI exits.
+type composer struct {
+	active     bool
+	account    string
+	draft      drafts.Draft
+	replyTo    *mastodon.Status
+	prefill    string
+	text       textarea.Model
+	cw         textinput.Model
+	field      composeField
+	attach     attachStep
+	attachment drafts.Media
+	attachIn   textinput.Model
+	posting    bool
+	message    string
+	seq        int
+	savedSeq   int
+	lastSaveAt time.Time
+}
+
+type draftTickMsg struct {
+	seq int
+}
+
+type composePostedMsg struct {
+	status *mastodon.Status
+	err    error
+}
+
+func newComposer(account string) *composer {
+	text := textarea.New()
+	text.ShowLineNumbers = false
+	text.CharLimit = 0
+	text.Placeholder = "What's on your mind?"
+
+	cw := textinput.New()
+	cw.Prompt = "CW: "
+	cw.Placeholder = "content warning (optional)"
+
+	return &composer{account: account, text: text, cw: cw, attachIn: textinput.New()}
+}
+
+// postStatusCmd checks and uploads the attachments, then posts. Images
+// always need alt text here.
+func postStatusCmd(client *mastodon.Client, params mastodon.StatusParams, uploads []mastodon.MediaUpload) tea.Cmd {
+	return func() tea.Msg {
+		if len(uploads) > 0 {
+			if err := client.CheckMediaFiles(uploads, true); err != nil {
+				return composePostedMsg{err: err}
+			}
+			ids, err := client.UploadMediaFiles(uploads)
+			if err != nil {
+				return composePostedMsg{err: err}
+			}
+			params.MediaIDs = ids
+		}
+		status, err := client.PostStatus(params)
+		return composePostedMsg{status: status, err: err}
+	}
+}
+
+// openComposer starts a new status, or a reply to the selected one.
+func (m *model) openComposer(reply bool) (tea.Model, tea.Cmd) {
+	c := m.composer
+	c.draft = drafts.Draft{Account: c.account}
+	c.replyTo = nil
+	c.prefill = ""
+	c.message = ""
+	c.posting = false
+	c.field = composeFieldText
+	c.attach = attachNone
+	c.text.Reset()
+	c.cw.Reset()
+
+	if reply {
+		selected := m.selectedStatus()
+		if selected == nil {
+			return m, m.activeStatusMessage("Select a status to reply to.")
+		}
+		target := displayStatus(selected)
+		c.replyTo = target
+		c.draft.InReplyToID = target.ID
+		if target.Visibility != "public" {
+			c.draft.Visibility = target.Visibility
+		}
+		c.cw.SetValue(target.SpoilerText)
+		c.prefill = "@" + target.Account.Acct + " "
+		c.text.SetValue(c.prefill)
+	}
+
+	c.active = true
+	c.cw.Blur()
+	m.resizeComposer()
+	return m, c.text.Focus()
+}
+
+// updateComposer handles keys while composing: ctrl+s posts, esc closes
+// and keeps the draft, ctrl+x discards it.
+func (m *model) updateComposer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := m.composer
+	if c.attach != attachNone && msg.String() != "ctrl+c" {
+		return m, m.updateAttach(msg)
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		m.saveDraft()
+		c.active = false
+		return m.handleKey(msg)
+	case "esc":
+		c.active = false
+		if m.saveDraft() {
+			return m, m.activeStatusMessage(fmt.Sprintf("Saved as draft %s.", c.draft.ID))
+		}
+		return m, nil
+	case "ctrl+x":
+		c.active = false
+		m.deleteDraft()
+		return m, m.activeStatusMessage("Draft discarded.")
+	case "ctrl+s":
+		return m, m.postComposer()
+	case "ctrl+t":
+		c.draft.Visibility = nextVisibility(c.draft.Visibility)
+		return m, m.draftChanged()
+	case "ctrl+o":
+		if c.posting {
+			return m, nil
+		}
+		c.attach = attachPath
+		c.attachment = drafts.Media{}
+		c.attachIn.Prompt = "File: "
+		c.attachIn.Placeholder = "path to an image, video or audio file"
+		c.attachIn.Reset()
+		c.text.Blur()
+		c.cw.Blur()
+		m.resizeComposer()
+		return m, c.attachIn.Focus()
+	case "ctrl+r":
+		if n := len(c.draft.Media); n > 0 && !c.posting {
+			c.message = "Removed " + filepath.Base(c.draft.Media[n-1].Path) + "."
+			c.draft.Media = c.draft.Media[:n-1]
+			m.resizeComposer()
+			return m, m.draftChanged()
+		}
+		return m, nil
+	case "tab", "shift+tab":
+		if c.field == composeFieldText {
+			c.field = composeFieldCW
+			c.text.Blur()
+			return m, c.cw.Focus()
+		}
+		c.field = composeFieldText
+		c.cw.Blur()
+		return m, c.text.Focus()
+	}
+
+	if c.posting {
+		return m, nil
+	}
+	cmd := m.updateComposerInputs(msg)
+	return m, tea.Batch(cmd, m.draftChanged())
+}
+
+// updateAttach asks for the file, its alt text and an optional focus
+// point; enter moves on and esc drops the attachment.
+func (m *model) updateAttach(msg tea.KeyMsg) tea.Cmd {
+	c := m.composer
+	switch msg.String() {
+	case "esc":
+		return m.stopAttach("")
+	case "enter":
+	default:
+		return m.updateComposerInputs(msg)
+	}
+
+	value := strings.TrimSpace(c.attachIn.Value())
+	switch c.attach {
+	case attachPath:
+		path, err := expandPath(value)
+		if err == nil {
+			var info os.FileInfo
+			if info, err = os.Stat(path); err == nil && info.IsDir() {
+				err = fmt.Errorf("%s is a directory", path)
+			}
+		}
+		if err != nil {
+			c.message = fmt.Sprintf("Error: %v", err)
+			return nil
+		}
+		c.attachment.Path = path
+		c.attach = attachAlt
+		c.attachIn.Prompt = "Alt text: "
+		c.attachIn.Placeholder = "describe the media for people who can't see it"
+	case attachAlt:
+		c.attachment.Alt = value
+		c.attach = attachFocus
+		c.attachIn.Prompt = "Focus x,y: "
+		c.attachIn.Placeholder = "optional, e.g. 0,0.5"
+	case attachFocus:
+		if value != "" {
+			if _, _, err := mastodon.ParseFocus(value); err != nil {
+				c.message = fmt.Sprintf("Error: %v", err)
+				return nil
+			}
+		}
+		c.attachment.Focus = value
+		c.draft.Media = append(c.draft.Media, c.attachment)
+		return tea.Batch(m.stopAttach("Attached "+filepath.Base(c.attachment.Path)+"."), m.draftChanged())
+	}
+	c.message = ""
+	c.attachIn.Reset()
+	return nil
+}
+
+func (m *model) stopAttach(message string) tea.Cmd {
+	c := m.composer
+	c.attach = attachNone
+	c.attachIn.Blur()
+	c.message = message
+	m.resizeComposer()
+	if c.field == composeFieldCW {
+		return c.cw.Focus()
+	}
+	return c.text.Focus()
+}
+
+// expandPath resolves a leading "~" and makes the path absolute, so the
+// draft still works from another directory.
+func expandPath(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("enter a file path")
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	return filepath.Abs(path)
+}
+
+func (m *model) updateComposerInputs(msg tea.Msg) tea.Cmd {
+	c := m.composer
+	var cmd tea.Cmd
+	if c.attach != attachNone {
+		c.attachIn, cmd = c.attachIn.Update(msg)
+	} else if c.field == composeFieldText {
+		c.text, cmd = c.text.Update(msg)
+	} else {
+		c.cw, cmd = c.cw.Update(msg)
+	}
+	return cmd
+}
+
+// draftChanged schedules a draft save once typing pauses.
+func (m *model) draftChanged() tea.Cmd {
+	c := m.composer
+	c.seq++
+	seq := c.seq
+	return tea.Tick(draftSaveDelay, func(time.Time) tea.Msg {
+		return draftTickMsg{seq: seq}
+	})
+}
+
+func (m *model) draftTick(msg draftTickMsg) tea.Cmd {
+	c := m.composer
+	if !c.active || msg.seq != c.seq || c.savedSeq == c.seq {
+		return nil
+	}
+	m.saveDraft()
+	return nil
+}
+
+// saveDraft writes the composer to the drafts store and reports whether a
+// draft exists afterwards. Drafts with nothing beyond the reply mention are
+// not kept.
+func (m *model) saveDraft() bool {
+	c := m.composer
+	c.draft.Text = c.text.Value()
+	c.draft.SpoilerText = strings.TrimSpace(c.cw.Value())
+	c.savedSeq = c.seq
+	prefillCW := ""
+	if c.replyTo != nil {
+		prefillCW = strings.TrimSpace(c.replyTo.SpoilerText)
+	}
+	untouched := strings.TrimSpace(c.draft.Text) == strings.TrimSpace(c.prefill) && c.draft.SpoilerText == prefillCW && len(c.draft.Media) == 0
+	if c.draft.Empty() || untouched {
+		m.deleteDraft()
+		return false
+	}
+	err := drafts.Update(func(store *drafts.Store) error {
+		c.draft.ID = store.Put(c.draft, time.Now())
+		return nil
+	})
+	if err != nil {
+		c.message = fmt.Sprintf("Error: %v", err)
+		return false
+	}
+	c.lastSaveAt = time.Now()
+	return true
+}
+
+func (m *model) deleteDraft() {
+	c := m.composer
+	if c.draft.ID == "" {
+		return
+	}
+	id := c.draft.ID
+	err := drafts.Update(func(store *drafts.Store) error {
+		store.Delete(id)
+		return nil
+	})
+	if err != nil {
+		c.message = fmt.Sprintf("Error: %v", err)
+		return
+	}
+	c.draft.ID = ""
+}
+
+func (m *model) postComposer() tea.Cmd {
+	c := m.composer
+	if c.posting {
+		return nil
+	}
+	m.saveDraft()
+	params := c.draft.Params()
+	params.Status = strings.TrimSpace(params.Status)
+	if params.Status == "" && len(c.draft.Media) == 0 {
+		c.message = "Error: status text is required"
+		return nil
+	}
+	c.posting = true
+	c.message = "Posting..."
+	if len(c.draft.Media) > 0 {
+		c.message = fmt.Sprintf("Uploading %d attachment(s) and posting...", len(c.draft.Media))
+	}
+	return postStatusCmd(m.client, params, c.draft.Uploads())
+}
+
+func (m *model) composePosted(msg composePostedMsg) tea.Cmd {
+	c := m.composer
+	c.posting = false
+	if msg.err != nil {
+		c.message = fmt.Sprintf("Error: %v (kept as draft %s)", msg.err, c.draft.ID)
+		if !c.active {
+			return m.activeStatusMessage(c.message)
+		}
+		return nil
+	}
+	m.deleteDraft()
+	c.active = false
+	return m.activeStatusMessage("Posted.")
+}
+
+func nextVisibility(current string) string {
+	for i, visibility := range composeVisibilities {
+		if visibility == current {
+			return composeVisibilities[(i+1)%len(composeVisibilities)]
+		}
+	}
+	return composeVisibilities[0]
+}
+
+func (m model) renderComposer() string {
+	c := m.composer
+	width := components.Max(20, m.width-2)
+
+	var builder strings.Builder
+	title := "New post"
+	if c.replyTo != nil {
+		title = "Reply to @" + c.replyTo.Account.Acct
+	}
+	builder.WriteString(components.TimeStyle.Render(title))
+	if c.account != "" {
+		builder.WriteString(components.MutedStyle.Render(" as " + c.account))
+	}
+	builder.WriteString("\n")
+	if c.replyTo != nil {
+		excerpt := output.WrapText(output.StripHTML(c.replyTo.Content), width)
+		lines := strings.Split(excerpt, "\n")
+		if len(lines) > 3 {
+			lines = append(lines[:3], "…")
+		}
+		builder.WriteString(components.MutedStyle.Render(strings.Join(lines, "\n")))
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\n")
+
+	visibility := c.draft.Visibility
+	if visibility == "" {
+		visibility = "account default"
+	}
+	builder.WriteString(fmt.Sprintf("Visibility: %s\n", visibility))
+	builder.WriteString(c.cw.View())
+	builder.WriteString("\n\n")
+	builder.WriteString(c.text.View())
+	builder.WriteString("\n")
+	for i, media := range c.draft.Media {
+		alt := media.Alt
+		if alt == "" {
+			alt = "no alt text"
+		}
+		builder.WriteString(fmt.Sprintf("%d. %s — %s\n", i+1, filepath.Base(media.Path), alt))
+	}
+	if c.attach != attachNone {
+		builder.WriteString(c.attachIn.View())
+		builder.WriteString("\n")
+	}
+
+	info := fmt.Sprintf("%d characters", utf8.RuneCountInString(strings.TrimSpace(c.text.Value())))
+	if c.draft.ID != "" {
+		info += fmt.Sprintf(" · draft %s", c.draft.ID)
+		if !c.lastSaveAt.IsZero() {
+			info += " saved " + c.lastSaveAt.Format("15:04:05")
+		}
+	}
+	builder.WriteString(components.MutedStyle.Render(info))
+	builder.WriteString("\n")
+	builder.WriteString(components.MutedStyle.Render("ctrl+s post · tab switch field · ctrl+t visibility · ctrl+o attach · ctrl+r remove last · esc close (keeps draft) · ctrl+x discard"))
+	if c.message != "" {
+		builder.WriteString("\n")
+		builder.WriteString(c.message)
+	}
+	return builder.String()
+}
+
+func (m *model) resizeComposer() {
+	if m.width == 0 || m.height == 0 {
+		return
+	}
+	c := m.composer
+	width := components.Max(20, m.width-2)
+	c.cw.Width = components.Max(10, width-len(c.cw.Prompt)-1)
+	c.attachIn.Width = components.Max(10, width-14)
+	c.text.SetWidth(width)
+	reserved := 9 + len(c.draft.Media)
+	if c.attach != attachNone {
+		reserved++
+	}
+	if c.replyTo != nil {
+		reserved += 4
+	}
+	c.text.SetHeight(components.Max(3, m.contentHeight()-reserved))
+}
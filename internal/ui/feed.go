@@ -7,15 +7,24 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"mastodoncli/internal/mastodon"
 	"mastodoncli/internal/output"
+	"mastodoncli/internal/rules"
 	"mastodoncli/internal/ui/components"
 )
 
+var (
+	ruleHighlightStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("70")).Bold(true)
+	ruleMutedStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("167"))
+	cwStyle            = lipgloss.NewStyle().Foreground(lipgloss.Color("175")).Bold(true)
+)
+
 type timelineItem struct {
 	id      string
+	index   int
 	title   string
 	snippet string
 }
@@ -24,16 +33,31 @@ func (t timelineItem) Title() string       { return t.title }
 func (t timelineItem) Description() string { return t.snippet }
 func (t timelineItem) FilterValue() string { return t.title + " " + t.snippet }
 
+// statusDisplay holds the per-status view state used by the renderers.
+type statusDisplay struct {
+	verdict       rules.Verdict
+	expanded      bool
+	previews      *previewState
+	pollSelection map[int]bool
+	history       *editHistory
+}
+
 type feedView struct {
 	list     list.Model
 	detail   viewport.Model
+	title    string
 	statuses []mastodon.Status
+	context  rules.Context
+	marker   string
+	unread   int
+	expanded map[string]bool
+	hidden   int
 	topID    string
 	loading  bool
 	selected int
 }
 
-func newFeedView(title string) *feedView {
+func newFeedView(title string, context rules.Context) *feedView {
 	delegate := list.NewDefaultDelegate()
 	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.Foreground(lipgloss.Color("86"))
 	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.Foreground(lipgloss.Color("86"))
@@ -48,6 +72,14 @@ func newFeedView(title string) *feedView {
 	l.AdditionalFullHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+			key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "toggle CW")),
+			key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open media")),
+			key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "previews")),
+			key.NewBinding(key.WithKeys("V"), key.WithHelp("1-9/V", "poll vote")),
+			key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "edit history")),
+			key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "compose")),
+			key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "reply")),
+			key.NewBinding(key.WithKeys("H"), key.WithHelp("H", "show hidden")),
 			key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next tab")),
 			key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "prev tab")),
 			key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit")),
@@ -59,23 +91,18 @@ func newFeedView(title string) *feedView {
 	vp.YPosition = 0
 
 	return &feedView{
-		list:    l,
-		detail:  vp,
-		loading: true,
+		list:     l,
+		detail:   vp,
+		title:    title,
+		context:  context,
+		expanded: make(map[string]bool),
+		loading:  true,
 	}
 }
 
 func (m *model) setStatuses(view *feedView, statuses []mastodon.Status) {
 	view.statuses = statuses
-	items := make([]list.Item, 0, components.Max(1, len(statuses)))
-	if len(statuses) == 0 {
-		items = append(items, emptyTimelineItem())
-	} else {
-		for _, item := range statuses {
-			items = append(items, statusToItem(item, view.list.Width()))
-		}
-	}
-	view.list.SetItems(items)
+	m.refreshStatusItems(view)
 	if len(statuses) > 0 {
 		view.topID = statuses[0].ID
 	}
@@ -87,15 +114,166 @@ func (m *model) prependStatuses(view *feedView, statuses []mastodon.Status) {
 	}
 
 	view.statuses = append(statuses, view.statuses...)
-	items := make([]list.Item, 0, components.Max(1, len(view.statuses)))
-	for _, item := range view.statuses {
-		items = append(items, statusToItem(item, view.list.Width()))
+	m.refreshStatusItems(view)
+	view.topID = statuses[0].ID
+}
+
+// refreshStatusItems rebuilds the list from view.statuses, applying the
+// local rules for the view context.
+func (m *model) refreshStatusItems(view *feedView) {
+	previous, _ := view.list.SelectedItem().(timelineItem)
+	items := make([]list.Item, 0, components.Max(1, len(view.statuses)+1))
+	view.hidden = 0
+	view.unread = 0
+	dividerPlaced := view.marker == ""
+	for i, item := range view.statuses {
+		if !dividerPlaced && mastodon.CompareIDs(item.ID, view.marker) <= 0 {
+			if len(items) > 0 {
+				items = append(items, dividerItem())
+			}
+			dividerPlaced = true
+		}
+
+		verdict := rules.Verdict{}
+		if view.context != "" {
+			verdict = m.rules.Status(item, view.context)
+		}
+		if verdict.Action == rules.ActionHide && !m.showHidden {
+			view.hidden++
+			continue
+		}
+		display := statusDisplay{verdict: verdict, expanded: m.cwExpanded(view.expanded, item)}
+		entry := statusToItem(item, display, view.list.Width())
+		entry.index = i
+		applyVerdict(&entry.title, &entry.snippet, verdict)
+		if !dividerPlaced {
+			view.unread++
+		}
+		items = append(items, entry)
+	}
+	if len(items) == 0 {
+		if view.hidden > 0 {
+			items = append(items, emptyItem("No statuses", fmt.Sprintf("%d hidden by local rules (H to show).", view.hidden)))
+		} else {
+			items = append(items, emptyTimelineItem())
+		}
 	}
 	view.list.SetItems(items)
-	view.topID = statuses[0].ID
+	view.list.Title = unreadTitle(view.title, view.unread, view.marker)
+	if previous.id != "" {
+		selectItemByID(&view.list, previous.id)
+	}
 }
 
-func statusToItem(item mastodon.Status, width int) timelineItem {
+// selectItemByID moves the cursor back to the status with the given ID
+// after the list was rebuilt.
+func selectItemByID(l *list.Model, id string) {
+	for i, item := range l.Items() {
+		if entry, ok := item.(timelineItem); ok && entry.index >= 0 && entry.id == id {
+			l.Select(i)
+			return
+		}
+	}
+}
+
+// selectedStatusIndex maps the list selection back to view.statuses.
+func selectedStatusIndex(view *feedView) int {
+	item, ok := view.list.SelectedItem().(timelineItem)
+	if !ok || item.index < 0 || item.index >= len(view.statuses) {
+		return -1
+	}
+	return item.index
+}
+
+func applyVerdict(title, snippet *string, verdict rules.Verdict) {
+	switch verdict.Action {
+	case rules.ActionHighlight:
+		*title = ruleHighlightStyle.Render("★ ") + *title
+	case rules.ActionCollapse:
+		*snippet = ruleMutedStyle.Render("Collapsed: " + verdict.Reason())
+	case rules.ActionHide:
+		*title = ruleMutedStyle.Render("[hidden] ") + *title
+		*snippet = ruleMutedStyle.Render(verdict.Reason())
+	}
+}
+
+// cwExpanded reports whether the content warning of status is open, using
+// the per-status override from overrides before the configured default.
+func (m *model) cwExpanded(overrides map[string]bool, status mastodon.Status) bool {
+	display := &status
+	if status.Reblog != nil {
+		display = status.Reblog
+	}
+	if expanded, ok := overrides[display.ID]; ok {
+		return expanded
+	}
+	spoiler := output.StripHTML(display.SpoilerText)
+	if spoiler == "" && display.Sensitive && len(display.MediaAttachments) > 0 {
+		return m.cw.RevealSensitive()
+	}
+	return m.cw.Expand(spoiler)
+}
+
+func (m *model) toggleContentWarning() (tea.Model, tea.Cmd) {
+	status := m.selectedStatus()
+	if status == nil {
+		return m, nil
+	}
+
+	var overrides map[string]bool
+	switch m.activeTab {
+	case tabNotifications:
+		overrides = m.notificationsView.expanded
+	case tabProfile:
+		overrides = m.profileView.expanded
+	default:
+		overrides = m.timelineView().expanded
+	}
+
+	display := displayStatus(status)
+	if strings.TrimSpace(display.SpoilerText) == "" && !(display.Sensitive && len(display.MediaAttachments) > 0) {
+		return m, m.activeStatusMessage("No content warning on this status.")
+	}
+	overrides[display.ID] = !m.cwExpanded(overrides, *status)
+
+	switch m.activeTab {
+	case tabNotifications:
+		m.refreshNotificationItems(m.notificationsView)
+	case tabProfile:
+		m.refreshStatusItems(m.profileView)
+	default:
+		m.refreshStatusItems(m.timelineView())
+	}
+	m.renderCurrentDetail()
+	return m, m.previewCmd()
+}
+
+func (m *model) toggleHidden() (tea.Model, tea.Cmd) {
+	m.showHidden = !m.showHidden
+	for _, view := range m.timelineViews {
+		m.refreshStatusItems(view)
+	}
+	m.refreshStatusItems(m.profileView)
+	m.refreshNotificationItems(m.notificationsView)
+	m.renderCurrentDetail()
+
+	message := "Hiding items matched by local rules."
+	if m.showHidden {
+		message = "Showing items hidden by local rules."
+	}
+	switch m.activeTab {
+	case tabTimeline:
+		return m, m.timelineView().list.NewStatusMessage(message)
+	case tabProfile:
+		return m, m.profileView.list.NewStatusMessage(message)
+	case tabNotifications:
+		return m, m.notificationsView.list.NewStatusMessage(message)
+	default:
+		return m, nil
+	}
+}
+
+func statusToItem(item mastodon.Status, state statusDisplay, width int) timelineItem {
 	display := &item
 	boostedBy := ""
 	if item.Reblog != nil {
@@ -110,11 +288,10 @@ func statusToItem(item mastodon.Status, width int) timelineItem {
 	}
 
 	title := fmt.Sprintf("%s%s · %s", author, boostedBy, display.CreatedAt)
-	snippet := output.WrapText(output.StripHTML(display.Content), components.Max(20, width-6))
-	snippet = components.TruncateLines(snippet, 2)
-	if snippet == "" {
-		snippet = "(no text)"
+	if display.EditedAt != "" {
+		title += " · edited"
 	}
+	snippet := statusSnippet(*display, state.expanded, width)
 
 	return timelineItem{
 		id:      display.ID,
@@ -123,7 +300,7 @@ func statusToItem(item mastodon.Status, width int) timelineItem {
 	}
 }
 
-func renderStatusDetail(item mastodon.Status, width int) string {
+func renderStatusDetail(item mastodon.Status, state statusDisplay, width int) string {
 	display := &item
 	boostedBy := ""
 	if item.Reblog != nil {
@@ -157,18 +334,89 @@ func renderStatusDetail(item mastodon.Status, width int) string {
 		builder.WriteString(boostedBy)
 		builder.WriteString("\n")
 	}
-	builder.WriteString("Text:\n")
-	text := output.WrapText(output.StripHTML(display.Content), wrapWidth)
-	if text == "" {
-		text = "(no text)"
+	if state.verdict.Matched() {
+		builder.WriteString(renderVerdict(state.verdict))
+		builder.WriteString("\n")
+	}
+	builder.WriteString(renderStatusBody(*display, state, wrapWidth))
+
+	return builder.String()
+}
+
+// statusSnippet is the two-line list preview; a closed content warning
+// replaces the text.
+func statusSnippet(status mastodon.Status, expanded bool, width int) string {
+	spoiler := strings.TrimSpace(output.StripHTML(status.SpoilerText))
+	if spoiler != "" && !expanded {
+		return cwStyle.Render("CW: ") + components.TruncateLines(output.WrapText(spoiler, components.Max(20, width-10)), 2)
+	}
+	snippet := output.WrapText(output.StripHTML(status.Content), components.Max(20, width-6))
+	snippet = components.TruncateLines(snippet, 2)
+	if snippet == "" {
+		snippet = "(no text)"
+	}
+	return snippet
+}
+
+// renderStatusBody renders the content warning header, if any, followed by
+// the text when the warning is expanded.
+func renderStatusBody(status mastodon.Status, state statusDisplay, wrapWidth int) string {
+	var builder strings.Builder
+	if status.EditedAt != "" {
+		builder.WriteString(components.TimeStyle.Render("Edited:"))
+		builder.WriteString(" ")
+		builder.WriteString(status.EditedAt)
+		builder.WriteString(components.MutedStyle.Render(" (d for changes)"))
+		builder.WriteString("\n")
+	}
+	spoiler := strings.TrimSpace(output.StripHTML(status.SpoilerText))
+	if spoiler != "" {
+		builder.WriteString(cwStyle.Render("CW:"))
+		builder.WriteString("     ")
+		builder.WriteString(output.WrapText(spoiler, wrapWidth))
+		builder.WriteString("\n")
+	}
+	if status.Sensitive && len(status.MediaAttachments) > 0 {
+		builder.WriteString(cwStyle.Render("Media:"))
+		builder.WriteString("  marked sensitive\n")
+	}
+	if spoiler != "" && !state.expanded {
+		builder.WriteString(components.MutedStyle.Render("Text hidden behind content warning (e to expand)."))
+	} else {
+		builder.WriteString("Text:\n")
+		text := output.WrapText(output.StripHTML(status.Content), wrapWidth)
+		if text == "" {
+			text = "(no text)"
+		}
+		builder.WriteString(text)
+		if status.Poll != nil {
+			builder.WriteString("\n\n")
+			builder.WriteString(renderPoll(*status.Poll, state.pollSelection, wrapWidth))
+		}
 	}
-	builder.WriteString(text)
 
+	if media := renderAttachments(status, state.expanded, state.previews, wrapWidth); media != "" {
+		builder.WriteString("\n\n")
+		builder.WriteString(media)
+	}
+	if state.history != nil && state.history.shown {
+		builder.WriteString("\n\n")
+		builder.WriteString(renderHistory(state.history, wrapWidth))
+	}
 	return builder.String()
 }
 
+func renderVerdict(verdict rules.Verdict) string {
+	style := ruleMutedStyle
+	if verdict.Action == rules.ActionHighlight {
+		style = ruleHighlightStyle
+	}
+	return style.Render("Rule:") + "   " + verdict.Reason()
+}
+
 func loadingItem(title, snippet string) timelineItem {
 	return timelineItem{
+		index:   -1,
 		title:   title,
 		snippet: snippet,
 	}
@@ -176,6 +424,7 @@ func loadingItem(title, snippet string) timelineItem {
 
 func emptyItem(title, snippet string) timelineItem {
 	return timelineItem{
+		index:   -1,
 		title:   title,
 		snippet: snippet,
 	}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"mastodoncli/internal/metrics"
+	"mastodoncli/internal/ui/components"
+)
+
+// heatmapRamp runs from an empty cell to the busiest one.
+var heatmapRamp = []lipgloss.Color{"236", "22", "28", "34", "40", "46", "154", "226"}
+
+func (m *model) toggleHeatmap() (tea.Model, tea.Cmd) {
+	view := m.metricsView
+	view.showHeatmap = !view.showHeatmap
+	m.renderCurrentDetail()
+	return m, nil
+}
+
+func (m *model) toggleComparison() (tea.Model, tea.Cmd) {
+	view := m.metricsView
+	view.compare = !view.compare
+	m.renderCurrentDetail()
+	return m, nil
+}
+
+func renderMetricsHeatmaps(view *metricsView, width int) string {
+	return renderHeatmap("Engagement", view.engagementHeatmap, width) +
+		"\n\n" + renderHeatmap("Your posts", view.postingHeatmap, width)
+}
+
+// renderHeatmap draws a weekday by hour grid, two columns per hour when the
+// pane is wide enough.
+func renderHeatmap(title string, heatmap metrics.Heatmap, width int) string {
+	cell := 2
+	if width < 4+24*2 {
+		cell = 1
+	}
+	maxValue := heatmap.Max()
+
+	var builder strings.Builder
+	builder.WriteString(metricsSelectedStyle.Render(title))
+	builder.WriteString(components.MutedStyle.Render(fmt.Sprintf("  total %d · peak %s", heatmap.Total(), heatmap.Peak())))
+	builder.WriteString("\n    ")
+	for hour := 0; hour < 24; hour += 6 {
+		builder.WriteString(fmt.Sprintf("%-*s", 6*cell, fmt.Sprintf("%02d", hour)))
+	}
+	for day, row := range heatmap.Cells {
+		builder.WriteString("\n")
+		builder.WriteString(metrics.Weekdays[day])
+		builder.WriteString(" ")
+		for _, value := range row {
+			color := heatmapRamp[0]
+			if value > 0 && maxValue > 0 {
+				color = heatmapRamp[1+(value-1)*(len(heatmapRamp)-1)/maxValue]
+			}
+			builder.WriteString(lipgloss.NewStyle().Background(color).Render(strings.Repeat(" ", cell)))
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+	"mastodoncli/internal/ui/components"
+)
+
+var (
+	diffAddedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("70"))
+	diffRemovedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("167")).Strikethrough(true)
+)
+
+// editHistory is the fetched revisions of one status and whether the
+// detail pane shows them.
+type editHistory struct {
+	shown   bool
+	loading bool
+	edits   []mastodon.StatusEdit
+	err     error
+}
+
+type historyMsg struct {
+	statusID string
+	edits    []mastodon.StatusEdit
+	err      error
+}
+
+func fetchHistoryCmd(client *mastodon.Client, statusID string) tea.Cmd {
+	return func() tea.Msg {
+		edits, err := client.StatusHistory(statusID)
+		return historyMsg{statusID: statusID, edits: edits, err: err}
+	}
+}
+
+// toggleHistory shows or hides the revisions of the selected status,
+// fetching them the first time.
+func (m *model) toggleHistory() (tea.Model, tea.Cmd) {
+	status := m.selectedStatus()
+	if status == nil {
+		return m, nil
+	}
+	display := displayStatus(status)
+	if display.EditedAt == "" {
+		return m, m.activeStatusMessage("This status was not edited.")
+	}
+
+	history := m.histories[display.ID]
+	if history == nil || history.err != nil {
+		m.histories[display.ID] = &editHistory{shown: true, loading: true}
+		m.renderCurrentDetail()
+		return m, fetchHistoryCmd(m.client, display.ID)
+	}
+	history.shown = !history.shown
+	m.renderCurrentDetail()
+	return m, nil
+}
+
+func (m *model) storeHistory(msg historyMsg) tea.Cmd {
+	history := m.histories[msg.statusID]
+	if history == nil {
+		return nil
+	}
+	history.loading = false
+	history.edits = msg.edits
+	history.err = msg.err
+	m.renderCurrentDetail()
+	if msg.err != nil {
+		return m.activeStatusMessage(fmt.Sprintf("Error: %v", msg.err))
+	}
+	return nil
+}
+
+// renderHistory shows each revision as a word diff against the one before,
+// newest first.
+func renderHistory(history *editHistory, width int) string {
+	var builder strings.Builder
+	builder.WriteString(components.TimeStyle.Render("History:"))
+	builder.WriteString(" (d to hide)\n")
+	switch {
+	case history.loading:
+		builder.WriteString(components.MutedStyle.Render("Loading edits..."))
+		return builder.String()
+	case history.err != nil:
+		builder.WriteString(components.MutedStyle.Render(fmt.Sprintf("Could not load edits: %v", history.err)))
+		return builder.String()
+	case len(history.edits) == 0:
+		builder.WriteString(components.MutedStyle.Render("No revisions returned."))
+		return builder.String()
+	}
+
+	edits := history.edits
+	for i := len(edits) - 1; i >= 1; i-- {
+		builder.WriteString(components.MutedStyle.Render(fmt.Sprintf("Edit %d · %s", i, edits[i].CreatedAt)))
+		builder.WriteString("\n")
+		if changes := output.EditChanges(edits[i-1], edits[i]); len(changes) > 0 {
+			builder.WriteString(components.MutedStyle.Render(strings.Join(changes, ", ")))
+			builder.WriteString("\n")
+		}
+		builder.WriteString(renderDiff(output.DiffWords(output.EditText(edits[i-1]), output.EditText(edits[i])), width))
+		builder.WriteString("\n\n")
+	}
+	builder.WriteString(components.MutedStyle.Render("Original · " + edits[0].CreatedAt))
+	builder.WriteString("\n")
+	builder.WriteString(output.WrapText(output.EditText(edits[0]), width))
+	return builder.String()
+}
+
+var diffToken = regexp.MustCompile(`\n|[^\S\n]+|\S+`)
+
+// renderDiff wraps the diff word by word, styling each word on its own so
+// colour codes never count towards the line width.
+func renderDiff(parts []output.DiffPart, width int) string {
+	var builder strings.Builder
+	column := 0
+	for _, part := range parts {
+		style := lipgloss.NewStyle()
+		switch part.Op {
+		case output.DiffAdded:
+			style = diffAddedStyle
+		case output.DiffRemoved:
+			style = diffRemovedStyle
+		}
+		for _, token := range diffToken.FindAllString(part.Text, -1) {
+			switch {
+			case token == "\n":
+				builder.WriteString("\n")
+				column = 0
+			case strings.TrimSpace(token) == "":
+				if column > 0 && column < width {
+					builder.WriteString(" ")
+					column++
+				}
+			default:
+				n := lipgloss.Width(token)
+				if column > 0 && column+n > width {
+					builder.WriteString("\n")
+					column = 0
+				}
+				builder.WriteString(style.Render(token))
+				column += n
+			}
+		}
+	}
+	return builder.String()
+}
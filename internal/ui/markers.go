@@ -0,0 +1,216 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"mastodoncli/internal/mastodon"
+)
+
+// markerSaveDelay batches marker updates while scrolling.
+const markerSaveDelay = 3 * time.Second
+
+var dividerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("167")).Bold(true)
+
+// markersState tracks the home and notifications read positions that were
+// last written to the server.
+type markersState struct {
+	enabled            bool
+	savedHome          string
+	savedNotifications string
+	seq                int
+}
+
+type markerTickMsg struct {
+	seq int
+}
+
+type markersSavedMsg struct {
+	home          string
+	notifications string
+	err           error
+}
+
+// fetchHomeFromMarkerCmd loads the home timeline around the last-read
+// position: unread statuses newer than the marker, the marker status and
+// some read context below it. Servers without markers get the newest page.
+func fetchHomeFromMarkerCmd(client *mastodon.Client) tea.Cmd {
+	return func() tea.Msg {
+		markers, err := client.Markers("home", "notifications")
+		if err != nil {
+			statuses, err := client.HomeTimelinePage(40, "", "")
+			if err != nil {
+				return feedErrMsg{tab: tabTimeline, mode: modeHome, err: err}
+			}
+			return timelineMsg{mode: modeHome, statuses: statuses}
+		}
+
+		msg := timelineMsg{
+			mode:                modeHome,
+			markersLoaded:       true,
+			homeMarker:          markers["home"].LastReadID,
+			notificationsMarker: markers["notifications"].LastReadID,
+		}
+		if msg.homeMarker == "" {
+			msg.statuses, err = client.HomeTimelinePage(40, "", "")
+			if err != nil {
+				return feedErrMsg{tab: tabTimeline, mode: modeHome, err: err}
+			}
+			return msg
+		}
+
+		unread, err := client.HomeTimelineAfter(40, msg.homeMarker)
+		if err != nil {
+			return feedErrMsg{tab: tabTimeline, mode: modeHome, err: err}
+		}
+		read, err := client.HomeTimelinePage(20, "", msg.homeMarker)
+		if err != nil {
+			return feedErrMsg{tab: tabTimeline, mode: modeHome, err: err}
+		}
+
+		statuses := append([]mastodon.Status{}, unread...)
+		if last, err := client.GetStatus(msg.homeMarker); err == nil {
+			statuses = append(statuses, *last)
+		}
+		msg.statuses = append(statuses, read...)
+		return msg
+	}
+}
+
+func saveMarkersCmd(client *mastodon.Client, home, notifications string) tea.Cmd {
+	return func() tea.Msg {
+		err := client.SaveMarkers(home, notifications)
+		return markersSavedMsg{home: home, notifications: notifications, err: err}
+	}
+}
+
+// applyMarkers stores the read positions returned by the server.
+func (m *model) applyMarkers(msg timelineMsg) {
+	m.markers.enabled = true
+	m.markers.savedHome = msg.homeMarker
+	m.markers.savedNotifications = msg.notificationsMarker
+	m.timelineViews[modeHome].marker = msg.homeMarker
+	m.notificationsView.marker = msg.notificationsMarker
+}
+
+// selectOldestUnread puts the cursor on the unread status just above the
+// last-read divider.
+func selectOldestUnread(view *feedView) {
+	for i, item := range view.list.Items() {
+		entry, ok := item.(timelineItem)
+		if !ok || entry.index < 0 {
+			continue
+		}
+		if mastodon.CompareIDs(entry.id, view.marker) <= 0 {
+			return
+		}
+		view.list.Select(i)
+	}
+}
+
+// advanceMarker moves the read position forward when the cursor reaches a
+// newer item and schedules a save.
+func (m *model) advanceMarker() tea.Cmd {
+	if !m.markers.enabled {
+		return nil
+	}
+
+	switch {
+	case m.activeTab == tabTimeline && m.activeTimeline == modeHome:
+		view := m.timelineView()
+		index := selectedStatusIndex(view)
+		if index < 0 || mastodon.CompareIDs(view.statuses[index].ID, view.marker) <= 0 {
+			return nil
+		}
+		view.marker = view.statuses[index].ID
+		view.unread = countNewer(view.statuses, view.marker)
+		view.list.Title = unreadTitle(view.title, view.unread, view.marker)
+	case m.activeTab == tabNotifications && m.notificationsView.filter == 0:
+		// A filtered list skips types, so it cannot say what was read.
+		view := m.notificationsView
+		index := selectedNotificationIndex(view)
+		if index < 0 || mastodon.CompareIDs(view.notifications[index].MostRecent, view.marker) <= 0 {
+			return nil
+		}
+		view.marker = view.notifications[index].MostRecent
+		view.unread = 0
+		for _, item := range view.notifications {
+			if mastodon.CompareIDs(item.MostRecent, view.marker) > 0 {
+				view.unread++
+			}
+		}
+		view.list.Title = unreadTitle(view.title, view.unread, view.marker)
+	default:
+		return nil
+	}
+
+	m.markers.seq++
+	seq := m.markers.seq
+	return tea.Tick(markerSaveDelay, func(time.Time) tea.Msg {
+		return markerTickMsg{seq: seq}
+	})
+}
+
+// pendingMarkersCmd saves positions that moved since the last save, or
+// returns nil when there is nothing to write.
+func (m *model) pendingMarkersCmd() tea.Cmd {
+	if !m.markers.enabled {
+		return nil
+	}
+	home := m.timelineViews[modeHome].marker
+	if home == m.markers.savedHome {
+		home = ""
+	}
+	notifications := m.notificationsView.marker
+	if notifications == m.markers.savedNotifications {
+		notifications = ""
+	}
+	if home == "" && notifications == "" {
+		return nil
+	}
+	return saveMarkersCmd(m.client, home, notifications)
+}
+
+func (m *model) markersSaved(msg markersSavedMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.activeStatusMessage(fmt.Sprintf("Could not save read position: %v", msg.err))
+	}
+	if msg.home != "" {
+		m.markers.savedHome = msg.home
+	}
+	if msg.notifications != "" {
+		m.markers.savedNotifications = msg.notifications
+	}
+	return nil
+}
+
+func countNewer(statuses []mastodon.Status, marker string) int {
+	count := 0
+	for _, status := range statuses {
+		if mastodon.CompareIDs(status.ID, marker) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func unreadTitle(title string, unread int, marker string) string {
+	if marker == "" {
+		return title
+	}
+	if unread == 0 {
+		return title + " · all read"
+	}
+	return fmt.Sprintf("%s · %d unread", title, unread)
+}
+
+func dividerItem() timelineItem {
+	return timelineItem{
+		index:   -1,
+		title:   dividerStyle.Render("──── Last read ────"),
+		snippet: "Newer items are above.",
+	}
+}
@@ -0,0 +1,215 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"mastodoncli/internal/browser"
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+	"mastodoncli/internal/preview"
+	"mastodoncli/internal/ui/components"
+)
+
+var mediaStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("33"))
+
+const previewRows = 12
+
+// previewState caches decoded thumbnails for the detail pane. Graphics
+// protocols do not survive the viewport, so the TUI always renders them
+// with half blocks.
+type previewState struct {
+	cache   *preview.Cache
+	images  map[string]image.Image
+	errors  map[string]error
+	pending map[string]bool
+}
+
+type previewMsg struct {
+	url string
+	img image.Image
+	err error
+}
+
+func newPreviewState(cache *preview.Cache) *previewState {
+	return &previewState{
+		cache:   cache,
+		images:  make(map[string]image.Image),
+		errors:  make(map[string]error),
+		pending: make(map[string]bool),
+	}
+}
+
+func fetchPreviewCmd(cache *preview.Cache, url string) tea.Cmd {
+	return func() tea.Msg {
+		img, err := cache.Image(url)
+		return previewMsg{url: url, img: img, err: err}
+	}
+}
+
+func (p *previewState) store(msg previewMsg) {
+	delete(p.pending, msg.url)
+	if msg.err != nil {
+		p.errors[msg.url] = msg.err
+		return
+	}
+	p.images[msg.url] = msg.img
+}
+
+// previewCmd starts downloads for the thumbnails of the selected status
+// that are neither cached nor already requested.
+func (m *model) previewCmd() tea.Cmd {
+	if !m.showPreviews || m.previews == nil || m.previews.cache == nil {
+		return nil
+	}
+	status := m.selectedStatus()
+	if status == nil {
+		return nil
+	}
+
+	var cmds []tea.Cmd
+	for _, attachment := range displayStatus(status).MediaAttachments {
+		url := attachment.PreviewURL
+		if !output.Previewable(attachment) || m.previews.pending[url] {
+			continue
+		}
+		if _, ok := m.previews.images[url]; ok {
+			continue
+		}
+		if _, ok := m.previews.errors[url]; ok {
+			continue
+		}
+		m.previews.pending[url] = true
+		cmds = append(cmds, fetchPreviewCmd(m.previews.cache, url))
+	}
+	return tea.Batch(cmds...)
+}
+
+// selectedStatus returns the status under the cursor in the active tab.
+func (m *model) selectedStatus() *mastodon.Status {
+	switch m.activeTab {
+	case tabTimeline, tabProfile:
+		view := m.profileView
+		if m.activeTab == tabTimeline {
+			view = m.timelineView()
+		}
+		if index := selectedStatusIndex(view); index >= 0 {
+			return &view.statuses[index]
+		}
+	case tabNotifications:
+		view := m.notificationsView
+		if index := selectedNotificationIndex(view); index >= 0 {
+			return view.notifications[index].Status
+		}
+	}
+	return nil
+}
+
+func (m *model) statusPreviews() *previewState {
+	if !m.showPreviews {
+		return nil
+	}
+	return m.previews
+}
+
+func (m *model) togglePreviews() (tea.Model, tea.Cmd) {
+	if m.previews == nil || m.previews.cache == nil {
+		return m, m.activeStatusMessage("Previews unavailable: no media cache.")
+	}
+	m.showPreviews = !m.showPreviews
+	m.renderCurrentDetail()
+	message := "Media previews off."
+	if m.showPreviews {
+		message = "Media previews on."
+	}
+	return m, tea.Batch(m.activeStatusMessage(message), m.previewCmd())
+}
+
+// openOriginal opens the first attachment of the selected status, or the
+// status page when it has no media.
+func (m *model) openOriginal() (tea.Model, tea.Cmd) {
+	status := m.selectedStatus()
+	if status == nil {
+		return m, nil
+	}
+	display := displayStatus(status)
+	url := display.URL
+	if len(display.MediaAttachments) > 0 {
+		url = output.AttachmentURL(display.MediaAttachments[0])
+	}
+	if url == "" {
+		return m, m.activeStatusMessage("Nothing to open.")
+	}
+	if err := browser.Open(url); err != nil {
+		return m, m.activeStatusMessage(fmt.Sprintf("Error: %v", err))
+	}
+	return m, m.activeStatusMessage("Opened in browser.")
+}
+
+func (m *model) activeStatusMessage(message string) tea.Cmd {
+	switch m.activeTab {
+	case tabTimeline:
+		return m.timelineView().list.NewStatusMessage(message)
+	case tabProfile:
+		return m.profileView.list.NewStatusMessage(message)
+	case tabNotifications:
+		return m.notificationsView.list.NewStatusMessage(message)
+	case tabMetrics:
+		return m.metricsView.list.NewStatusMessage(message)
+	case tabScheduled:
+		return m.scheduledView.list.NewStatusMessage(message)
+	default:
+		return nil
+	}
+}
+
+func displayStatus(status *mastodon.Status) *mastodon.Status {
+	if status.Reblog != nil {
+		return status.Reblog
+	}
+	return status
+}
+
+func renderAttachments(status mastodon.Status, expanded bool, previews *previewState, width int) string {
+	if len(status.MediaAttachments) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(mediaStyle.Render("Media:"))
+	builder.WriteString(" (o to open)\n")
+	for i, attachment := range status.MediaAttachments {
+		builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, output.AttachmentSummary(attachment)))
+		alt := output.WrapText(output.AttachmentAlt(attachment), components.Max(10, width-5))
+		builder.WriteString("   Alt: ")
+		builder.WriteString(strings.ReplaceAll(alt, "\n", "\n        "))
+		builder.WriteString("\n   ")
+		builder.WriteString(components.MutedStyle.Render(output.AttachmentURL(attachment)))
+		builder.WriteString("\n")
+
+		if previews == nil || !output.Previewable(attachment) {
+			continue
+		}
+		if status.Sensitive && !expanded {
+			builder.WriteString(components.MutedStyle.Render("   Preview hidden for sensitive media (e to reveal)."))
+			builder.WriteString("\n")
+			continue
+		}
+		url := attachment.PreviewURL
+		if img, ok := previews.images[url]; ok {
+			builder.WriteString(preview.HalfBlocks(img, components.Max(10, width-2), previewRows))
+			builder.WriteString("\n")
+		} else if err, ok := previews.errors[url]; ok {
+			builder.WriteString(components.MutedStyle.Render(fmt.Sprintf("   Preview unavailable: %v", err)))
+			builder.WriteString("\n")
+		} else {
+			builder.WriteString(components.MutedStyle.Render("   Loading preview..."))
+			builder.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
@@ -12,6 +13,7 @@ import (
 
 	"mastodoncli/internal/mastodon"
 	"mastodoncli/internal/metrics"
+	"mastodoncli/internal/output"
 	"mastodoncli/internal/ui/components"
 )
 
@@ -19,24 +21,58 @@ var (
 	metricsFollowStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("70"))
 	metricsLikeStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
 	metricsBoostStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("33"))
+	metricsLostStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("167"))
+	metricsAnomalyStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("213")).Bold(true)
 	metricsSelectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("86")).Bold(true)
 )
 
+// metricsSeriesColors gives every series in metrics.AllSeries its own color.
+var metricsSeriesColors = map[string]lipgloss.Color{
+	"follows":  "70",
+	"likes":    "220",
+	"boosts":   "33",
+	"mentions": "141",
+	"replies":  "208",
+	"quotes":   "45",
+	"requests": "118",
+	"polls":    "175",
+	"edits":    "246",
+}
+
+func metricsSeriesStyle(key string) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(metricsSeriesColors[key])
+}
+
 type metricsView struct {
-	list           list.Model
-	detail         viewport.Model
-	series         []metrics.DailyMetric
-	loading        bool
-	selected       int
-	rangeDays      int
-	progressDone   int
-	progressTotal  int
-	progressActive bool
-	progressCh     <-chan metricsProgressMsg
+	list              list.Model
+	detail            viewport.Model
+	series            []metrics.DailyMetric
+	posts             []metrics.PostStat
+	previous          []metrics.DailyMetric
+	anomalies         []metrics.Anomaly
+	compare           bool
+	showHeatmap       bool
+	engagementHeatmap metrics.Heatmap
+	postingHeatmap    metrics.Heatmap
+	loading           bool
+	selected          int
+	rangeDays         int
+	bucket            metrics.Bucket
+	enabled           map[string]bool
+	seriesCursor      int
+	progressDone      int
+	progressTotal     int
+	progressActive    bool
+	progressCh        <-chan metricsProgressMsg
 }
 
 type metricsMsg struct {
-	series []metrics.DailyMetric
+	series     []metrics.DailyMetric
+	previous   []metrics.DailyMetric
+	anomalies  []metrics.Anomaly
+	posts      []metrics.PostStat
+	engagement metrics.Heatmap
+	posting    metrics.Heatmap
 }
 
 type metricsProgressMsg struct {
@@ -68,8 +104,12 @@ func newMetricsView(title string) *metricsView {
 	l.SetShowPagination(true)
 	l.AdditionalFullHelpKeys = func() []key.Binding {
 		return []key.Binding{
-			key.NewBinding(key.WithKeys("7"), key.WithHelp("7", "last 7 days")),
-			key.NewBinding(key.WithKeys("3"), key.WithHelp("3", "last 30 days")),
+			key.NewBinding(key.WithKeys("[", "]"), key.WithHelp("[/]", "range")),
+			key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "bucket")),
+			key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "heatmap")),
+			key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "compare")),
+			key.NewBinding(key.WithKeys(",", "."), key.WithHelp(",/.", "pick series")),
+			key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "toggle series")),
 			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
 			key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next tab")),
 			key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "prev tab")),
@@ -81,12 +121,65 @@ func newMetricsView(title string) *metricsView {
 	vp := viewport.New(0, 0)
 	vp.YPosition = 0
 
+	enabled := make(map[string]bool, len(metrics.DefaultSeriesKeys))
+	for _, key := range metrics.DefaultSeriesKeys {
+		enabled[key] = true
+	}
+
 	return &metricsView{
 		list:      l,
 		detail:    vp,
 		loading:   true,
-		rangeDays: 7,
+		rangeDays: metricsRanges[0],
+		bucket:    metrics.BucketDay,
+		enabled:   enabled,
+	}
+}
+
+// metricsRanges are the presets offered by the range picker, in days.
+var metricsRanges = []int{7, 30, 90, 180, 365}
+
+func (v *metricsView) window() metrics.Window {
+	return metrics.LastDays(v.rangeDays, time.Now(), v.bucket)
+}
+
+func (v *metricsView) title() string {
+	return fmt.Sprintf("Metrics (%s)", v.window().Describe(time.Now()))
+}
+
+// activeSeries returns the enabled series in legend order.
+func (v *metricsView) activeSeries() []metrics.Series {
+	active := make([]metrics.Series, 0, len(metrics.AllSeries))
+	for _, series := range metrics.AllSeries {
+		if v.enabled[series.Key] {
+			active = append(active, series)
+		}
 	}
+	return active
+}
+
+// moveSeriesCursor moves the legend cursor by delta, wrapping around.
+func (m *model) moveSeriesCursor(delta int) (tea.Model, tea.Cmd) {
+	view := m.metricsView
+	view.seriesCursor = (view.seriesCursor + delta + len(metrics.AllSeries)) % len(metrics.AllSeries)
+	m.renderCurrentDetail()
+	return m, nil
+}
+
+// toggleSeries shows or hides the series under the legend cursor; the last
+// visible series stays on.
+func (m *model) toggleSeries() (tea.Model, tea.Cmd) {
+	view := m.metricsView
+	key := metrics.AllSeries[view.seriesCursor].Key
+	if view.enabled[key] && len(view.activeSeries()) == 1 {
+		return m, nil
+	}
+	view.enabled[key] = !view.enabled[key]
+	index := view.list.Index()
+	m.setMetrics(view, view.series)
+	view.list.Select(index)
+	m.renderCurrentDetail()
+	return m, nil
 }
 
 func (m *model) ensureMetricsLoaded() tea.Cmd {
@@ -94,20 +187,7 @@ func (m *model) ensureMetricsLoaded() tea.Cmd {
 	if !view.loading && len(view.series) > 0 {
 		return nil
 	}
-	view.loading = true
-	view.progressActive = true
-	view.progressDone = 0
-	view.progressTotal = 0
-	view.list.Title = fmt.Sprintf("Metrics (%dd)", view.rangeDays)
-	view.list.SetItems([]list.Item{loadingItem("Loading metrics...", "Scanning groups...")})
-	view.list.StartSpinner()
-	progressCh := make(chan metricsProgressMsg, 4)
-	view.progressCh = progressCh
-	return tea.Batch(
-		fetchMetricsCmd(m.client, view.rangeDays, progressCh),
-		listenMetricsProgressCmd(progressCh),
-		m.spinner.Tick,
-	)
+	return m.loadMetrics()
 }
 
 func (m *model) switchMetricsRange(days int) (tea.Model, tea.Cmd) {
@@ -116,32 +196,75 @@ func (m *model) switchMetricsRange(days int) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	view.rangeDays = days
+	return m, m.loadMetrics()
+}
+
+// stepMetricsRange moves the range picker by delta presets.
+func (m *model) stepMetricsRange(delta int) (tea.Model, tea.Cmd) {
+	current := 0
+	for i, days := range metricsRanges {
+		if days == m.metricsView.rangeDays {
+			current = i
+		}
+	}
+	next := (current + delta + len(metricsRanges)) % len(metricsRanges)
+	return m.switchMetricsRange(metricsRanges[next])
+}
+
+func (m *model) cycleMetricsBucket() (tea.Model, tea.Cmd) {
+	view := m.metricsView
+	for i, bucket := range metrics.Buckets {
+		if bucket == view.bucket {
+			view.bucket = metrics.Buckets[(i+1)%len(metrics.Buckets)]
+			break
+		}
+	}
+	return m, m.loadMetrics()
+}
+
+func (m *model) loadMetrics() tea.Cmd {
+	view := m.metricsView
 	view.loading = true
 	view.progressActive = true
 	view.progressDone = 0
 	view.progressTotal = 0
-	view.list.Title = fmt.Sprintf("Metrics (%dd)", view.rangeDays)
-	view.list.SetItems([]list.Item{loadingItem("Loading metrics...", "Scanning groups...")})
+	view.list.Title = view.title()
+	view.list.SetItems([]list.Item{loadingItem("Loading metrics...", "Syncing notifications...")})
 	view.list.StartSpinner()
 	progressCh := make(chan metricsProgressMsg, 4)
 	view.progressCh = progressCh
-	return m, tea.Batch(
-		fetchMetricsCmd(m.client, view.rangeDays, progressCh),
+	return tea.Batch(
+		fetchMetricsCmd(m.client, view.window(), progressCh),
 		listenMetricsProgressCmd(progressCh),
 		m.spinner.Tick,
 	)
 }
 
-func fetchMetricsCmd(client *mastodon.Client, days int, progressCh chan<- metricsProgressMsg) tea.Cmd {
+func fetchMetricsCmd(client *mastodon.Client, window metrics.Window, progressCh chan<- metricsProgressMsg) tea.Cmd {
 	return func() tea.Msg {
-		series, err := metrics.FetchDailyMetrics(client, days, func(scanned int) {
+		comparison, err := metrics.FetchComparison(client, window, func(scanned int) {
 			progressCh <- metricsProgressMsg{done: scanned}
 		})
 		close(progressCh)
 		if err != nil {
 			return feedErrMsg{tab: tabMetrics, err: err}
 		}
-		return metricsMsg{series: series}
+		posts, err := metrics.FetchPosts(client, window)
+		if err != nil {
+			return feedErrMsg{tab: tabMetrics, err: err}
+		}
+		history, err := metrics.LoadHistory()
+		if err != nil {
+			return feedErrMsg{tab: tabMetrics, err: err}
+		}
+		return metricsMsg{
+			series:     comparison.Current,
+			previous:   comparison.Previous,
+			anomalies:  comparison.Anomalies,
+			posts:      posts,
+			engagement: metrics.EngagementHeatmap(history, window, time.Local),
+			posting:    metrics.PostingHeatmap(posts, time.Local),
+		}
 	}
 }
 
@@ -161,12 +284,16 @@ func (m *model) setMetrics(view *metricsView, series []metrics.DailyMetric) {
 	if len(series) == 0 {
 		items = append(items, emptyItem("No metrics", "Nothing to show here yet."))
 	} else {
-		for _, item := range series {
-			items = append(items, metricsToItem(item))
+		for i, item := range series {
+			anomaly := metrics.AnomalyNone
+			if i < len(view.anomalies) {
+				anomaly = view.anomalies[i]
+			}
+			items = append(items, metricsToItem(item, anomaly, view.activeSeries()))
 		}
 	}
 	view.list.SetItems(items)
-	view.list.Title = fmt.Sprintf("Metrics (%dd)", view.rangeDays)
+	view.list.Title = view.title()
 }
 
 func (m *model) renderMetricsDetail(view *metricsView) {
@@ -186,7 +313,58 @@ func (m *model) renderMetricsDetail(view *metricsView) {
 	if selected < 0 || selected >= len(view.series) {
 		selected = 0
 	}
-	view.detail.SetContent(renderMetricsChart(view.series, view.detail.Width, selected))
+	if view.showHeatmap {
+		view.detail.SetContent(renderMetricsHeatmaps(view, view.detail.Width))
+		return
+	}
+	content := renderMetricsChart(view.series, view.anomalies, view.activeSeries(), view.seriesCursor, view.detail.Width, selected)
+	if view.compare {
+		content = renderMetricsComparison(view) + "\n\n" + content
+	}
+	day := view.series[selected]
+	posts := metrics.PostsBetween(view.posts, day.Date, view.bucket.Next(day.Date))
+	content += "\n\n" + renderMetricsPosts(day.Label, posts, view.detail.Width)
+	view.detail.SetContent(content)
+}
+
+// renderMetricsPosts lists the posts published in the selected bucket,
+// best first.
+func renderMetricsPosts(label string, posts []metrics.PostStat, width int) string {
+	const maxPosts = 5
+
+	var builder strings.Builder
+	builder.WriteString(metricsSelectedStyle.Render(fmt.Sprintf("Posts on %s (%d)", label, len(posts))))
+	if len(posts) == 0 {
+		builder.WriteString("\n")
+		builder.WriteString(components.MutedStyle.Render("No posts."))
+		return builder.String()
+	}
+
+	report := metrics.BuildPostsReport(posts)
+	for i, post := range report.Posts {
+		if i == maxPosts {
+			builder.WriteString(components.MutedStyle.Render(fmt.Sprintf("\n… %d more (mastodon metrics posts)", len(posts)-maxPosts)))
+			break
+		}
+		builder.WriteString(fmt.Sprintf("\n%d. %s  score %d  ", i+1, post.CreatedAt.Format("15:04"), post.Score()))
+		builder.WriteString(metricsLikeStyle.Render(fmt.Sprintf("♥%d", post.Favourites)))
+		builder.WriteString(" ")
+		builder.WriteString(metricsBoostStyle.Render(fmt.Sprintf("⟳%d", post.Reblogs)))
+		builder.WriteString(fmt.Sprintf(" ↩%d", post.Replies))
+		builder.WriteString(components.MutedStyle.Render(fmt.Sprintf("  1h %s · 24h %s",
+			output.FormatEngagement(post.FirstHour), output.FormatEngagement(post.FirstDay))))
+		builder.WriteString("\n   ")
+		builder.WriteString(output.PostExcerpt(post, components.Max(20, width-4)))
+	}
+	if len(report.Hashtags) > 0 {
+		tags := make([]string, 0, len(report.Hashtags))
+		for _, entry := range report.Hashtags {
+			tags = append(tags, fmt.Sprintf("%s %d", entry.Key, entry.Score))
+		}
+		builder.WriteString("\nTags: ")
+		builder.WriteString(strings.Join(tags, " · "))
+	}
+	return builder.String()
 }
 
 func (m model) renderMetrics(view *metricsView) string {
@@ -215,9 +393,19 @@ func (m *model) resizeMetrics(view *metricsView) {
 	view.detail.Height = components.Max(5, m.contentHeight())
 }
 
-func metricsToItem(item metrics.DailyMetric) metricsItem {
+func metricsToItem(item metrics.DailyMetric, anomaly metrics.Anomaly, kinds []metrics.Series) metricsItem {
 	title := item.Label
-	snippet := fmt.Sprintf("F %d · L %d · B %d", item.Follows, item.Likes, item.Boosts)
+	switch anomaly {
+	case metrics.AnomalySpike:
+		title += " " + metricsAnomalyStyle.Render("▲ spike")
+	case metrics.AnomalyDip:
+		title += " " + metricsAnomalyStyle.Render("▼ dip")
+	}
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%s %d", kind.Short, kind.Value(item)))
+	}
+	snippet := strings.Join(parts, " · ")
 	return metricsItem{title: title, snippet: snippet}
 }
 
@@ -225,46 +413,80 @@ func renderMetricsLoading(view *metricsView, spinnerView string) string {
 	var builder strings.Builder
 	builder.WriteString(spinnerView)
 	builder.WriteString(" Loading metrics")
-	builder.WriteString(fmt.Sprintf(" (%dd)...\n", view.rangeDays))
+	builder.WriteString(fmt.Sprintf(" (%s)...\n", view.window().Describe(time.Now())))
 	if view.progressActive {
 		if view.progressTotal > 0 {
-			builder.WriteString(fmt.Sprintf("Scanned %d/%d groups...", view.progressDone, view.progressTotal))
+			builder.WriteString(fmt.Sprintf("Synced %d/%d notifications...", view.progressDone, view.progressTotal))
 		} else if view.progressDone > 0 {
-			builder.WriteString(fmt.Sprintf("Scanned %d groups...", view.progressDone))
+			builder.WriteString(fmt.Sprintf("Synced %d notifications...", view.progressDone))
 		} else {
-			builder.WriteString("Scanning groups...")
+			builder.WriteString("Syncing notifications...")
 		}
 	}
 	return builder.String()
 }
 
-func renderMetricsChart(series []metrics.DailyMetric, width int, selected int) string {
-	header := metrics.FormatTotal(series)
+// renderMetricsComparison shows the current period next to the previous one
+// of the same length.
+func renderMetricsComparison(view *metricsView) string {
+	comparison := metrics.Comparison{Current: view.series, Previous: view.previous}
+	if len(view.previous) == 0 {
+		return components.MutedStyle.Render("No previous period to compare with.")
+	}
+
+	var builder strings.Builder
+	builder.WriteString(metricsSelectedStyle.Render(fmt.Sprintf("vs %s – %s",
+		view.previous[0].Date.Format("Jan 2"), view.previous[len(view.previous)-1].Date.Format("Jan 2"))))
+	for _, change := range comparison.Changes() {
+		percent := change.Percent()
+		switch {
+		case change.Current > change.Previous:
+			percent = metricsFollowStyle.Render(percent)
+		case change.Current < change.Previous:
+			percent = metricsLostStyle.Render(percent)
+		}
+		builder.WriteString(fmt.Sprintf("\n%-9s %5d vs %5d  %s", change.Name, change.Current, change.Previous, percent))
+	}
+	return builder.String()
+}
+
+func renderMetricsChart(series []metrics.DailyMetric, anomalies []metrics.Anomaly, kinds []metrics.Series, cursor int, width int, selected int) string {
+	header := metrics.FormatTotal(series, kinds)
 	lines := make([]string, 0, len(series)+6)
 	lines = append(lines, header)
-	lines = append(lines, renderMetricsLegend())
-	lines = append(lines, renderMetricsSparkline(series, width))
+	lines = append(lines, renderMetricsLegend(kinds, cursor))
+	lines = append(lines, renderMetricsSparkline(series, kinds, width))
+	lines = append(lines, renderMetricsChurn(series, width))
 
 	if selected < 0 || selected >= len(series) {
 		selected = 0
 	}
 	selectedDay := series[selected]
-	lines = append(lines, renderMetricsSelection(selectedDay, series, selected))
+	lines = append(lines, renderMetricsSelection(selectedDay, series, selected, kinds))
 	lines = append(lines, "")
 
-	labelWidth := 6
-	countsTemplate := fmt.Sprintf("F%d L%d B%d", maxCount(series), maxCount(series), maxCount(series))
+	labelWidth := metrics.LabelWidth(series)
+	countsTemplate := metricsCounts(kinds, func(metrics.Series) int { return maxCount(series, kinds) })
+	if lost := maxLost(series); lost > 0 {
+		countsTemplate += fmt.Sprintf(" -%d", lost)
+	}
 	barWidth := width - (labelWidth + 1 + len(countsTemplate) + 3)
 	barWidth = components.Max(10, barWidth)
-	maxTotal := maxTotal(series)
+	maxTotal := maxTotal(series, kinds)
 	if maxTotal == 0 {
 		maxTotal = 1
 	}
 
-	for _, day := range series {
-		counts := fmt.Sprintf("F%d L%d B%d", day.Follows, day.Likes, day.Boosts)
-		bar := renderStackedBar(day, maxTotal, barWidth)
-		line := fmt.Sprintf("%-6s %s %s", day.Label, bar, counts)
+	for i, day := range series {
+		counts := metricsCounts(kinds, func(kind metrics.Series) int { return kind.Value(day) })
+		if day.Lost > 0 {
+			counts += " " + metricsLostStyle.Render(fmt.Sprintf("-%d", day.Lost))
+		}
+		if i < len(anomalies) && anomalies[i] != metrics.AnomalyNone {
+			counts += " " + metricsAnomalyStyle.Render(anomalies[i].Marker())
+		}
+		bar := renderStackedBar(day, kinds, maxTotal, barWidth)
+		line := fmt.Sprintf("%-*s %s %s", labelWidth, day.Label, bar, counts)
 		if selected >= 0 && selected < len(series) && day.Date.Equal(series[selected].Date) {
 			line = metricsSelectedStyle.Render("> " + line)
 		} else {
@@ -276,58 +498,103 @@ func renderMetricsChart(series []metrics.DailyMetric, width int, selected int) s
 	return strings.Join(lines, "\n")
 }
 
+// metricsCounts formats "F1 L2 B3" for the given series.
+func metricsCounts(kinds []metrics.Series, value func(metrics.Series) int) string {
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%s%d", kind.Short, value(kind)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// renderMetricsRanges draws the range picker: the presets, then the
+// bucket selector.
 func (m model) renderMetricsRanges() string {
-	labels := []string{"7d", "30d"}
-	parts := make([]string, 0, len(labels))
-	for _, label := range labels {
+	parts := make([]string, 0, len(metricsRanges)+len(metrics.Buckets)+1)
+	for _, days := range metricsRanges {
 		style := components.TabStyle
-		if (label == "7d" && m.metricsView.rangeDays == 7) || (label == "30d" && m.metricsView.rangeDays == 30) {
+		if m.metricsView.rangeDays == days {
 			style = components.TabActiveStyle
 		}
-		parts = append(parts, components.RenderTabLabel(label, style))
+		parts = append(parts, components.RenderTabLabel(fmt.Sprintf("%dd", days), style))
+	}
+	parts = append(parts, components.MutedStyle.Render("  by "))
+	for _, bucket := range metrics.Buckets {
+		style := components.TabStyle
+		if m.metricsView.bucket == bucket {
+			style = components.TabActiveStyle
+		}
+		parts = append(parts, components.RenderTabLabel(string(bucket), style))
 	}
 
 	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
 }
 
-func renderMetricsLegend() string {
-	parts := []string{
-		metricsFollowStyle.Render("Follows ##"),
-		metricsLikeStyle.Render("Likes ##"),
-		metricsBoostStyle.Render("Boosts ##"),
+// renderMetricsLegend lists every series; disabled ones are muted and the
+// entry under the toggle cursor is underlined.
+func renderMetricsLegend(kinds []metrics.Series, cursor int) string {
+	enabled := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		enabled[kind.Key] = true
+	}
+	parts := make([]string, 0, len(metrics.AllSeries)+1)
+	for i, kind := range metrics.AllSeries {
+		style := components.MutedStyle
+		label := kind.Name + " --"
+		if enabled[kind.Key] {
+			style = metricsSeriesStyle(kind.Key)
+			label = kind.Name + " ##"
+		}
+		if i == cursor {
+			style = style.Underline(true)
+		}
+		parts = append(parts, style.Render(label))
 	}
+	parts = append(parts, metricsLostStyle.Render("Lost -n"))
 	return strings.Join(parts, "  ")
 }
 
-func renderMetricsSelection(selected metrics.DailyMetric, series []metrics.DailyMetric, selectedIndex int) string {
-	total := selected.Follows + selected.Likes + selected.Boosts
-	pctF, pctL, pctB := 0, 0, 0
-	if total > 0 {
-		pctF = (selected.Follows * 100) / total
-		pctL = (selected.Likes * 100) / total
-		pctB = (selected.Boosts * 100) / total
-	}
-	line := fmt.Sprintf("Selected %s  F%d L%d B%d  Pct F%d%% L%d%% B%d%%",
-		selected.Label,
-		selected.Follows,
-		selected.Likes,
-		selected.Boosts,
-		pctF,
-		pctL,
-		pctB,
-	)
+func renderMetricsSelection(selected metrics.DailyMetric, series []metrics.DailyMetric, selectedIndex int, kinds []metrics.Series) string {
+	total := metrics.Total(selected, kinds)
+	line := "Selected " + selected.Label + "  " + metricsCounts(kinds, func(kind metrics.Series) int { return kind.Value(selected) })
+	line += "  Pct " + metricsCounts(kinds, func(kind metrics.Series) int {
+		if total == 0 {
+			return 0
+		}
+		return kind.Value(selected) * 100 / total
+	})
+	if selected.Lost > 0 {
+		line += fmt.Sprintf("  Lost %d", selected.Lost)
+	}
+	if selected.Followers > 0 {
+		line += fmt.Sprintf("  Followers %d", selected.Followers)
+	}
 	if selectedIndex <= 0 {
 		return line + "  Δ n/a"
 	}
 	prev := series[selectedIndex-1]
-	deltaF := selected.Follows - prev.Follows
-	deltaL := selected.Likes - prev.Likes
-	deltaB := selected.Boosts - prev.Boosts
-	return fmt.Sprintf("%s  Δ F%+d L%+d B%+d", line, deltaF, deltaL, deltaB)
+	deltas := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		deltas = append(deltas, fmt.Sprintf("%s%+d", kind.Short, kind.Value(selected)-kind.Value(prev)))
+	}
+	return line + "  Δ " + strings.Join(deltas, " ")
+}
+
+func renderMetricsSparkline(series []metrics.DailyMetric, kinds []metrics.Series, width int) string {
+	return renderSparkline("Trend ", series, width, func(day metrics.DailyMetric) int {
+		return metrics.Total(day, kinds)
+	})
 }
 
-func renderMetricsSparkline(series []metrics.DailyMetric, width int) string {
-	const label = "Trend "
+// renderMetricsChurn plots lost followers per bucket in the same layout as
+// the trend line.
+func renderMetricsChurn(series []metrics.DailyMetric, width int) string {
+	return metricsLostStyle.Render(renderSparkline("Churn ", series, width, func(day metrics.DailyMetric) int {
+		return day.Lost
+	}))
+}
+
+func renderSparkline(label string, series []metrics.DailyMetric, width int, value func(metrics.DailyMetric) int) string {
 	if len(series) == 0 {
 		return label + "-"
 	}
@@ -345,16 +612,20 @@ func renderMetricsSparkline(series []metrics.DailyMetric, width int) string {
 		points = trimmed
 	}
 
-	maxTotal := maxTotal(points)
-	if maxTotal == 0 {
-		maxTotal = 1
+	maxValue := 0
+	for _, day := range points {
+		if v := value(day); v > maxValue {
+			maxValue = v
+		}
+	}
+	if maxValue == 0 {
+		maxValue = 1
 	}
 	ramp := " .:-=+*#"
 	var builder strings.Builder
 	builder.WriteString(label)
 	for _, day := range points {
-		total := day.Follows + day.Likes + day.Boosts
-		index := (total * (len(ramp) - 1)) / maxTotal
+		index := (value(day) * (len(ramp) - 1)) / maxValue
 		if index < 0 {
 			index = 0
 		}
@@ -366,23 +637,27 @@ func renderMetricsSparkline(series []metrics.DailyMetric, width int) string {
 	return builder.String()
 }
 
-func renderStackedBar(day metrics.DailyMetric, maxTotal, width int) string {
+func renderStackedBar(day metrics.DailyMetric, kinds []metrics.Series, maxTotal, width int) string {
 	if width <= 0 {
 		return ""
 	}
-	total := day.Follows + day.Likes + day.Boosts
+	total := metrics.Total(day, kinds)
 	if total == 0 {
 		return strings.Repeat(" ", width)
 	}
 
-	segments := []int{day.Follows, day.Likes, day.Boosts}
+	segments := make([]int, len(kinds))
+	for i, kind := range kinds {
+		segments[i] = kind.Value(day)
+	}
 	lengths := scaledSegments(segments, maxTotal, width)
 	var builder strings.Builder
 	builder.Grow(width)
-	builder.WriteString(metricsFollowStyle.Render(strings.Repeat("#", lengths[0])))
-	builder.WriteString(metricsLikeStyle.Render(strings.Repeat("#", lengths[1])))
-	builder.WriteString(metricsBoostStyle.Render(strings.Repeat("#", lengths[2])))
-	filled := lengths[0] + lengths[1] + lengths[2]
+	filled := 0
+	for i, kind := range kinds {
+		builder.WriteString(metricsSeriesStyle(kind.Key).Render(strings.Repeat("#", lengths[i])))
+		filled += lengths[i]
+	}
 	if filled < width {
 		builder.WriteString(strings.Repeat(" ", width-filled))
 	}
@@ -390,15 +665,11 @@ func renderStackedBar(day metrics.DailyMetric, maxTotal, width int) string {
 }
 
 func scaledSegments(values []int, maxTotal, width int) []int {
-	if width <= 0 {
-		return []int{0, 0, 0}
-	}
-	total := values[0] + values[1] + values[2]
-	if total == 0 {
-		return []int{0, 0, 0}
+	lengths := make([]int, len(values))
+	if width <= 0 || sum(values) == 0 {
+		return lengths
 	}
 
-	lengths := make([]int, 3)
 	for i, value := range values {
 		lengths[i] = (value * width) / maxTotal
 	}
@@ -417,7 +688,7 @@ func ensureMinimumSegments(values, lengths []int, width int) {
 }
 
 func normalizeSegments(lengths []int, width int) {
-	total := lengths[0] + lengths[1] + lengths[2]
+	total := sum(lengths)
 	if total == width {
 		return
 	}
@@ -436,6 +707,14 @@ func normalizeSegments(lengths []int, width int) {
 	}
 }
 
+func sum(values []int) int {
+	total := 0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
 func maxIndex(values []int) int {
 	maxIdx := 0
 	for i, value := range values {
@@ -446,27 +725,32 @@ func maxIndex(values []int) int {
 	return maxIdx
 }
 
-func maxCount(series []metrics.DailyMetric) int {
+func maxCount(series []metrics.DailyMetric, kinds []metrics.Series) int {
 	maxValue := 0
 	for _, day := range series {
-		if day.Follows > maxValue {
-			maxValue = day.Follows
+		for _, kind := range kinds {
+			if value := kind.Value(day); value > maxValue {
+				maxValue = value
+			}
 		}
-		if day.Likes > maxValue {
-			maxValue = day.Likes
-		}
-		if day.Boosts > maxValue {
-			maxValue = day.Boosts
+	}
+	return maxValue
+}
+
+func maxLost(series []metrics.DailyMetric) int {
+	maxValue := 0
+	for _, day := range series {
+		if day.Lost > maxValue {
+			maxValue = day.Lost
 		}
 	}
 	return maxValue
 }
 
-func maxTotal(series []metrics.DailyMetric) int {
+func maxTotal(series []metrics.DailyMetric, kinds []metrics.Series) int {
 	maxValue := 0
 	for _, day := range series {
-		total := day.Follows + day.Likes + day.Boosts
-		if total > maxValue {
+		if total := metrics.Total(day, kinds); total > maxValue {
 			maxValue = total
 		}
 	}
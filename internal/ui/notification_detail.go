@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+	"mastodoncli/internal/ui/components"
+)
+
+// notificationPayload is the fetched type-specific part of a notification,
+// keyed by notification ID in model.notificationDetails.
+type notificationPayload struct {
+	loading bool
+	detail  *mastodon.NotificationDetail
+	err     error
+}
+
+type notificationDetailMsg struct {
+	id     string
+	detail *mastodon.NotificationDetail
+	err    error
+}
+
+type followRequestMsg struct {
+	accounts []mastodon.Account
+	accept   bool
+	err      error
+}
+
+func fetchNotificationDetailCmd(client *mastodon.Client, id string) tea.Cmd {
+	return func() tea.Msg {
+		detail, err := client.Notification(id)
+		return notificationDetailMsg{id: id, detail: detail, err: err}
+	}
+}
+
+func followRequestCmd(client *mastodon.Client, accounts []mastodon.Account, accept bool) tea.Cmd {
+	return func() tea.Msg {
+		for _, account := range accounts {
+			var err error
+			if accept {
+				err = client.AuthorizeFollowRequest(account.ID)
+			} else {
+				err = client.RejectFollowRequest(account.ID)
+			}
+			if err != nil {
+				return followRequestMsg{accounts: accounts, accept: accept, err: err}
+			}
+		}
+		return followRequestMsg{accounts: accounts, accept: accept}
+	}
+}
+
+func (m *model) selectedNotification() *mastodon.GroupedNotification {
+	view := m.notificationsView
+	if index := selectedNotificationIndex(view); index >= 0 {
+		return &view.notifications[index]
+	}
+	return nil
+}
+
+// notificationDetailCmd loads what the selected notification's renderer
+// needs: the payload for types that have one, and the revisions of an
+// edited status.
+func (m *model) notificationDetailCmd() tea.Cmd {
+	item := m.selectedNotification()
+	if item == nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	if output.NotificationNeedsDetail(item.Type) && item.MostRecent != "" && m.notificationDetails[item.MostRecent] == nil {
+		m.notificationDetails[item.MostRecent] = &notificationPayload{loading: true}
+		cmds = append(cmds, fetchNotificationDetailCmd(m.client, item.MostRecent))
+	}
+	if item.Type == "update" && item.Status != nil && item.Status.EditedAt != "" && m.histories[item.Status.ID] == nil {
+		m.histories[item.Status.ID] = &editHistory{shown: true, loading: true}
+		cmds = append(cmds, fetchHistoryCmd(m.client, item.Status.ID))
+	}
+	if len(cmds) > 0 {
+		m.renderCurrentDetail()
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *model) storeNotificationDetail(msg notificationDetailMsg) {
+	m.notificationDetails[msg.id] = &notificationPayload{detail: msg.detail, err: msg.err}
+	m.renderCurrentDetail()
+}
+
+// answerFollowRequest accepts or rejects the follow request behind the
+// selected notification.
+func (m *model) answerFollowRequest(accept bool) (tea.Model, tea.Cmd) {
+	item := m.selectedNotification()
+	if item == nil || item.Type != "follow_request" || len(item.Accounts) == 0 {
+		return m, m.activeStatusMessage("Select a follow request first.")
+	}
+	if state := m.followRequests[item.Accounts[0].ID]; state != "" {
+		return m, m.activeStatusMessage("Already " + state + ".")
+	}
+	verb := "Rejecting"
+	if accept {
+		verb = "Accepting"
+	}
+	return m, tea.Batch(
+		m.activeStatusMessage(fmt.Sprintf("%s %s...", verb, output.NotificationAccountsLabel(item.Accounts))),
+		followRequestCmd(m.client, item.Accounts, accept),
+	)
+}
+
+func (m *model) followRequestAnswered(msg followRequestMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.activeStatusMessage(fmt.Sprintf("Error: %v", msg.err))
+	}
+	state := "rejected"
+	if msg.accept {
+		state = "accepted"
+	}
+	for _, account := range msg.accounts {
+		m.followRequests[account.ID] = state
+	}
+	m.renderCurrentDetail()
+	return m.activeStatusMessage(fmt.Sprintf("Follow request from %s %s.", output.NotificationAccountsLabel(msg.accounts), state))
+}
+
+// renderNotificationPayload shows the part of the detail pane that depends
+// on the notification type.
+func renderNotificationPayload(item mastodon.GroupedNotification, payload *notificationPayload, answered string, width int) string {
+	var lines []string
+	switch {
+	case item.Type == "follow_request" && answered != "":
+		lines = append(lines, "Request "+answered+".")
+	case item.Type == "follow_request":
+		lines = append(lines, "a to accept, x to reject.")
+	case payload == nil:
+	case payload.loading:
+		lines = append(lines, components.MutedStyle.Render("Loading details..."))
+	case payload.err != nil:
+		lines = append(lines, components.MutedStyle.Render(fmt.Sprintf("Could not load details: %v", payload.err)))
+	case payload.detail != nil:
+		for _, line := range output.NotificationDetailLines(*payload.detail) {
+			lines = append(lines, output.WrapText(line, width))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
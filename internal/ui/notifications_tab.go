@@ -12,22 +12,61 @@ import (
 
 	"mastodoncli/internal/mastodon"
 	"mastodoncli/internal/output"
+	"mastodoncli/internal/rules"
 	"mastodoncli/internal/ui/components"
 )
 
 type notificationsView struct {
 	list          list.Model
 	detail        viewport.Model
+	title         string
 	notifications []mastodon.GroupedNotification
+	marker        string
+	unread        int
+	expanded      map[string]bool
+	hidden        int
 	loading       bool
 	selected      int
+	// filter indexes notificationFilters; nextMaxID pages to older groups.
+	filter    int
+	nextMaxID string
+	// showRequests swaps the list for filtered notification requests.
+	showRequests    bool
+	requests        []mastodon.NotificationRequest
+	requestsLoading bool
+	// confirmReject is set after x until the next key answers.
+	confirmReject bool
 }
 
 type notificationsMsg struct {
 	notifications []mastodon.GroupedNotification
+	filter        int
+	next          string
+	older         bool
 }
 
+type notificationFilter struct {
+	label  string
+	filter mastodon.NotificationFilter
+}
+
+// notificationFilters are the chips above the notifications list.
+var notificationFilters = []notificationFilter{
+	{label: "All"},
+	{label: "Mentions", filter: mastodon.NotificationFilter{Types: []string{"mention"}}},
+	{label: "Follows", filter: mastodon.NotificationFilter{Types: []string{"follow", "follow_request"}}},
+	{label: "Boosts", filter: mastodon.NotificationFilter{Types: []string{"reblog"}}},
+	{label: "Favourites", filter: mastodon.NotificationFilter{Types: []string{"favourite"}}},
+	{label: "Polls", filter: mastodon.NotificationFilter{Types: []string{"poll"}}},
+	{label: "Posts & edits", filter: mastodon.NotificationFilter{Types: []string{"status", "update", "quote", "quoted_update"}}},
+	{label: "No boosts/favs", filter: mastodon.NotificationFilter{ExcludeTypes: []string{"reblog", "favourite"}}},
+}
+
+// mentionsFilter is the chip M jumps to.
+const mentionsFilter = 1
+
 type notificationItem struct {
+	index   int
 	title   string
 	snippet string
 }
@@ -51,6 +90,19 @@ func newNotificationsView(title string) *notificationsView {
 	l.AdditionalFullHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+			key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "toggle CW")),
+			key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open media")),
+			key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "previews")),
+			key.NewBinding(key.WithKeys("V"), key.WithHelp("1-9/V", "poll vote")),
+			key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "edit history")),
+			key.NewBinding(key.WithKeys("a", "x"), key.WithHelp("a/x", "accept/reject follow")),
+			key.NewBinding(key.WithKeys("[", "]"), key.WithHelp("[/]", "filter")),
+			key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "mentions")),
+			key.NewBinding(key.WithKeys("O"), key.WithHelp("O", "older")),
+			key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "requests")),
+			key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "compose")),
+			key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "reply")),
+			key.NewBinding(key.WithKeys("H"), key.WithHelp("H", "show hidden")),
 			key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next tab")),
 			key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "prev tab")),
 			key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit")),
@@ -62,9 +114,11 @@ func newNotificationsView(title string) *notificationsView {
 	vp.YPosition = 0
 
 	return &notificationsView{
-		list:    l,
-		detail:  vp,
-		loading: true,
+		list:     l,
+		detail:   vp,
+		title:    title,
+		expanded: make(map[string]bool),
+		loading:  true,
 	}
 }
 
@@ -77,38 +131,161 @@ func (m *model) ensureNotificationsLoaded() tea.Cmd {
 	view.list.SetItems([]list.Item{loadingItem("Loading notifications...", "Fetching notifications...")})
 	view.list.StartSpinner()
 	return tea.Batch(
-		fetchNotificationsCmd(m.client),
+		fetchNotificationsCmd(m.client, view.filter, ""),
 		m.spinner.Tick,
 	)
 }
 
-func fetchNotificationsCmd(client *mastodon.Client) tea.Cmd {
+// fetchNotificationsCmd loads a page for the filter chip; a maxID loads
+// the groups older than it.
+func fetchNotificationsCmd(client *mastodon.Client, filter int, maxID string) tea.Cmd {
 	return func() tea.Msg {
-		notifications, err := client.GroupedNotifications(40)
+		notifications, next, err := client.NotificationGroupsPage(40, maxID, notificationFilters[filter].filter)
 		if err != nil {
 			return feedErrMsg{tab: tabNotifications, err: err}
 		}
-		return notificationsMsg{notifications: notifications}
+		return notificationsMsg{notifications: notifications, filter: filter, next: next, older: maxID != ""}
 	}
 }
 
 func (m *model) setNotifications(view *notificationsView, notifications []mastodon.GroupedNotification) {
 	view.notifications = notifications
-	items := make([]list.Item, 0, components.Max(1, len(notifications)))
-	if len(notifications) == 0 {
-		items = append(items, emptyItem("No notifications", "Nothing to show here yet."))
-	} else {
-		for _, item := range notifications {
-			items = append(items, notificationToItem(item, view.list.Width()))
+	m.refreshNotificationItems(view)
+}
+
+// appendNotifications adds an older page, skipping groups already shown.
+func (m *model) appendNotifications(view *notificationsView, notifications []mastodon.GroupedNotification) int {
+	seen := make(map[string]bool, len(view.notifications))
+	for _, item := range view.notifications {
+		seen[item.GroupKey] = true
+	}
+	added := 0
+	for _, item := range notifications {
+		if !seen[item.GroupKey] {
+			view.notifications = append(view.notifications, item)
+			added++
+		}
+	}
+	m.refreshNotificationItems(view)
+	return added
+}
+
+func (m model) renderNotificationFilters() string {
+	var parts []string
+	view := m.notificationsView
+	for i, chip := range notificationFilters {
+		style := components.ModeStyle
+		if view.filter == i && !view.showRequests {
+			style = components.ModeActiveStyle
 		}
+		parts = append(parts, components.RenderTabLabel(chip.label, style))
+	}
+	style := components.ModeStyle
+	if view.showRequests {
+		style = components.ModeActiveStyle
+	}
+	parts = append(parts, components.RenderTabLabel("Requests", style))
+	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
+}
+
+// switchNotificationFilter reloads the list for another chip.
+func (m *model) switchNotificationFilter(filter int) (tea.Model, tea.Cmd) {
+	view := m.notificationsView
+	filter = (filter + len(notificationFilters)) % len(notificationFilters)
+	if view.showRequests {
+		view.showRequests = false
+		if filter == view.filter {
+			m.refreshNotificationItems(view)
+			m.renderCurrentDetail()
+			return m, nil
+		}
+	}
+	if filter == view.filter {
+		return m, nil
+	}
+	view.filter = filter
+	view.notifications = nil
+	view.nextMaxID = ""
+	view.loading = false
+	return m, m.ensureNotificationsLoaded()
+}
+
+func (m *model) loadOlderNotifications() (tea.Model, tea.Cmd) {
+	view := m.notificationsView
+	if view.loading || view.showRequests {
+		return m, nil
+	}
+	if view.nextMaxID == "" {
+		return m, view.list.NewStatusMessage("No older notifications.")
+	}
+	view.loading = true
+	view.list.StartSpinner()
+	return m, tea.Batch(
+		fetchNotificationsCmd(m.client, view.filter, view.nextMaxID),
+		m.spinner.Tick,
+	)
+}
+
+func (m *model) refreshNotificationItems(view *notificationsView) {
+	items := make([]list.Item, 0, components.Max(1, len(view.notifications)+1))
+	view.hidden = 0
+	view.unread = 0
+	dividerPlaced := view.marker == ""
+	for i, item := range view.notifications {
+		if !dividerPlaced && mastodon.CompareIDs(item.MostRecent, view.marker) <= 0 {
+			if len(items) > 0 {
+				items = append(items, dividerItem())
+			}
+			dividerPlaced = true
+		}
+
+		verdict := m.rules.Notification(item)
+		if verdict.Action == rules.ActionHide && !m.showHidden {
+			view.hidden++
+			continue
+		}
+		entry := notificationToItem(item, m.notificationExpanded(view, item), view.list.Width())
+		entry.index = i
+		applyVerdict(&entry.title, &entry.snippet, verdict)
+		if !dividerPlaced {
+			view.unread++
+		}
+		items = append(items, entry)
+	}
+	if len(items) == 0 {
+		if view.hidden > 0 {
+			items = append(items, emptyItem("No notifications", fmt.Sprintf("%d hidden by local rules (H to show).", view.hidden)))
+		} else {
+			items = append(items, emptyItem("No notifications", "Nothing to show here yet."))
+		}
+	}
+	if view.nextMaxID != "" {
+		items = append(items, emptyItem("Older notifications", "Press O to load the next page."))
+	}
+	if view.showRequests {
+		return
 	}
 	view.list.SetItems(items)
+	view.list.Title = unreadTitle(view.title, view.unread, view.marker)
+}
+
+// selectedNotificationIndex maps the list selection back to view.notifications.
+func selectedNotificationIndex(view *notificationsView) int {
+	item, ok := view.list.SelectedItem().(notificationItem)
+	if !ok || item.index < 0 || item.index >= len(view.notifications) {
+		return -1
+	}
+	return item.index
 }
 
 func (m *model) renderNotificationsDetail(view *notificationsView) {
 	if view.detail.Width == 0 {
 		return
 	}
+	if view.showRequests {
+		m.renderRequestDetail(view)
+		return
+	}
 	if len(view.notifications) == 0 {
 		if view.loading {
 			view.detail.SetContent(fmt.Sprintf("%s Loading notifications...", m.spinner.View()))
@@ -118,24 +295,45 @@ func (m *model) renderNotificationsDetail(view *notificationsView) {
 		return
 	}
 
-	index := view.list.Index()
-	if index < 0 || index >= len(view.notifications) {
-		index = 0
+	index := selectedNotificationIndex(view)
+	if index < 0 {
+		view.detail.SetContent("No notification selected.")
+		return
+	}
+
+	item := view.notifications[index]
+	state := statusDisplay{
+		verdict:  m.rules.Notification(item),
+		expanded: m.notificationExpanded(view, item),
+		previews: m.statusPreviews(),
+	}
+	if item.Status != nil && item.Status.Poll != nil {
+		state.pollSelection = m.pollSelections[item.Status.Poll.ID]
+	}
+	if item.Status != nil {
+		state.history = m.histories[item.Status.ID]
+	}
+	answered := ""
+	if len(item.Accounts) > 0 {
+		answered = m.followRequests[item.Accounts[0].ID]
 	}
+	payload := renderNotificationPayload(item, m.notificationDetails[item.MostRecent], answered, components.Max(20, view.detail.Width-2))
+	view.detail.SetContent(renderNotificationDetail(item, state, payload, view.detail.Width))
+}
 
-	view.detail.SetContent(renderNotificationDetail(view.notifications[index], view.detail.Width))
+func (m *model) notificationExpanded(view *notificationsView, item mastodon.GroupedNotification) bool {
+	if item.Status == nil {
+		return true
+	}
+	return m.cwExpanded(view.expanded, *item.Status)
 }
 
-func notificationToItem(item mastodon.GroupedNotification, width int) notificationItem {
-	author := notificationAccountsLabel(item.Accounts)
-	title := fmt.Sprintf("%s (%d) · %s · %s", notificationTypeLabel(item.Type), item.Count, author, notificationLatestLabel(item))
-	snippet := ""
+func notificationToItem(item mastodon.GroupedNotification, expanded bool, width int) notificationItem {
+	author := output.NotificationAccountsLabel(item.Accounts)
+	title := fmt.Sprintf("%s (%d) · %s · %s", output.NotificationTypeLabel(item.Type), item.Count, author, notificationLatestLabel(item))
+	snippet := "(no text)"
 	if item.Status != nil {
-		snippet = output.WrapText(output.StripHTML(item.Status.Content), components.Max(20, width-6))
-		snippet = components.TruncateLines(snippet, 2)
-	}
-	if snippet == "" {
-		snippet = "(no text)"
+		snippet = statusSnippet(*item.Status, expanded, width)
 	}
 
 	return notificationItem{
@@ -144,8 +342,8 @@ func notificationToItem(item mastodon.GroupedNotification, width int) notificati
 	}
 }
 
-func renderNotificationDetail(item mastodon.GroupedNotification, width int) string {
-	author := notificationAccountsLabel(item.Accounts)
+func renderNotificationDetail(item mastodon.GroupedNotification, state statusDisplay, payload string, width int) string {
+	author := output.NotificationAccountsLabel(item.Accounts)
 	wrapWidth := components.Max(20, width-2)
 	separator := strings.Repeat("-", width)
 
@@ -154,12 +352,17 @@ func renderNotificationDetail(item mastodon.GroupedNotification, width int) stri
 	builder.WriteString("\n")
 	builder.WriteString(components.AuthorStyle.Render("Type:"))
 	builder.WriteString(" ")
-	builder.WriteString(notificationTypeLabel(item.Type))
+	builder.WriteString(output.NotificationTypeLabel(item.Type))
 	builder.WriteString("\n")
 	builder.WriteString(components.AuthorStyle.Render("From:"))
 	builder.WriteString(" ")
 	builder.WriteString(author)
 	builder.WriteString("\n")
+	if headline := output.NotificationHeadline(item.Type); headline != "" {
+		builder.WriteString("      ")
+		builder.WriteString(headline)
+		builder.WriteString("\n")
+	}
 	builder.WriteString(components.TimeStyle.Render("Time:"))
 	builder.WriteString("   ")
 	builder.WriteString(notificationLatestLabel(item))
@@ -168,68 +371,25 @@ func renderNotificationDetail(item mastodon.GroupedNotification, width int) stri
 	builder.WriteString("  ")
 	builder.WriteString(fmt.Sprintf("%d", item.Count))
 	builder.WriteString("\n")
+	if state.verdict.Matched() {
+		builder.WriteString(renderVerdict(state.verdict))
+		builder.WriteString("\n")
+	}
+	if payload != "" {
+		builder.WriteString(payload)
+		builder.WriteString("\n")
+	}
 
 	if item.Status != nil {
-		builder.WriteString("Text:\n")
-		text := output.WrapText(output.StripHTML(item.Status.Content), wrapWidth)
-		if text == "" {
-			text = "(no text)"
-		}
-		builder.WriteString(text)
+		builder.WriteString(renderStatusBody(*item.Status, state, wrapWidth))
 	}
 
 	return builder.String()
 }
 
-func notificationAccountsLabel(accounts []mastodon.Account) string {
-	if len(accounts) == 0 {
-		return "Unknown"
-	}
-	if len(accounts) == 1 {
-		return formatAccount(accounts[0])
-	}
-	first := formatAccount(accounts[0])
-	return fmt.Sprintf("%s +%d", first, len(accounts)-1)
-}
-
 func notificationLatestLabel(item mastodon.GroupedNotification) string {
 	if item.LatestAt == "" {
 		return "Unknown"
 	}
 	return item.LatestAt
 }
-
-func formatAccount(account mastodon.Account) string {
-	name := strings.TrimSpace(output.StripHTML(account.DisplayName))
-	if name != "" && name != account.Acct {
-		return fmt.Sprintf("%s (@%s)", name, account.Acct)
-	}
-	return fmt.Sprintf("@%s", account.Acct)
-}
-
-func notificationTypeLabel(value string) string {
-	switch value {
-	case "mention":
-		return "Mention"
-	case "status":
-		return "Status"
-	case "reblog":
-		return "Boost"
-	case "favourite":
-		return "Favorite"
-	case "follow":
-		return "Follow"
-	case "follow_request":
-		return "Follow request"
-	case "poll":
-		return "Poll"
-	case "update":
-		return "Update"
-	case "admin.sign_up":
-		return "Sign up"
-	case "admin.report":
-		return "Report"
-	default:
-		return value
-	}
-}
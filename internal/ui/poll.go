@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+	"mastodoncli/internal/ui/components"
+)
+
+var (
+	pollBarStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("33"))
+	pollOwnStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("70")).Bold(true)
+	pollTitleStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("86"))
+)
+
+type pollVotedMsg struct {
+	poll *mastodon.Poll
+	err  error
+}
+
+func votePollCmd(client *mastodon.Client, pollID string, choices []int) tea.Cmd {
+	return func() tea.Msg {
+		poll, err := client.VotePoll(pollID, choices)
+		return pollVotedMsg{poll: poll, err: err}
+	}
+}
+
+// pollChoiceKey maps "1".."9" to a zero-based option index.
+func pollChoiceKey(key string) (int, bool) {
+	if len(key) != 1 || key[0] < '1' || key[0] > '9' {
+		return 0, false
+	}
+	return int(key[0] - '1'), true
+}
+
+func (m *model) selectedPoll() *mastodon.Poll {
+	status := m.selectedStatus()
+	if status == nil {
+		return nil
+	}
+	return displayStatus(status).Poll
+}
+
+// choosePollOption votes right away on single-choice polls and toggles the
+// option on multiple-choice polls until V submits.
+func (m *model) choosePollOption(choice int) (tea.Model, tea.Cmd) {
+	poll := m.selectedPoll()
+	if poll == nil {
+		return m, nil
+	}
+	if poll.Expired || poll.Voted {
+		return m, m.activeStatusMessage("Voting is closed for this poll.")
+	}
+	if choice >= len(poll.Options) {
+		return m, m.activeStatusMessage(fmt.Sprintf("Poll has %d options.", len(poll.Options)))
+	}
+
+	if !poll.Multiple {
+		return m, tea.Batch(
+			m.activeStatusMessage("Voting..."),
+			votePollCmd(m.client, poll.ID, []int{choice}),
+		)
+	}
+
+	selection := m.pollSelections[poll.ID]
+	if selection == nil {
+		selection = make(map[int]bool)
+		m.pollSelections[poll.ID] = selection
+	}
+	selection[choice] = !selection[choice]
+	m.renderCurrentDetail()
+	return m, nil
+}
+
+func (m *model) submitPollVote() (tea.Model, tea.Cmd) {
+	poll := m.selectedPoll()
+	if poll == nil || !poll.Multiple {
+		return m, nil
+	}
+	var choices []int
+	for i := range poll.Options {
+		if m.pollSelections[poll.ID][i] {
+			choices = append(choices, i)
+		}
+	}
+	if len(choices) == 0 {
+		return m, m.activeStatusMessage("Select options with 1-9 first.")
+	}
+	return m, tea.Batch(
+		m.activeStatusMessage("Voting..."),
+		votePollCmd(m.client, poll.ID, choices),
+	)
+}
+
+// updatePoll replaces every copy of the poll across the loaded views.
+func (m *model) updatePoll(poll mastodon.Poll) {
+	replace := func(status *mastodon.Status) {
+		if status == nil {
+			return
+		}
+		display := displayStatus(status)
+		if display.Poll != nil && display.Poll.ID == poll.ID {
+			updated := poll
+			display.Poll = &updated
+		}
+	}
+	views := []*feedView{m.profileView}
+	for _, view := range m.timelineViews {
+		views = append(views, view)
+	}
+	for _, view := range views {
+		for i := range view.statuses {
+			replace(&view.statuses[i])
+		}
+	}
+	for i := range m.notificationsView.notifications {
+		replace(m.notificationsView.notifications[i].Status)
+	}
+	delete(m.pollSelections, poll.ID)
+}
+
+func renderPoll(poll mastodon.Poll, selection map[int]bool, width int) string {
+	var builder strings.Builder
+	builder.WriteString(pollTitleStyle.Render("Poll:"))
+	builder.WriteString("   ")
+	builder.WriteString(output.PollSummary(poll, time.Now()))
+	builder.WriteString("\n")
+
+	barWidth := components.Max(10, width-10)
+	for i, option := range poll.Options {
+		marker := "  "
+		switch {
+		case output.PollVotedFor(poll, i):
+			marker = pollOwnStyle.Render("✓ ")
+		case selection[i]:
+			marker = pollOwnStyle.Render("● ")
+		}
+		builder.WriteString(fmt.Sprintf("%s%d. %s\n", marker, i+1, option.Title))
+
+		pct, ok := output.PollPercent(poll, i)
+		if !ok {
+			continue
+		}
+		filled := min((pct*barWidth)/100, barWidth)
+		if pct > 0 && filled == 0 {
+			filled = 1
+		}
+		builder.WriteString("   ")
+		builder.WriteString(pollBarStyle.Render(strings.Repeat("#", filled)))
+		builder.WriteString(components.MutedStyle.Render(strings.Repeat(".", barWidth-filled)))
+		builder.WriteString(fmt.Sprintf(" %3d%%\n", pct))
+	}
+
+	switch {
+	case poll.Expired || poll.Voted:
+	case poll.Multiple:
+		builder.WriteString(components.MutedStyle.Render("1-9 select · V vote"))
+	default:
+		builder.WriteString(components.MutedStyle.Render("1-9 vote"))
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
@@ -0,0 +1,235 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+	"mastodoncli/internal/ui/components"
+)
+
+// The Requests subsection of the notifications tab lists accounts whose
+// notifications the server filtered by the notification policy.
+
+type requestItem struct {
+	index   int
+	title   string
+	snippet string
+}
+
+func (r requestItem) Title() string       { return r.title }
+func (r requestItem) Description() string { return r.snippet }
+func (r requestItem) FilterValue() string { return r.title + " " + r.snippet }
+
+type requestsMsg struct {
+	requests []mastodon.NotificationRequest
+	err      error
+}
+
+type requestAnsweredMsg struct {
+	id     string
+	accept bool
+	err    error
+}
+
+func fetchRequestsCmd(client *mastodon.Client) tea.Cmd {
+	return func() tea.Msg {
+		requests, _, err := client.NotificationRequestsPage(80, "")
+		return requestsMsg{requests: requests, err: err}
+	}
+}
+
+func answerRequestCmd(client *mastodon.Client, id string, accept bool) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		if accept {
+			err = client.AcceptNotificationRequest(id)
+		} else {
+			err = client.DismissNotificationRequest(id)
+		}
+		return requestAnsweredMsg{id: id, accept: accept, err: err}
+	}
+}
+
+// toggleRequests switches the notifications tab between notifications and
+// filtered requests, loading the requests each time they are opened.
+func (m *model) toggleRequests() (tea.Model, tea.Cmd) {
+	view := m.notificationsView
+	view.showRequests = !view.showRequests
+	view.selected = -1
+	if !view.showRequests {
+		m.refreshNotificationItems(view)
+		view.list.Select(0)
+		m.renderCurrentDetail()
+		return m, nil
+	}
+	view.requestsLoading = true
+	view.list.SetItems([]list.Item{loadingItem("Loading requests...", "Fetching filtered notifications...")})
+	view.list.Select(0)
+	view.list.StartSpinner()
+	m.renderCurrentDetail()
+	return m, tea.Batch(fetchRequestsCmd(m.client), m.spinner.Tick)
+}
+
+func (m *model) setRequests(msg requestsMsg) tea.Cmd {
+	view := m.notificationsView
+	view.requestsLoading = false
+	view.list.StopSpinner()
+	if msg.err != nil {
+		view.requests = nil
+		if view.showRequests {
+			m.refreshRequestItems(view)
+		}
+		return view.list.NewStatusMessage(fmt.Sprintf("Error: %v", msg.err))
+	}
+	view.requests = msg.requests
+	if !view.showRequests {
+		return nil
+	}
+	m.refreshRequestItems(view)
+	m.renderCurrentDetail()
+	return view.list.NewStatusMessage(fmt.Sprintf("Loaded %d requests.", len(msg.requests)))
+}
+
+func (m *model) refreshRequestItems(view *notificationsView) {
+	items := make([]list.Item, 0, components.Max(1, len(view.requests)))
+	for i, request := range view.requests {
+		items = append(items, requestItem{
+			index:   i,
+			title:   fmt.Sprintf("%s · %s notifications", output.FormatAccount(request.Account), request.NotificationsCount),
+			snippet: output.NotificationRequestExcerpt(request, components.Max(20, view.list.Width()-6)),
+		})
+	}
+	if len(items) == 0 {
+		items = append(items, emptyItem("No requests", "Nothing was filtered by your notification policy."))
+	}
+	view.list.SetItems(items)
+}
+
+func selectedRequestIndex(view *notificationsView) int {
+	item, ok := view.list.SelectedItem().(requestItem)
+	if !ok || item.index < 0 || item.index >= len(view.requests) {
+		return -1
+	}
+	return item.index
+}
+
+func (m *model) answerRequest(accept bool) (tea.Model, tea.Cmd) {
+	view := m.notificationsView
+	index := selectedRequestIndex(view)
+	if index < 0 {
+		return m, nil
+	}
+	request := view.requests[index]
+	verb := "Dismissing"
+	if accept {
+		verb = "Accepting"
+	}
+	return m, tea.Batch(
+		view.list.NewStatusMessage(fmt.Sprintf("%s %s...", verb, output.FormatAccount(request.Account))),
+		answerRequestCmd(m.client, request.ID, accept),
+	)
+}
+
+// askRejectRequest arms the confirmation for rejecting the selected follow
+// request or dismissing the selected notification request; neither can be
+// undone. The next key decides.
+func (m *model) askRejectRequest() (tea.Model, tea.Cmd) {
+	view := m.notificationsView
+	var question string
+	if view.showRequests {
+		index := selectedRequestIndex(view)
+		if index < 0 {
+			return m, nil
+		}
+		question = fmt.Sprintf("Dismiss the notifications from %s?", output.FormatAccount(view.requests[index].Account))
+	} else {
+		item := m.selectedNotification()
+		if item == nil || item.Type != "follow_request" || len(item.Accounts) == 0 {
+			return m, m.activeStatusMessage("Select a follow request first.")
+		}
+		if state := m.followRequests[item.Accounts[0].ID]; state != "" {
+			return m, m.activeStatusMessage("Already " + state + ".")
+		}
+		question = fmt.Sprintf("Reject the follow request from %s?", output.NotificationAccountsLabel(item.Accounts))
+	}
+	view.confirmReject = true
+	return m, view.list.NewStatusMessage(question + " y to confirm, any other key to keep it.")
+}
+
+func (m *model) confirmRejectRequest(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	view := m.notificationsView
+	view.confirmReject = false
+	if msg.String() != "y" {
+		return m, view.list.NewStatusMessage("Kept.")
+	}
+	if view.showRequests {
+		return m.answerRequest(false)
+	}
+	return m.answerFollowRequest(false)
+}
+
+// requestAnswered drops the request from the list. Accepted notifications
+// join the main list on the next refresh.
+func (m *model) requestAnswered(msg requestAnsweredMsg) tea.Cmd {
+	view := m.notificationsView
+	if msg.err != nil {
+		return view.list.NewStatusMessage(fmt.Sprintf("Error: %v", msg.err))
+	}
+	for i, request := range view.requests {
+		if request.ID == msg.id {
+			view.requests = append(view.requests[:i], view.requests[i+1:]...)
+			break
+		}
+	}
+	if view.showRequests {
+		m.refreshRequestItems(view)
+		m.renderCurrentDetail()
+	}
+	if msg.accept {
+		return view.list.NewStatusMessage("Request accepted; refresh notifications (r) to see them.")
+	}
+	return view.list.NewStatusMessage("Request dismissed.")
+}
+
+func (m *model) renderRequestDetail(view *notificationsView) {
+	if view.requestsLoading {
+		view.detail.SetContent(fmt.Sprintf("%s Loading requests...", m.spinner.View()))
+		return
+	}
+	index := selectedRequestIndex(view)
+	if index < 0 {
+		view.detail.SetContent("No request selected.")
+		return
+	}
+	view.detail.SetContent(renderRequestDetail(view.requests[index], view.detail.Width))
+}
+
+func renderRequestDetail(request mastodon.NotificationRequest, width int) string {
+	wrapWidth := components.Max(20, width-2)
+	var builder strings.Builder
+	builder.WriteString(strings.Repeat("-", width))
+	builder.WriteString("\n")
+	builder.WriteString(components.AuthorStyle.Render("From:"))
+	builder.WriteString(" ")
+	builder.WriteString(output.FormatAccount(request.Account))
+	builder.WriteString("\n")
+	builder.WriteString(components.TimeStyle.Render("Time:"))
+	builder.WriteString("   ")
+	builder.WriteString(request.UpdatedAt)
+	builder.WriteString("\n")
+	builder.WriteString(components.MutedStyle.Render("Count:"))
+	builder.WriteString("  ")
+	builder.WriteString(request.NotificationsCount)
+	builder.WriteString(" filtered notifications\n")
+	builder.WriteString("a to accept (show them and let future ones through), x to dismiss.\n")
+	if request.LastStatus != nil {
+		builder.WriteString("\n")
+		builder.WriteString(renderStatusBody(*request.LastStatus, statusDisplay{}, wrapWidth))
+	}
+	return builder.String()
+}
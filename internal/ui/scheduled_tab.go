@@ -0,0 +1,425 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+	"mastodoncli/internal/ui/components"
+)
+
+type scheduledEdit int
+
+const (
+	scheduledEditNone scheduledEdit = iota
+	scheduledEditTime
+	scheduledEditText
+)
+
+type scheduledView struct {
+	list          list.Model
+	detail        viewport.Model
+	statuses      []mastodon.ScheduledStatus
+	loading       bool
+	selected      int
+	editing       scheduledEdit
+	confirmCancel bool
+	timeInput     textinput.Model
+	textInput     textarea.Model
+}
+
+type scheduledMsg struct {
+	statuses []mastodon.ScheduledStatus
+}
+
+// scheduledSavedMsg reports an edit; replacedID is the status the edit
+// replaced, which differs from status.ID after a text change.
+type scheduledSavedMsg struct {
+	replacedID string
+	status     *mastodon.ScheduledStatus
+	err        error
+}
+
+type scheduledCancelledMsg struct {
+	id  string
+	err error
+}
+
+type scheduledItem struct {
+	index   int
+	title   string
+	snippet string
+}
+
+func (s scheduledItem) Title() string       { return s.title }
+func (s scheduledItem) Description() string { return s.snippet }
+func (s scheduledItem) FilterValue() string { return s.title + " " + s.snippet }
+
+func newScheduledView(title string) *scheduledView {
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.Foreground(lipgloss.Color("86"))
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.Foreground(lipgloss.Color("86"))
+	delegate.SetHeight(2)
+
+	l := list.New([]list.Item{}, delegate, 0, 0)
+	l.Title = title
+	l.SetShowHelp(true)
+	l.SetFilteringEnabled(false)
+	l.SetShowStatusBar(true)
+	l.SetShowPagination(true)
+	l.AdditionalFullHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "edit time")),
+			key.NewBinding(key.WithKeys("E"), key.WithHelp("E", "edit text")),
+			key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "cancel post")),
+			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+			key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next tab")),
+			key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "prev tab")),
+			key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit")),
+		}
+	}
+	l.DisableQuitKeybindings()
+
+	vp := viewport.New(0, 0)
+	vp.YPosition = 0
+
+	timeInput := textinput.New()
+	timeInput.Placeholder = output.ScheduleTimeLayout
+	timeInput.CharLimit = 25
+	timeInput.Prompt = "At: "
+
+	textInput := textarea.New()
+	textInput.ShowLineNumbers = false
+	textInput.CharLimit = 0
+
+	return &scheduledView{
+		list:      l,
+		detail:    vp,
+		loading:   true,
+		timeInput: timeInput,
+		textInput: textInput,
+	}
+}
+
+func (m *model) ensureScheduledLoaded() tea.Cmd {
+	view := m.scheduledView
+	if !view.loading && len(view.statuses) > 0 {
+		return nil
+	}
+	return m.loadScheduled()
+}
+
+func (m *model) loadScheduled() tea.Cmd {
+	view := m.scheduledView
+	view.loading = true
+	view.list.SetItems([]list.Item{loadingItem("Loading scheduled posts...", "Fetching scheduled statuses...")})
+	view.list.StartSpinner()
+	return tea.Batch(
+		fetchScheduledCmd(m.client),
+		m.spinner.Tick,
+	)
+}
+
+func fetchScheduledCmd(client *mastodon.Client) tea.Cmd {
+	return func() tea.Msg {
+		statuses, err := client.ScheduledStatuses()
+		if err != nil {
+			return feedErrMsg{tab: tabScheduled, err: err}
+		}
+		return scheduledMsg{statuses: statuses}
+	}
+}
+
+func rescheduleCmd(client *mastodon.Client, id string, at time.Time) tea.Cmd {
+	return func() tea.Msg {
+		status, err := client.RescheduleStatus(id, at)
+		return scheduledSavedMsg{replacedID: id, status: status, err: err}
+	}
+}
+
+func replaceScheduledTextCmd(client *mastodon.Client, old mastodon.ScheduledStatus, text string) tea.Cmd {
+	return func() tea.Msg {
+		status, err := client.ReplaceScheduledStatus(old, text)
+		return scheduledSavedMsg{replacedID: old.ID, status: status, err: err}
+	}
+}
+
+func cancelScheduledCmd(client *mastodon.Client, id string) tea.Cmd {
+	return func() tea.Msg {
+		return scheduledCancelledMsg{id: id, err: client.CancelScheduledStatus(id)}
+	}
+}
+
+func (m *model) setScheduled(view *scheduledView, statuses []mastodon.ScheduledStatus) {
+	view.statuses = statuses
+	items := make([]list.Item, 0, components.Max(1, len(statuses)))
+	if len(statuses) == 0 {
+		items = append(items, emptyItem("No scheduled posts", "Schedule one with: mastodon post --at \"YYYY-MM-DD HH:MM\" <text>"))
+	}
+	now := time.Now()
+	for i, status := range statuses {
+		items = append(items, scheduledItem{
+			index:   i,
+			title:   fmt.Sprintf("%s · %s", status.Time().Local().Format(output.ScheduleTimeLayout), output.ScheduledIn(status, now)),
+			snippet: output.ScheduledExcerpt(status, components.Max(20, view.list.Width()-4)),
+		})
+	}
+	view.list.SetItems(items)
+	view.list.Title = fmt.Sprintf("Scheduled (%d)", len(statuses))
+}
+
+func selectedScheduledIndex(view *scheduledView) int {
+	item, ok := view.list.SelectedItem().(scheduledItem)
+	if !ok || item.index < 0 || item.index >= len(view.statuses) {
+		return -1
+	}
+	return item.index
+}
+
+// startScheduledEdit opens the time or text editor for the selected post.
+func (m *model) startScheduledEdit(edit scheduledEdit) (tea.Model, tea.Cmd) {
+	view := m.scheduledView
+	index := selectedScheduledIndex(view)
+	if index < 0 {
+		return m, nil
+	}
+	status := view.statuses[index]
+	view.editing = edit
+	var cmd tea.Cmd
+	switch edit {
+	case scheduledEditTime:
+		view.timeInput.SetValue(status.Time().Local().Format(output.ScheduleTimeLayout))
+		view.timeInput.CursorEnd()
+		cmd = view.timeInput.Focus()
+	case scheduledEditText:
+		view.textInput.SetValue(status.Params.Text)
+		cmd = view.textInput.Focus()
+	}
+	m.resizeScheduled(view)
+	m.renderCurrentDetail()
+	return m, cmd
+}
+
+// updateScheduledEditor sends keys to the open editor: enter saves the
+// time, ctrl+s saves the text and esc discards either.
+func (m *model) updateScheduledEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	view := m.scheduledView
+	index := selectedScheduledIndex(view)
+	if index < 0 {
+		view.editing = scheduledEditNone
+		return m, nil
+	}
+	status := view.statuses[index]
+
+	switch msg.String() {
+	case "esc":
+		m.stopScheduledEdit()
+		return m, view.list.NewStatusMessage("Edit discarded.")
+	case "ctrl+c":
+		m.stopScheduledEdit()
+		return m.handleKey(msg)
+	}
+
+	switch view.editing {
+	case scheduledEditTime:
+		if msg.String() == "enter" {
+			at, err := mastodon.ParseScheduleTime(view.timeInput.Value(), time.Local, time.Now())
+			if err != nil {
+				return m, view.list.NewStatusMessage(fmt.Sprintf("Error: %v", err))
+			}
+			m.stopScheduledEdit()
+			return m, tea.Batch(rescheduleCmd(m.client, status.ID, at), view.list.NewStatusMessage("Rescheduling..."))
+		}
+	case scheduledEditText:
+		if msg.String() == "ctrl+s" {
+			text := strings.TrimSpace(view.textInput.Value())
+			if text == "" && len(status.Params.MediaIDs) == 0 {
+				return m, view.list.NewStatusMessage("Error: status text is required")
+			}
+			m.stopScheduledEdit()
+			if text == status.Params.Text {
+				return m, view.list.NewStatusMessage("No changes.")
+			}
+			return m, tea.Batch(replaceScheduledTextCmd(m.client, status, text), view.list.NewStatusMessage("Saving..."))
+		}
+	}
+	return m, m.updateScheduledInputs(msg)
+}
+
+func (m *model) updateScheduledInputs(msg tea.Msg) tea.Cmd {
+	view := m.scheduledView
+	var cmd tea.Cmd
+	if view.editing == scheduledEditTime {
+		view.timeInput, cmd = view.timeInput.Update(msg)
+	} else {
+		view.textInput, cmd = view.textInput.Update(msg)
+	}
+	m.renderCurrentDetail()
+	return cmd
+}
+
+func (m *model) stopScheduledEdit() {
+	view := m.scheduledView
+	view.editing = scheduledEditNone
+	view.timeInput.Blur()
+	view.textInput.Blur()
+	m.resizeScheduled(view)
+	m.renderCurrentDetail()
+}
+
+// askCancelScheduled arms the cancel confirmation; the next key decides.
+func (m *model) askCancelScheduled() (tea.Model, tea.Cmd) {
+	view := m.scheduledView
+	if selectedScheduledIndex(view) < 0 {
+		return m, nil
+	}
+	view.confirmCancel = true
+	return m, view.list.NewStatusMessage("Cancel this scheduled post? y to confirm, any other key to keep it.")
+}
+
+func (m *model) confirmCancelScheduled(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	view := m.scheduledView
+	view.confirmCancel = false
+	index := selectedScheduledIndex(view)
+	if msg.String() != "y" || index < 0 {
+		return m, view.list.NewStatusMessage("Kept.")
+	}
+	return m, cancelScheduledCmd(m.client, view.statuses[index].ID)
+}
+
+func (m *model) scheduledSaved(msg scheduledSavedMsg) tea.Cmd {
+	view := m.scheduledView
+	if msg.err != nil {
+		return view.list.NewStatusMessage(fmt.Sprintf("Error: %v", msg.err))
+	}
+	statuses := make([]mastodon.ScheduledStatus, 0, len(view.statuses))
+	for _, status := range view.statuses {
+		if status.ID != msg.replacedID {
+			statuses = append(statuses, status)
+		}
+	}
+	statuses = append(statuses, *msg.status)
+	sortScheduledStatuses(statuses)
+	m.setScheduled(view, statuses)
+	for i, status := range statuses {
+		if status.ID == msg.status.ID {
+			view.list.Select(i)
+		}
+	}
+	m.renderCurrentDetail()
+	return view.list.NewStatusMessage("Saved for " + msg.status.Time().Local().Format(output.ScheduleTimeLayout+" MST") + ".")
+}
+
+func (m *model) scheduledCancelled(msg scheduledCancelledMsg) tea.Cmd {
+	view := m.scheduledView
+	if msg.err != nil {
+		return view.list.NewStatusMessage(fmt.Sprintf("Error: %v", msg.err))
+	}
+	statuses := make([]mastodon.ScheduledStatus, 0, len(view.statuses))
+	for _, status := range view.statuses {
+		if status.ID != msg.id {
+			statuses = append(statuses, status)
+		}
+	}
+	m.setScheduled(view, statuses)
+	m.renderCurrentDetail()
+	return view.list.NewStatusMessage("Scheduled post cancelled.")
+}
+
+func sortScheduledStatuses(statuses []mastodon.ScheduledStatus) {
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Time().Before(statuses[j].Time())
+	})
+}
+
+func (m *model) renderScheduledDetail(view *scheduledView) {
+	if view.detail.Width == 0 {
+		return
+	}
+	if len(view.statuses) == 0 {
+		if view.loading {
+			view.detail.SetContent(fmt.Sprintf("%s Loading scheduled posts...", m.spinner.View()))
+		} else {
+			view.detail.SetContent("No scheduled post selected.")
+		}
+		return
+	}
+	index := selectedScheduledIndex(view)
+	if index < 0 {
+		view.detail.SetContent("No scheduled post selected.")
+		return
+	}
+	view.detail.SetContent(renderScheduledDetail(view.statuses[index], view, view.detail.Width))
+}
+
+func renderScheduledDetail(status mastodon.ScheduledStatus, view *scheduledView, width int) string {
+	var builder strings.Builder
+	builder.WriteString(strings.Repeat("-", width))
+	builder.WriteString("\n")
+	builder.WriteString(components.TimeStyle.Render("Scheduled:"))
+	builder.WriteString(" ")
+	builder.WriteString(status.Time().Local().Format(output.ScheduleTimeLayout + " MST"))
+	builder.WriteString(components.MutedStyle.Render(" (" + output.ScheduledIn(status, time.Now()) + ")"))
+	builder.WriteString("\n")
+	for _, line := range output.ScheduledDetails(status) {
+		builder.WriteString(components.MutedStyle.Render(line))
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\n")
+
+	switch view.editing {
+	case scheduledEditTime:
+		builder.WriteString(view.timeInput.View())
+		builder.WriteString("\n")
+		builder.WriteString(components.MutedStyle.Render("enter save · esc discard · times are local"))
+		builder.WriteString("\n\n")
+		builder.WriteString(output.WrapText(status.Params.Text, components.Max(20, width-2)))
+	case scheduledEditText:
+		builder.WriteString(view.textInput.View())
+		builder.WriteString("\n")
+		builder.WriteString(components.MutedStyle.Render("ctrl+s save · esc discard"))
+	default:
+		builder.WriteString(output.WrapText(status.Params.Text, components.Max(20, width-2)))
+	}
+	return builder.String()
+}
+
+func (m model) renderScheduled(view *scheduledView) string {
+	leftWidth := components.Max(30, m.width/2)
+	rightWidth := m.width - leftWidth - 1
+	if rightWidth < 20 {
+		return view.list.View()
+	}
+
+	left := lipgloss.NewStyle().Width(leftWidth).Height(m.contentHeight()).Render(view.list.View())
+	right := lipgloss.NewStyle().Width(rightWidth).Height(m.contentHeight()).Render(view.detail.View())
+	sep := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("│")
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, sep, right)
+}
+
+func (m *model) resizeScheduled(view *scheduledView) {
+	if m.width == 0 || m.height == 0 {
+		return
+	}
+
+	leftWidth := components.Max(30, m.width/2)
+	rightWidth := m.width - leftWidth - 1
+	view.list.SetSize(leftWidth, components.Max(5, m.contentHeight()))
+	view.detail.Width = rightWidth
+	view.detail.Height = components.Max(5, m.contentHeight())
+	view.timeInput.Width = components.Max(10, rightWidth-len(view.timeInput.Prompt)-2)
+	view.textInput.SetWidth(components.Max(20, rightWidth-2))
+	view.textInput.SetHeight(components.Max(3, m.contentHeight()-12))
+}
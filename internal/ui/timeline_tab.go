@@ -19,9 +19,12 @@ const (
 )
 
 type timelineMsg struct {
-	mode     timelineMode
-	statuses []mastodon.Status
-	sinceID  string
+	mode                timelineMode
+	statuses            []mastodon.Status
+	sinceID             string
+	markersLoaded       bool
+	homeMarker          string
+	notificationsMarker string
 }
 
 func (m model) renderTimelineModes() string {
@@ -0,0 +1,118 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// Target is one place items are delivered to. Name identifies it in the
+// watch state, so a failed delivery is repeated only for the targets that
+// did not get the item.
+type Target struct {
+	Name string
+	Send func(ctx context.Context, event string, payload any) error
+}
+
+// Desktop shows a desktop notification through notify-send (which talks
+// to the D-Bus notification service) or, on macOS, osascript.
+func Desktop(summary, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title \"Mastodon\" subtitle %q", body, summary)
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		cmd = exec.Command("notify-send", "--app-name=Mastodon", summary, body)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("desktop notification: %w: %s", err, bytes.TrimSpace(output))
+	}
+	return nil
+}
+
+// Exec runs command with the shell and payload as JSON on its stdin. The
+// command's output goes to ours.
+func Exec(command string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("run %q: %w", command, err)
+	}
+	return nil
+}
+
+// webhookClient bounds each delivery attempt.
+var webhookClient = &http.Client{Timeout: 30 * time.Second}
+
+// Webhook posts payloads as JSON to URL. Failed deliveries (network
+// errors, 429 and 5xx answers) are repeated Retries times, waiting twice
+// as long each time.
+type Webhook struct {
+	URL     string
+	Retries int
+	// backoff is the first wait; zero means two seconds.
+	backoff time.Duration
+}
+
+// Post delivers payload. event ("status" or "notification") is sent in
+// the X-Mastodon-Event header. Waits between attempts end with ctx.
+func (h Webhook) Post(ctx context.Context, event string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode payload: %w", err)
+	}
+	wait := h.backoff
+	if wait == 0 {
+		wait = 2 * time.Second
+	}
+	for attempt := 0; ; attempt++ {
+		retry, err := h.post(ctx, event, data)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= h.Retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+func (h Webhook) post(ctx context.Context, event string, data []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", h.URL, bytes.NewReader(data))
+	if err != nil {
+		return false, fmt.Errorf("webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "mastodon-cli")
+	req.Header.Set("X-Mastodon-Event", event)
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("webhook: %w", err)
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook: %s", resp.Status)
+}
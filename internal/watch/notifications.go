@@ -0,0 +1,114 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/rules"
+)
+
+// NotificationOptions configures Notifications.
+type NotificationOptions struct {
+	Options
+	Filter mastodon.NotificationFilter
+}
+
+type notificationFollower struct {
+	client *mastodon.Client
+	opts   NotificationOptions
+	cursor Cursor
+}
+
+// Notifications delivers new notifications until ctx is done.
+func Notifications(ctx context.Context, client *mastodon.Client, opts NotificationOptions) error {
+	f := &notificationFollower{client: client, opts: opts}
+	return run(ctx, f, &f.cursor, opts.Options)
+}
+
+func (f *notificationFollower) start() error {
+	latest, _, err := f.client.UngroupedNotificationsPage(1, "", "", mastodon.NotificationFilter{})
+	if err != nil {
+		return err
+	}
+	if len(latest) > 0 {
+		f.cursor.Mark(latest[0].ID)
+	}
+	return nil
+}
+
+func (f *notificationFollower) poll(ctx context.Context) error {
+	var notifications []mastodon.Notification
+	maxID := ""
+	for {
+		page, next, err := f.client.UngroupedNotificationsPage(pageSize, f.cursor.LastID, maxID, f.opts.Filter)
+		if err != nil {
+			return err
+		}
+		notifications = append(notifications, page...)
+		if next == "" || len(page) < pageSize {
+			break
+		}
+		maxID = next
+	}
+	return f.deliver(ctx, notifications, f.cursor.Seen)
+}
+
+func (f *notificationFollower) stream(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var failed error
+	err := f.client.StreamNotifications(ctx, func(notification mastodon.Notification) {
+		if failed == nil {
+			if failed = f.deliver(ctx, []mastodon.Notification{notification}, f.cursor.Repeated); failed != nil {
+				cancel()
+			}
+		}
+	})
+	if failed != nil {
+		return failed
+	}
+	return err
+}
+
+// deliver groups the notifications of a newest-first batch that are not
+// seen yet, hands them out oldest first and records them in the state. It
+// stops at the first failed delivery; that group and the ones after it are
+// not recorded, so the next poll hands them out again.
+func (f *notificationFollower) deliver(ctx context.Context, notifications []mastodon.Notification, seen func(id string) bool) error {
+	var fresh []mastodon.Notification
+	for _, notification := range notifications {
+		if !seen(notification.ID) && f.opts.Filter.Matches(notification.Type) {
+			fresh = append(fresh, notification)
+		}
+	}
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	groups := mastodon.GroupNotifications(fresh)
+	handled := make(map[string]bool)
+	var err error
+	for i := len(groups) - 1; i >= 0; i-- {
+		group := groups[i]
+		if f.opts.Rules.Notification(group).Action != rules.ActionHide && f.opts.Match.Notification(group) {
+			if err = send(ctx, &f.cursor, f.opts.Targets, group.MostRecent, "notification", group); err != nil {
+				err = fmt.Errorf("delivering notification %s: %w", group.MostRecent, err)
+				break
+			}
+		}
+		handled[group.GroupKey] = true
+	}
+
+	var ids []string
+	held := ""
+	for _, notification := range fresh {
+		if handled[mastodon.NotificationGroupKey(notification)] {
+			ids = append(ids, notification.ID)
+		} else if held == "" || NewerID(held, notification.ID) {
+			held = notification.ID
+		}
+	}
+	commit(&f.cursor, ids, held, f.opts.Options)
+	return err
+}
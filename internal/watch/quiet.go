@@ -0,0 +1,66 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QuietHours is a daily span of local time in which nothing is delivered.
+// The zero value is no quiet hours.
+type QuietHours struct {
+	// Start and End are minutes after midnight; End before Start wraps
+	// past midnight.
+	Start, End int
+}
+
+// ParseQuietHours reads "HH:MM-HH:MM", e.g. "22:00-07:30".
+func ParseQuietHours(value string) (QuietHours, error) {
+	start, end, ok := strings.Cut(value, "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("quiet hours must look like 22:00-07:00")
+	}
+	var quiet QuietHours
+	var err error
+	if quiet.Start, err = parseClock(start); err != nil {
+		return QuietHours{}, err
+	}
+	if quiet.End, err = parseClock(end); err != nil {
+		return QuietHours{}, err
+	}
+	return quiet, nil
+}
+
+func parseClock(value string) (int, error) {
+	clock, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q; use HH:MM", value)
+	}
+	return clock.Hour()*60 + clock.Minute(), nil
+}
+
+// Contains reports whether t falls in the quiet hours.
+func (q QuietHours) Contains(t time.Time) bool {
+	if q.Start == q.End {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if q.Start < q.End {
+		return minute >= q.Start && minute < q.End
+	}
+	return minute >= q.Start || minute < q.End
+}
+
+// before returns a context that ends when the quiet hours next begin after
+// now, or only with ctx when there are none.
+func (q QuietHours) before(ctx context.Context, now time.Time) (context.Context, context.CancelFunc) {
+	if q.Start == q.End {
+		return context.WithCancel(ctx)
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, q.Start, 0, 0, now.Location())
+	if !start.After(now) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return context.WithDeadline(ctx, start)
+}
@@ -0,0 +1,136 @@
+package watch
+
+import (
+	"path/filepath"
+	"slices"
+
+	"mastodoncli/internal/config"
+)
+
+// maxDelivered bounds the IDs remembered per source; overlaps between a
+// stream and a catch-up poll are much shorter than that.
+const maxDelivered = 500
+
+// State remembers, per watched source, what was already delivered, so a
+// restart neither repeats alerts nor replays old ones.
+type State struct {
+	Sources map[string]*Cursor `json:"sources"`
+}
+
+// Cursor tracks one source, such as "mastodon.social notifications".
+type Cursor struct {
+	// LastID is the newest ID seen; polling resumes after it.
+	LastID string `json:"last_id"`
+	// Delivered holds recently delivered IDs, oldest first.
+	Delivered []string `json:"delivered,omitempty"`
+	// Sent holds, for items some target failed to get, the targets that
+	// did get them.
+	Sent map[string][]string `json:"sent,omitempty"`
+}
+
+func StatePath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "watch.json"), nil
+}
+
+func LoadState() (*State, error) {
+	path, err := StatePath()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{Sources: map[string]*Cursor{}}
+	if err := config.ReadJSON(path, "watch state", state); err != nil {
+		return nil, err
+	}
+	if state.Sources == nil {
+		state.Sources = map[string]*Cursor{}
+	}
+	return state, nil
+}
+
+func (s *State) Save() error {
+	path, err := StatePath()
+	if err != nil {
+		return err
+	}
+	return config.WriteJSON(path, "watch state", s)
+}
+
+// LoadCursor returns the cursor of source, empty when it was never
+// watched.
+func LoadCursor(source string) (Cursor, error) {
+	state, err := LoadState()
+	if err != nil {
+		return Cursor{}, err
+	}
+	if cursor, ok := state.Sources[source]; ok {
+		return *cursor, nil
+	}
+	return Cursor{}, nil
+}
+
+// SaveCursor stores the cursor of source, leaving the other sources as
+// they are on disk so several watchers can share the file.
+func SaveCursor(source string, cursor Cursor) error {
+	path, err := StatePath()
+	if err != nil {
+		return err
+	}
+	unlock, err := config.Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := LoadState()
+	if err != nil {
+		return err
+	}
+	state.Sources[source] = &cursor
+	return state.Save()
+}
+
+// Seen reports whether id was delivered or is not newer than LastID.
+func (c *Cursor) Seen(id string) bool {
+	return !NewerID(id, c.LastID) || slices.Contains(c.Delivered, id)
+}
+
+// Repeated reports whether id was delivered. Streams use it instead of
+// Seen: remote statuses get IDs from their own timestamps, so a late one
+// can be older than LastID and still new.
+func (c *Cursor) Repeated(id string) bool {
+	return slices.Contains(c.Delivered, id)
+}
+
+// Mark records id as delivered and advances LastID.
+func (c *Cursor) Mark(id string) {
+	if NewerID(id, c.LastID) {
+		c.LastID = id
+	}
+	c.remember(id)
+}
+
+// remember records id as delivered without moving LastID.
+func (c *Cursor) remember(id string) {
+	if slices.Contains(c.Delivered, id) {
+		return
+	}
+	c.Delivered = append(c.Delivered, id)
+	if len(c.Delivered) > maxDelivered {
+		c.Delivered = c.Delivered[len(c.Delivered)-maxDelivered:]
+	}
+}
+
+// NewerID compares IDs as Mastodon sorts them: numeric IDs (Mastodon) and
+// ULIDs (GoToSocial) both grow in length and then in lexical order. Every
+// ID is newer than "".
+func NewerID(id, than string) bool {
+	if len(id) != len(than) {
+		return len(id) > len(than)
+	}
+	return id > than
+}
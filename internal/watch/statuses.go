@@ -0,0 +1,107 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/rules"
+)
+
+// StatusOptions configures Statuses.
+type StatusOptions struct {
+	Options
+	Timeline mastodon.Timeline
+}
+
+type statusFollower struct {
+	client *mastodon.Client
+	opts   StatusOptions
+	cursor Cursor
+}
+
+// Statuses delivers new statuses of a timeline until ctx is done.
+func Statuses(ctx context.Context, client *mastodon.Client, opts StatusOptions) error {
+	f := &statusFollower{client: client, opts: opts}
+	return run(ctx, f, &f.cursor, opts.Options)
+}
+
+func (f *statusFollower) start() error {
+	latest, err := f.client.TimelinePage(f.opts.Timeline, 1, "", "")
+	if err != nil {
+		return err
+	}
+	if len(latest) > 0 {
+		f.cursor.Mark(latest[0].ID)
+	}
+	return nil
+}
+
+func (f *statusFollower) poll(ctx context.Context) error {
+	var statuses []mastodon.Status
+	maxID := ""
+	for {
+		page, err := f.client.TimelinePage(f.opts.Timeline, pageSize, f.cursor.LastID, maxID)
+		if err != nil {
+			return err
+		}
+		statuses = append(statuses, page...)
+		if len(page) < pageSize {
+			break
+		}
+		maxID = page[len(page)-1].ID
+	}
+	return f.deliver(ctx, statuses, f.cursor.Seen)
+}
+
+func (f *statusFollower) stream(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var failed error
+	err := f.client.StreamStatuses(ctx, f.opts.Timeline, func(status mastodon.Status) {
+		if failed == nil {
+			if failed = f.deliver(ctx, []mastodon.Status{status}, f.cursor.Repeated); failed != nil {
+				cancel()
+			}
+		}
+	})
+	if failed != nil {
+		return failed
+	}
+	return err
+}
+
+// deliver hands out the statuses of a newest-first batch that are not seen
+// yet, oldest first, and records them in the state. It stops at the first
+// failed delivery, which the next poll hands out again.
+func (f *statusFollower) deliver(ctx context.Context, statuses []mastodon.Status, seen func(id string) bool) error {
+	var ids []string
+	held := ""
+	var err error
+	for i := len(statuses) - 1; i >= 0; i-- {
+		status := statuses[i]
+		if seen(status.ID) {
+			continue
+		}
+		if f.opts.Rules.Status(status, f.context()).Action != rules.ActionHide && f.opts.Match.Status(status) {
+			if err = send(ctx, &f.cursor, f.opts.Targets, status.ID, "status", status); err != nil {
+				held = status.ID
+				err = fmt.Errorf("delivering status %s: %w", status.ID, err)
+				break
+			}
+		}
+		ids = append(ids, status.ID)
+	}
+	if len(ids) > 0 || err != nil {
+		commit(&f.cursor, ids, held, f.opts.Options)
+	}
+	return err
+}
+
+// context is the rules context the timeline is shown in.
+func (f *statusFollower) context() rules.Context {
+	if f.opts.Timeline.Kind == "home" || f.opts.Timeline.Kind == "list" {
+		return rules.ContextHome
+	}
+	return rules.ContextPublic
+}
@@ -0,0 +1,247 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"mastodoncli/internal/mastodon"
+	"mastodoncli/internal/output"
+	"mastodoncli/internal/rules"
+)
+
+// Options are shared by every source.
+type Options struct {
+	// Source names the cursor in the watch state.
+	Source string
+	// Poll skips the streaming API. Interval is the time between polls,
+	// and between attempts to reconnect a dropped stream.
+	Poll     bool
+	Interval time.Duration
+	Quiet    QuietHours
+	// Rules drops items matched by local hide rules; Match keeps only the
+	// items it matches.
+	Rules *rules.Set
+	Match Match
+	// Targets receive each item, oldest first.
+	Targets []Target
+	// Logf reports progress and the errors that do not stop the watch.
+	Logf func(format string, args ...any)
+}
+
+// pageSize is the page size of catch-up polls, which page back to the
+// cursor so nothing newer than it is skipped.
+const pageSize = 40
+
+// follower is one kind of source: start sets the cursor to the newest
+// item, poll delivers what is newer than the cursor and stream delivers
+// items as they arrive. poll and stream stop at the first failed delivery
+// and leave it for the next poll.
+type follower interface {
+	start() error
+	poll(ctx context.Context) error
+	stream(ctx context.Context) error
+}
+
+// run follows the streaming API and polls with since_id to catch up after
+// restarts, dropped streams, failed deliveries and quiet hours; servers
+// without streaming are only polled. The first run starts from the newest
+// item instead of replaying the backlog.
+func run(ctx context.Context, f follower, cursor *Cursor, opts Options) error {
+	var err error
+	if *cursor, err = LoadCursor(opts.Source); err != nil {
+		return err
+	}
+	if cursor.LastID == "" {
+		for {
+			err := f.start()
+			if err == nil {
+				break
+			}
+			opts.Logf("Finding the newest item failed (%v); retrying in %s.", err, opts.Interval)
+			if !wait(ctx, opts.Interval) {
+				return nil
+			}
+		}
+		if cursor.LastID != "" {
+			opts.Logf("Starting after %s; older items are not delivered.", cursor.LastID)
+			if err := SaveCursor(opts.Source, *cursor); err != nil {
+				return err
+			}
+		}
+	}
+
+	stream := !opts.Poll
+	quiet := false
+	for {
+		// Nothing is fetched in quiet hours, so new items wait on the
+		// server and the first poll after them catches up.
+		if opts.Quiet.Contains(time.Now()) {
+			if !quiet {
+				opts.Logf("Quiet hours: holding new items until they end.")
+				quiet = true
+			}
+			if !wait(ctx, opts.Interval) {
+				return nil
+			}
+			continue
+		}
+		quiet = false
+
+		if err := f.poll(ctx); err != nil {
+			opts.Logf("Polling failed (%v); retrying in %s.", err, opts.Interval)
+		} else if stream {
+			opts.Logf("Streaming %s.", opts.Source)
+			streamCtx, cancel := opts.Quiet.before(ctx, time.Now())
+			err := f.stream(streamCtx)
+			quietStarted := streamCtx.Err() != nil
+			cancel()
+			if ctx.Err() != nil {
+				return nil
+			}
+			if quietStarted {
+				continue
+			}
+			var apiErr *mastodon.APIError
+			if errors.As(err, &apiErr) && apiErr.StatusCode < 500 {
+				stream = false
+				opts.Logf("Streaming is not available (%v); polling every %s.", err, opts.Interval)
+			} else {
+				opts.Logf("Stream interrupted (%v); reconnecting in %s.", err, opts.Interval)
+			}
+		}
+		if !wait(ctx, opts.Interval) {
+			return nil
+		}
+	}
+}
+
+// wait sleeps for d and reports false when ctx is done first.
+func wait(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// send hands payload, the item id, to the targets that do not have it yet.
+// The targets that got it are kept in the cursor until every target has
+// it, so a retry only goes to the ones that failed.
+func send(ctx context.Context, cursor *Cursor, targets []Target, id, event string, payload any) error {
+	var errs []error
+	for _, target := range targets {
+		if slices.Contains(cursor.Sent[id], target.Name) {
+			continue
+		}
+		if err := target.Send(ctx, event, payload); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target.Name, err))
+			continue
+		}
+		if cursor.Sent == nil {
+			cursor.Sent = make(map[string][]string)
+		}
+		cursor.Sent[id] = append(cursor.Sent[id], target.Name)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	delete(cursor.Sent, id)
+	return nil
+}
+
+// commit marks ids as delivered and saves the cursor. LastID stays below
+// held, the oldest ID not delivered yet, so the next poll fetches it again.
+func commit(cursor *Cursor, ids []string, held string, opts Options) {
+	for _, id := range ids {
+		if held == "" || NewerID(held, id) {
+			cursor.Mark(id)
+		} else {
+			cursor.remember(id)
+		}
+	}
+	if err := SaveCursor(opts.Source, *cursor); err != nil {
+		opts.Logf("Saving the watch state failed: %v", err)
+	}
+}
+
+// Match narrows what is delivered. Each field that is set must match.
+type Match struct {
+	// Keywords match, case-insensitively, anywhere in the text or content
+	// warning; any one is enough.
+	Keywords []string
+	// Accounts are accts ("user" for local accounts, "user@domain"
+	// otherwise); any one is enough.
+	Accounts []string
+	// Kinds are "post", "reply" or "boost", for statuses only.
+	Kinds []string
+}
+
+// StatusKinds are the values of Match.Kinds.
+var StatusKinds = []string{"post", "reply", "boost"}
+
+// Status reports whether status, or the status it boosts, matches.
+func (m Match) Status(status mastodon.Status) bool {
+	if len(m.Kinds) > 0 && !slices.Contains(m.Kinds, statusKind(status)) {
+		return false
+	}
+	accounts := []mastodon.Account{status.Account}
+	if status.Reblog != nil {
+		accounts = append(accounts, status.Reblog.Account)
+	}
+	return m.accounts(accounts) && m.keywords(&status)
+}
+
+// Notification reports whether a notification group matches; its status
+// is searched for the keywords.
+func (m Match) Notification(group mastodon.GroupedNotification) bool {
+	return m.accounts(group.Accounts) && m.keywords(group.Status)
+}
+
+func (m Match) accounts(accounts []mastodon.Account) bool {
+	if len(m.Accounts) == 0 {
+		return true
+	}
+	for _, account := range accounts {
+		for _, want := range m.Accounts {
+			if strings.EqualFold(strings.TrimPrefix(want, "@"), account.Acct) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m Match) keywords(status *mastodon.Status) bool {
+	if len(m.Keywords) == 0 {
+		return true
+	}
+	if status == nil {
+		return false
+	}
+	if status.Reblog != nil {
+		status = status.Reblog
+	}
+	text := strings.ToLower(output.StripHTML(status.SpoilerText) + "\n" + output.StripHTML(status.Content))
+	for _, keyword := range m.Keywords {
+		if strings.Contains(text, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+func statusKind(status mastodon.Status) string {
+	switch {
+	case status.Reblog != nil:
+		return "boost"
+	case status.InReplyToID != "":
+		return "reply"
+	default:
+		return "post"
+	}
+}
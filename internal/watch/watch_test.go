@@ -0,0 +1,283 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mastodoncli/internal/mastodon"
+)
+
+func TestQuietHoursWrapMidnight(t *testing.T) {
+	quiet, err := ParseQuietHours("22:00-07:30")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	cases := map[time.Duration]bool{
+		21*time.Hour + 59*time.Minute: false,
+		22 * time.Hour:                true,
+		3 * time.Hour:                 true,
+		7*time.Hour + 30*time.Minute:  false,
+	}
+	for offset, want := range cases {
+		if got := quiet.Contains(day.Add(offset)); got != want {
+			t.Fatalf("Contains(%s) = %v, want %v", day.Add(offset).Format("15:04"), got, want)
+		}
+	}
+	if _, err := ParseQuietHours("22-7"); err == nil {
+		t.Fatal("expected an error for times without minutes")
+	}
+}
+
+func TestQuietHoursEndTheStream(t *testing.T) {
+	quiet, err := ParseQuietHours("22:00-07:00")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	now := time.Date(2024, 5, 1, 23, 0, 0, 0, time.Local)
+	ctx, cancel := quiet.before(context.Background(), now)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if want := time.Date(2024, 5, 2, 22, 0, 0, 0, time.Local); !ok || !deadline.Equal(want) {
+		t.Fatalf("stream ends at %v, want %v", deadline, want)
+	}
+
+	ctx, cancel = QuietHours{}.before(context.Background(), now)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("expected no deadline without quiet hours")
+	}
+}
+
+func TestNewerID(t *testing.T) {
+	if !NewerID("110", "99") || NewerID("99", "110") || NewerID("5", "5") || !NewerID("1", "") {
+		t.Fatal("numeric IDs compared wrongly")
+	}
+	if !NewerID("01HZY0000000000000000000B", "01HZY0000000000000000000A") {
+		t.Fatal("ULIDs compared wrongly")
+	}
+}
+
+func TestNotificationsDeliversNewGroupsOnce(t *testing.T) {
+	var sinceIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/notifications" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		sinceIDs = append(sinceIDs, r.URL.Query().Get("since_id"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"id": "12", "type": "favourite", "account": {"id": "2", "acct": "bob"}, "status": {"id": "5"}},
+			{"id": "11", "type": "favourite", "account": {"id": "1", "acct": "alice"}, "status": {"id": "5"}},
+			{"id": "10", "type": "mention", "account": {"id": "1", "acct": "alice"}, "status": {"id": "4"}},
+			{"id": "9", "type": "follow", "account": {"id": "3", "acct": "carol"}}
+		]`))
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := SaveCursor("test", Cursor{LastID: "9", Delivered: []string{"10"}}); err != nil {
+		t.Fatalf("save cursor: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var delivered []mastodon.GroupedNotification
+	err := Notifications(ctx, mastodon.NewClient(server.URL, "token"), NotificationOptions{
+		Options: Options{Source: "test", Poll: true, Interval: time.Hour, Logf: t.Logf, Targets: []Target{
+			target("test", func(payload any) error {
+				delivered = append(delivered, payload.(mastodon.GroupedNotification))
+				cancel()
+				return nil
+			}),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Notifications error: %v", err)
+	}
+	if len(sinceIDs) != 1 || sinceIDs[0] != "9" {
+		t.Fatalf("unexpected since_id: %v", sinceIDs)
+	}
+	if len(delivered) != 1 || delivered[0].Type != "favourite" || delivered[0].Count != 2 {
+		t.Fatalf("expected one favourite group, got %+v", delivered)
+	}
+	cursor, err := LoadCursor("test")
+	if err != nil {
+		t.Fatalf("load cursor: %v", err)
+	}
+	if cursor.LastID != "12" || !cursor.Seen("11") {
+		t.Fatalf("unexpected cursor: %+v", cursor)
+	}
+}
+
+func TestNotificationsRetriesFailedDeliveries(t *testing.T) {
+	var sinceIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sinceIDs = append(sinceIDs, r.URL.Query().Get("since_id"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"id": "11", "type": "mention", "account": {"id": "2", "acct": "bob"}, "status": {"id": "6"}},
+			{"id": "10", "type": "mention", "account": {"id": "1", "acct": "alice"}, "status": {"id": "5"}}
+		]`))
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := SaveCursor("test", Cursor{LastID: "9"}); err != nil {
+		t.Fatalf("save cursor: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var attempts, healthy []string
+	err := Notifications(ctx, mastodon.NewClient(server.URL, "token"), NotificationOptions{
+		Options: Options{Source: "test", Poll: true, Interval: time.Millisecond, Logf: t.Logf, Targets: []Target{
+			target("healthy", func(payload any) error {
+				healthy = append(healthy, payload.(mastodon.GroupedNotification).MostRecent)
+				return nil
+			}),
+			target("flaky", func(payload any) error {
+				group := payload.(mastodon.GroupedNotification)
+				attempts = append(attempts, group.MostRecent)
+				if len(attempts) == 1 {
+					return errors.New("webhook down")
+				}
+				if group.MostRecent == "11" {
+					cancel()
+				}
+				return nil
+			}),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Notifications error: %v", err)
+	}
+	if got := strings.Join(attempts, ","); got != "10,10,11" {
+		t.Fatalf("unexpected deliveries: %s", got)
+	}
+	if got := strings.Join(healthy, ","); got != "10,11" {
+		t.Fatalf("expected the healthy target to get each item once, got %s", got)
+	}
+	if got := strings.Join(sinceIDs, ","); got != "9,9" {
+		t.Fatalf("unexpected since_id: %s", got)
+	}
+	cursor, err := LoadCursor("test")
+	if err != nil {
+		t.Fatalf("load cursor: %v", err)
+	}
+	if cursor.LastID != "11" {
+		t.Fatalf("unexpected cursor: %+v", cursor)
+	}
+}
+
+func TestStatusesCatchUpPagesBackToTheCursor(t *testing.T) {
+	var maxIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/timelines/tag/go" || r.URL.Query().Get("since_id") != "100" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL)
+		}
+		maxID := r.URL.Query().Get("max_id")
+		maxIDs = append(maxIDs, maxID)
+		newest := 100 + pageSize + 1
+		if maxID != "" {
+			newest, _ = strconv.Atoi(maxID)
+			newest--
+		}
+		var page []string
+		for id := newest; id > 100 && len(page) < pageSize; id-- {
+			page = append(page, fmt.Sprintf(`{"id": "%d", "account": {"acct": "alice"}}`, id))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("[" + strings.Join(page, ",") + "]"))
+	}))
+	defer server.Close()
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := SaveCursor("test", Cursor{LastID: "100"}); err != nil {
+		t.Fatalf("save cursor: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var delivered []string
+	err := Statuses(ctx, mastodon.NewClient(server.URL, "token"), StatusOptions{
+		Options: Options{Source: "test", Poll: true, Interval: time.Hour, Logf: t.Logf, Targets: []Target{
+			target("test", func(payload any) error {
+				delivered = append(delivered, payload.(mastodon.Status).ID)
+				cancel()
+				return nil
+			}),
+		}},
+		Timeline: mastodon.Timeline{Kind: "hashtag", Name: "go"},
+	})
+	if err != nil {
+		t.Fatalf("Statuses error: %v", err)
+	}
+	if len(maxIDs) != 2 || len(delivered) != pageSize+1 || delivered[0] != "101" {
+		t.Fatalf("expected %d statuses over 2 pages, got %d over %v", pageSize+1, len(delivered), maxIDs)
+	}
+}
+
+func TestMatchStatus(t *testing.T) {
+	boost := mastodon.Status{
+		Account: mastodon.Account{Acct: "bob"},
+		Reblog:  &mastodon.Status{Account: mastodon.Account{Acct: "alice@example.org"}, Content: "<p>Release of MastoCLI 2.0</p>"},
+	}
+	if !(Match{Keywords: []string{"mastocli"}, Accounts: []string{"@Alice@example.org"}}).Status(boost) {
+		t.Fatal("expected the boosted status to match its author and text")
+	}
+	if (Match{Kinds: []string{"post", "reply"}}).Status(boost) {
+		t.Fatal("expected a boost not to match posts and replies")
+	}
+	if (Match{Keywords: []string{"linux"}}).Status(boost) {
+		t.Fatal("expected no match for a missing keyword")
+	}
+}
+
+func TestWebhookRetriesServerErrors(t *testing.T) {
+	attempts := 0
+	var event string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		event = r.Header.Get("X-Mastodon-Event")
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	hook := Webhook{URL: server.URL, Retries: 2, backoff: time.Millisecond}
+	if err := hook.Post(context.Background(), "status", mastodon.Status{ID: "1"}); err != nil {
+		t.Fatalf("Post error: %v", err)
+	}
+	if attempts != 3 || event != "status" {
+		t.Fatalf("expected 3 attempts of a status event, got %d of %q", attempts, event)
+	}
+
+	attempts = 0
+	hook.Retries = 1
+	if err := hook.Post(context.Background(), "status", mastodon.Status{ID: "1"}); err == nil || attempts != 2 {
+		t.Fatalf("expected failure after 2 attempts, got %v after %d", err, attempts)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	hook.backoff = time.Hour
+	start := time.Now()
+	if err := hook.Post(ctx, "status", mastodon.Status{ID: "1"}); err == nil || time.Since(start) > time.Minute {
+		t.Fatalf("expected a cancelled context to end the retries, got %v", err)
+	}
+}
+
+// target is a Target that ignores the context and the event.
+func target(name string, send func(payload any) error) Target {
+	return Target{Name: name, Send: func(_ context.Context, _ string, payload any) error {
+		return send(payload)
+	}}
+}